@@ -1,152 +1,58 @@
 package beaconchain
 
 import (
-	"fmt"
-
 	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethpandaops/go-eth2-client/http"
 	"github.com/ethpandaops/go-eth2-client/spec"
 	"github.com/ethpandaops/go-eth2-client/spec/altair"
 	"github.com/ethpandaops/go-eth2-client/spec/phase0"
-	dynssz "github.com/pk910/dynamic-ssz"
-	"github.com/sirupsen/logrus"
 
 	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
-	"github.com/ethpandaops/eth-beacon-genesis/beaconutils"
-	"github.com/ethpandaops/eth-beacon-genesis/validators"
 )
 
-type altairBuilder struct {
-	elGenesis       *core.Genesis
-	clConfig        *beaconconfig.Config
-	dynSsz          *dynssz.DynSsz
-	shadowForkBlock *types.Block
-	validators      []*validators.Validator
+var altairGenesisFork = &genesisFork{
+	version:    spec.DataVersionAltair,
+	blockBody:  altairBlockBody,
+	buildState: buildAltairState,
 }
 
 func NewAltairBuilder(elGenesis *core.Genesis, clConfig *beaconconfig.Config) BeaconGenesisBuilder {
-	return &altairBuilder{
-		elGenesis: elGenesis,
-		clConfig:  clConfig,
-		dynSsz:    beaconutils.GetDynSSZ(clConfig),
-	}
+	return newGenesisBuilder(elGenesis, clConfig, altairGenesisFork)
 }
 
-func (b *altairBuilder) SetShadowForkBlock(block *types.Block) {
-	b.shadowForkBlock = block
+func altairBlockBody(g *genesisData) (any, error) {
+	return &altair.BeaconBlockBody{
+		ETH1Data:      g.emptyETH1Data(),
+		SyncAggregate: g.emptySyncAggregate(),
+	}, nil
 }
 
-func (b *altairBuilder) AddValidators(val []*validators.Validator) {
-	b.validators = append(b.validators, val...)
-}
-
-func (b *altairBuilder) BuildState() (*spec.VersionedBeaconState, error) {
-	genesisBlock := b.shadowForkBlock
-	if genesisBlock == nil {
-		genesisBlock = b.elGenesis.ToBlock()
-	}
-
-	genesisBlockHash := genesisBlock.Hash()
-
-	extra := genesisBlock.Extra()
-	if len(extra) > 32 {
-		return nil, fmt.Errorf("extra data is %d bytes, max is %d", len(extra), 32)
-	}
-
-	depositRoot, err := beaconutils.ComputeDepositRoot(b.clConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute deposit root: %w", err)
-	}
-
-	syncCommitteeSize := b.clConfig.GetUintDefault("SYNC_COMMITTEE_SIZE", 512)
-	syncCommitteeMaskBytes := syncCommitteeSize / 8
-
-	if syncCommitteeSize%8 != 0 {
-		syncCommitteeMaskBytes++
-	}
-
-	genesisBlockBody := &altair.BeaconBlockBody{
-		ETH1Data: &phase0.ETH1Data{
-			BlockHash: make([]byte, 32),
-		},
-		SyncAggregate: &altair.SyncAggregate{
-			SyncCommitteeBits: make([]byte, syncCommitteeMaskBytes),
-		},
-	}
-
-	genesisBlockBodyRoot, err := b.dynSsz.HashTreeRoot(genesisBlockBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute genesis block body root: %w", err)
-	}
-
-	clValidators, validatorsRoot := beaconutils.GetGenesisValidators(b.clConfig, b.validators)
-
-	syncCommittee, err := beaconutils.GetGenesisSyncCommittee(b.clConfig, clValidators, phase0.Hash32(genesisBlockHash))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get genesis sync committee: %w", err)
-	}
-
-	genesisDelay := b.clConfig.GetUintDefault("GENESIS_DELAY", 604800)
-	blocksPerHistoricalRoot := b.clConfig.GetUintDefault("SLOTS_PER_HISTORICAL_ROOT", 8192)
-	epochsPerSlashingVector := b.clConfig.GetUintDefault("EPOCHS_PER_SLASHINGS_VECTOR", 8192)
-
-	minGenesisTime := b.clConfig.GetUintDefault("MIN_GENESIS_TIME", 0)
-	if minGenesisTime == 0 {
-		minGenesisTime = genesisBlock.Time()
-	}
-
+func buildAltairState(g *genesisData, base *phase0.BeaconState) (*spec.VersionedBeaconState, error) {
 	genesisState := &altair.BeaconState{
-		GenesisTime:           minGenesisTime + genesisDelay,
-		GenesisValidatorsRoot: validatorsRoot,
-		Fork:                  GetStateForkConfig(spec.DataVersionAltair, b.clConfig),
-		LatestBlockHeader: &phase0.BeaconBlockHeader{
-			BodyRoot: genesisBlockBodyRoot,
-		},
-		BlockRoots: make([]phase0.Root, blocksPerHistoricalRoot),
-		StateRoots: make([]phase0.Root, blocksPerHistoricalRoot),
-		ETH1Data: &phase0.ETH1Data{
-			DepositRoot: depositRoot,
-			BlockHash:   genesisBlockHash[:],
-		},
-		JustificationBits:           make([]byte, 1),
-		PreviousJustifiedCheckpoint: &phase0.Checkpoint{},
-		CurrentJustifiedCheckpoint:  &phase0.Checkpoint{},
-		FinalizedCheckpoint:         &phase0.Checkpoint{},
-		RANDAOMixes:                 beaconutils.SeedRandomMixes(phase0.Hash32(genesisBlockHash), b.clConfig),
-		Validators:                  clValidators,
-		Balances:                    beaconutils.GetGenesisBalances(b.clConfig, b.validators),
-		Slashings:                   make([]phase0.Gwei, epochsPerSlashingVector),
-		PreviousEpochParticipation:  make([]altair.ParticipationFlags, len(clValidators)),
-		CurrentEpochParticipation:   make([]altair.ParticipationFlags, len(clValidators)),
-		InactivityScores:            make([]uint64, len(clValidators)),
-		CurrentSyncCommittee:        syncCommittee,
-		NextSyncCommittee:           syncCommittee,
-	}
-
-	versionedState := &spec.VersionedBeaconState{
+		GenesisTime:                 base.GenesisTime,
+		GenesisValidatorsRoot:       base.GenesisValidatorsRoot,
+		Fork:                        base.Fork,
+		LatestBlockHeader:           base.LatestBlockHeader,
+		BlockRoots:                  base.BlockRoots,
+		StateRoots:                  base.StateRoots,
+		ETH1Data:                    base.ETH1Data,
+		ETH1DepositIndex:            base.ETH1DepositIndex,
+		JustificationBits:           base.JustificationBits,
+		PreviousJustifiedCheckpoint: base.PreviousJustifiedCheckpoint,
+		CurrentJustifiedCheckpoint:  base.CurrentJustifiedCheckpoint,
+		FinalizedCheckpoint:         base.FinalizedCheckpoint,
+		RANDAOMixes:                 base.RANDAOMixes,
+		Validators:                  base.Validators,
+		Balances:                    base.Balances,
+		Slashings:                   base.Slashings,
+		PreviousEpochParticipation:  g.participationFlags(),
+		CurrentEpochParticipation:   g.participationFlags(),
+		InactivityScores:            g.inactivityScores(),
+		CurrentSyncCommittee:        g.syncCommittee,
+		NextSyncCommittee:           g.syncCommittee,
+	}
+
+	return &spec.VersionedBeaconState{
 		Version: spec.DataVersionAltair,
 		Altair:  genesisState,
-	}
-
-	logrus.Infof("genesis version: altair")
-	logrus.Infof("genesis time: %v", genesisState.GenesisTime)
-	logrus.Infof("genesis validators root: 0x%x", genesisState.GenesisValidatorsRoot)
-
-	return versionedState, nil
-}
-
-func (b *altairBuilder) Serialize(state *spec.VersionedBeaconState, contentType http.ContentType) ([]byte, error) {
-	if state.Version != spec.DataVersionAltair {
-		return nil, fmt.Errorf("unsupported version: %s", state.Version)
-	}
-
-	switch contentType {
-	case http.ContentTypeSSZ:
-		return b.dynSsz.MarshalSSZ(state.Altair)
-	case http.ContentTypeJSON:
-		return state.Altair.MarshalJSON()
-	default:
-		return nil, fmt.Errorf("unsupported content type: %s", contentType)
-	}
+	}, nil
 }
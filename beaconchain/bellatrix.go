@@ -1,64 +1,49 @@
 package beaconchain
 
 import (
+	"bytes"
 	"fmt"
+	"math"
 
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethpandaops/go-eth2-client/http"
 	"github.com/ethpandaops/go-eth2-client/spec"
-	"github.com/ethpandaops/go-eth2-client/spec/altair"
 	"github.com/ethpandaops/go-eth2-client/spec/bellatrix"
 	"github.com/ethpandaops/go-eth2-client/spec/phase0"
 	"github.com/holiman/uint256"
-	"github.com/sirupsen/logrus"
 
 	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
 	"github.com/ethpandaops/eth-beacon-genesis/beaconutils"
-	"github.com/ethpandaops/eth-beacon-genesis/validators"
-	dynssz "github.com/pk910/dynamic-ssz"
 )
 
-type bellatrixBuilder struct {
-	elGenesis       *core.Genesis
-	clConfig        *beaconconfig.Config
-	dynSsz          *dynssz.DynSsz
-	shadowForkBlock *types.Block
-	validators      []*validators.Validator
+var bellatrixGenesisFork = &genesisFork{
+	version:    spec.DataVersionBellatrix,
+	blockBody:  bellatrixBlockBody,
+	buildState: buildBellatrixState,
 }
 
 func NewBellatrixBuilder(elGenesis *core.Genesis, clConfig *beaconconfig.Config) BeaconGenesisBuilder {
-	return &bellatrixBuilder{
-		elGenesis: elGenesis,
-		clConfig:  clConfig,
-		dynSsz:    beaconutils.GetDynSSZ(clConfig),
-	}
-}
-
-func (b *bellatrixBuilder) SetShadowForkBlock(block *types.Block) {
-	b.shadowForkBlock = block
+	return newGenesisBuilder(elGenesis, clConfig, bellatrixGenesisFork)
 }
 
-func (b *bellatrixBuilder) AddValidators(val []*validators.Validator) {
-	b.validators = append(b.validators, val...)
+func bellatrixBlockBody(g *genesisData) (any, error) {
+	return &bellatrix.BeaconBlockBody{
+		ETH1Data:         g.emptyETH1Data(),
+		SyncAggregate:    g.emptySyncAggregate(),
+		ExecutionPayload: &bellatrix.ExecutionPayload{},
+	}, nil
 }
 
-func (b *bellatrixBuilder) BuildState() (*spec.VersionedBeaconState, error) {
-	genesisBlock := b.shadowForkBlock
-	if genesisBlock == nil {
-		genesisBlock = b.elGenesis.ToBlock()
-	}
-
-	genesisBlockHash := genesisBlock.Hash()
-
-	extra := genesisBlock.Extra()
-	if len(extra) > 32 {
-		return nil, fmt.Errorf("extra data is %d bytes, max is %d", len(extra), 32)
+// bellatrixExecutionHeader returns the execution payload header of the
+// genesis block, or the empty header of a pre-merge genesis.
+func bellatrixExecutionHeader(g *genesisData) (*bellatrix.ExecutionPayloadHeader, error) {
+	if g.preMerge {
+		return &bellatrix.ExecutionPayloadHeader{}, nil
 	}
 
-	baseFee, _ := uint256.FromBig(genesisBlock.BaseFee())
+	baseFee, _ := uint256.FromBig(g.block.BaseFee())
 
-	transactionsRoot, err := beaconutils.ComputeTransactionsRoot(genesisBlock.Transactions(), b.clConfig)
+	transactionsRoot, err := beaconutils.ComputeTransactionsRoot(g.block.Transactions(), g.clConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to compute transactions root: %w", err)
 	}
@@ -68,117 +53,99 @@ func (b *bellatrixBuilder) BuildState() (*spec.VersionedBeaconState, error) {
 		baseFeeBytes[i], baseFeeBytes[j] = baseFeeBytes[j], baseFeeBytes[i]
 	}
 
-	execHeader := &bellatrix.ExecutionPayloadHeader{
-		ParentHash:       phase0.Hash32(genesisBlock.ParentHash()),
-		FeeRecipient:     bellatrix.ExecutionAddress(genesisBlock.Coinbase()),
-		StateRoot:        phase0.Root(genesisBlock.Root()),
-		ReceiptsRoot:     phase0.Root(genesisBlock.ReceiptHash()),
-		LogsBloom:        genesisBlock.Bloom(),
-		BlockNumber:      genesisBlock.NumberU64(),
-		GasLimit:         genesisBlock.GasLimit(),
-		GasUsed:          genesisBlock.GasUsed(),
-		Timestamp:        genesisBlock.Time(),
-		ExtraData:        extra,
+	return &bellatrix.ExecutionPayloadHeader{
+		ParentHash:       phase0.Hash32(g.block.ParentHash()),
+		FeeRecipient:     bellatrix.ExecutionAddress(g.block.Coinbase()),
+		StateRoot:        phase0.Root(g.block.Root()),
+		ReceiptsRoot:     phase0.Root(g.block.ReceiptHash()),
+		LogsBloom:        g.block.Bloom(),
+		BlockNumber:      g.block.NumberU64(),
+		GasLimit:         g.block.GasLimit(),
+		GasUsed:          g.block.GasUsed(),
+		Timestamp:        g.block.Time(),
+		ExtraData:        g.block.Extra(),
 		BaseFeePerGasLE:  baseFeeBytes,
-		BlockHash:        phase0.Hash32(genesisBlockHash),
+		BlockHash:        g.blockHash,
 		TransactionsRoot: transactionsRoot,
-	}
+	}, nil
+}
 
-	depositRoot, err := beaconutils.ComputeDepositRoot(b.clConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute deposit root: %w", err)
+// checkPreMergeConfig checks the terminal block settings of a pre-merge
+// genesis. TERMINAL_TOTAL_DIFFICULTY must match the execution chain config and
+// be above the difficulty of the execution genesis block, as the terminal
+// block needs a parent below it. A TERMINAL_BLOCK_HASH override replaces the
+// difficulty check and needs its activation epoch.
+func checkPreMergeConfig(elGenesis *core.Genesis, clConfig *beaconconfig.Config, block *types.Block) error {
+	if block.NumberU64() != 0 {
+		return fmt.Errorf("execution genesis block required, got block %d", block.NumberU64())
 	}
 
-	syncCommitteeSize := b.clConfig.GetUintDefault("SYNC_COMMITTEE_SIZE", 512)
-	syncCommitteeMaskBytes := syncCommitteeSize / 8
+	ttd, found := clConfig.GetBigInt("TERMINAL_TOTAL_DIFFICULTY")
+	if !found {
+		return fmt.Errorf("TERMINAL_TOTAL_DIFFICULTY is not set")
+	}
 
-	if syncCommitteeSize%8 != 0 {
-		syncCommitteeMaskBytes++
+	if elGenesis.Config == nil || elGenesis.Config.TerminalTotalDifficulty == nil {
+		return fmt.Errorf("terminalTotalDifficulty is not set in the execution chain config")
 	}
 
-	genesisBlockBody := &bellatrix.BeaconBlockBody{
-		ETH1Data: &phase0.ETH1Data{
-			BlockHash: make([]byte, 32),
-		},
-		SyncAggregate: &altair.SyncAggregate{
-			SyncCommitteeBits: make([]byte, syncCommitteeMaskBytes),
-		},
-		ExecutionPayload: &bellatrix.ExecutionPayload{},
+	if elGenesis.Config.TerminalTotalDifficulty.Cmp(ttd) != 0 {
+		return fmt.Errorf("terminalTotalDifficulty of the execution chain config (%s) does not match TERMINAL_TOTAL_DIFFICULTY (%s)", elGenesis.Config.TerminalTotalDifficulty.String(), ttd.String())
 	}
 
-	genesisBlockBodyRoot, err := b.dynSsz.HashTreeRoot(genesisBlockBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute genesis block body root: %w", err)
+	terminalBlockHash := clConfig.GetBytesDefault("TERMINAL_BLOCK_HASH", nil)
+	if len(terminalBlockHash) != 0 && len(terminalBlockHash) != 32 {
+		return fmt.Errorf("TERMINAL_BLOCK_HASH is %d bytes, expected 32", len(terminalBlockHash))
 	}
 
-	clValidators, validatorsRoot := beaconutils.GetGenesisValidators(b.clConfig, b.validators)
+	hashOverride := len(terminalBlockHash) > 0 && !bytes.Equal(terminalBlockHash, make([]byte, 32))
+	activationEpoch := clConfig.GetUintDefault("TERMINAL_BLOCK_HASH_ACTIVATION_EPOCH", math.MaxUint64)
 
-	syncCommittee, err := beaconutils.GetGenesisSyncCommittee(b.clConfig, clValidators, phase0.Hash32(genesisBlockHash))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get genesis sync committee: %w", err)
+	switch {
+	case hashOverride && activationEpoch == math.MaxUint64:
+		return fmt.Errorf("TERMINAL_BLOCK_HASH is set, but TERMINAL_BLOCK_HASH_ACTIVATION_EPOCH is not")
+	case !hashOverride && activationEpoch != math.MaxUint64:
+		return fmt.Errorf("TERMINAL_BLOCK_HASH_ACTIVATION_EPOCH is set, but TERMINAL_BLOCK_HASH is not")
+	case !hashOverride && block.Difficulty().Cmp(ttd) >= 0:
+		return fmt.Errorf("execution genesis difficulty %s reaches TERMINAL_TOTAL_DIFFICULTY %s, the chain can not merge", block.Difficulty().String(), ttd.String())
 	}
 
-	genesisDelay := b.clConfig.GetUintDefault("GENESIS_DELAY", 604800)
-	blocksPerHistoricalRoot := b.clConfig.GetUintDefault("SLOTS_PER_HISTORICAL_ROOT", 8192)
-	epochsPerSlashingVector := b.clConfig.GetUintDefault("EPOCHS_PER_SLASHINGS_VECTOR", 8192)
+	return nil
+}
 
-	minGenesisTime := b.clConfig.GetUintDefault("MIN_GENESIS_TIME", 0)
-	if minGenesisTime == 0 {
-		minGenesisTime = genesisBlock.Time()
+func buildBellatrixState(g *genesisData, base *phase0.BeaconState) (*spec.VersionedBeaconState, error) {
+	execHeader, err := bellatrixExecutionHeader(g)
+	if err != nil {
+		return nil, err
 	}
 
 	genesisState := &bellatrix.BeaconState{
-		GenesisTime:           minGenesisTime + genesisDelay,
-		GenesisValidatorsRoot: validatorsRoot,
-		Fork:                  GetStateForkConfig(spec.DataVersionBellatrix, b.clConfig),
-		LatestBlockHeader: &phase0.BeaconBlockHeader{
-			BodyRoot: genesisBlockBodyRoot,
-		},
-		BlockRoots: make([]phase0.Root, blocksPerHistoricalRoot),
-		StateRoots: make([]phase0.Root, blocksPerHistoricalRoot),
-		ETH1Data: &phase0.ETH1Data{
-			DepositRoot: depositRoot,
-			BlockHash:   genesisBlockHash[:],
-		},
-		JustificationBits:            make([]byte, 1),
-		PreviousJustifiedCheckpoint:  &phase0.Checkpoint{},
-		CurrentJustifiedCheckpoint:   &phase0.Checkpoint{},
-		FinalizedCheckpoint:          &phase0.Checkpoint{},
-		RANDAOMixes:                  beaconutils.SeedRandomMixes(phase0.Hash32(genesisBlockHash), b.clConfig),
-		Validators:                   clValidators,
-		Balances:                     beaconutils.GetGenesisBalances(b.clConfig, b.validators),
-		Slashings:                    make([]phase0.Gwei, epochsPerSlashingVector),
-		PreviousEpochParticipation:   make([]altair.ParticipationFlags, len(clValidators)),
-		CurrentEpochParticipation:    make([]altair.ParticipationFlags, len(clValidators)),
-		InactivityScores:             make([]uint64, len(clValidators)),
-		CurrentSyncCommittee:         syncCommittee,
-		NextSyncCommittee:            syncCommittee,
+		GenesisTime:                  base.GenesisTime,
+		GenesisValidatorsRoot:        base.GenesisValidatorsRoot,
+		Fork:                         base.Fork,
+		LatestBlockHeader:            base.LatestBlockHeader,
+		BlockRoots:                   base.BlockRoots,
+		StateRoots:                   base.StateRoots,
+		ETH1Data:                     base.ETH1Data,
+		ETH1DepositIndex:             base.ETH1DepositIndex,
+		JustificationBits:            base.JustificationBits,
+		PreviousJustifiedCheckpoint:  base.PreviousJustifiedCheckpoint,
+		CurrentJustifiedCheckpoint:   base.CurrentJustifiedCheckpoint,
+		FinalizedCheckpoint:          base.FinalizedCheckpoint,
+		RANDAOMixes:                  base.RANDAOMixes,
+		Validators:                   base.Validators,
+		Balances:                     base.Balances,
+		Slashings:                    base.Slashings,
+		PreviousEpochParticipation:   g.participationFlags(),
+		CurrentEpochParticipation:    g.participationFlags(),
+		InactivityScores:             g.inactivityScores(),
+		CurrentSyncCommittee:         g.syncCommittee,
+		NextSyncCommittee:            g.syncCommittee,
 		LatestExecutionPayloadHeader: execHeader,
 	}
 
-	versionedState := &spec.VersionedBeaconState{
+	return &spec.VersionedBeaconState{
 		Version:   spec.DataVersionBellatrix,
 		Bellatrix: genesisState,
-	}
-
-	logrus.Infof("genesis version: bellatrix")
-	logrus.Infof("genesis time: %v", genesisState.GenesisTime)
-	logrus.Infof("genesis validators root: 0x%x", genesisState.GenesisValidatorsRoot)
-
-	return versionedState, nil
-}
-
-func (b *bellatrixBuilder) Serialize(state *spec.VersionedBeaconState, contentType http.ContentType) ([]byte, error) {
-	if state.Version != spec.DataVersionBellatrix {
-		return nil, fmt.Errorf("unsupported version: %s", state.Version)
-	}
-
-	switch contentType {
-	case http.ContentTypeSSZ:
-		return b.dynSsz.MarshalSSZ(state.Bellatrix)
-	case http.ContentTypeJSON:
-		return state.Bellatrix.MarshalJSON()
-	default:
-		return nil, fmt.Errorf("unsupported content type: %s", contentType)
-	}
+	}, nil
 }
@@ -4,65 +4,44 @@ import (
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethpandaops/go-eth2-client/http"
 	"github.com/ethpandaops/go-eth2-client/spec"
-	"github.com/ethpandaops/go-eth2-client/spec/altair"
-	"github.com/ethpandaops/go-eth2-client/spec/bellatrix"
 	"github.com/ethpandaops/go-eth2-client/spec/capella"
 	"github.com/ethpandaops/go-eth2-client/spec/phase0"
-	"github.com/holiman/uint256"
-	"github.com/sirupsen/logrus"
 
 	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
 	"github.com/ethpandaops/eth-beacon-genesis/beaconutils"
-	"github.com/ethpandaops/eth-beacon-genesis/validators"
-	dynssz "github.com/pk910/dynamic-ssz"
 )
 
-type capellaBuilder struct {
-	elGenesis       *core.Genesis
-	clConfig        *beaconconfig.Config
-	dynSsz          *dynssz.DynSsz
-	shadowForkBlock *types.Block
-	validators      []*validators.Validator
+var capellaGenesisFork = &genesisFork{
+	version:    spec.DataVersionCapella,
+	blockBody:  capellaBlockBody,
+	buildState: buildCapellaState,
 }
 
 func NewCapellaBuilder(elGenesis *core.Genesis, clConfig *beaconconfig.Config) BeaconGenesisBuilder {
-	return &capellaBuilder{
-		elGenesis: elGenesis,
-		clConfig:  clConfig,
-		dynSsz:    beaconutils.GetDynSSZ(clConfig),
-	}
-}
-
-func (b *capellaBuilder) SetShadowForkBlock(block *types.Block) {
-	b.shadowForkBlock = block
+	return newGenesisBuilder(elGenesis, clConfig, capellaGenesisFork)
 }
 
-func (b *capellaBuilder) AddValidators(val []*validators.Validator) {
-	b.validators = append(b.validators, val...)
+func capellaBlockBody(g *genesisData) (any, error) {
+	return &capella.BeaconBlockBody{
+		ETH1Data:         g.emptyETH1Data(),
+		SyncAggregate:    g.emptySyncAggregate(),
+		ExecutionPayload: &capella.ExecutionPayload{},
+	}, nil
 }
 
-func (b *capellaBuilder) BuildState() (*spec.VersionedBeaconState, error) {
-	genesisBlock := b.shadowForkBlock
-	if genesisBlock == nil {
-		genesisBlock = b.elGenesis.ToBlock()
-	}
-
-	genesisBlockHash := genesisBlock.Hash()
-
-	extra := genesisBlock.Extra()
-	if len(extra) > 32 {
-		return nil, fmt.Errorf("extra data is %d bytes, max is %d", len(extra), 32)
+// capellaExecutionHeader returns the execution payload header of the genesis
+// block, which adds the withdrawals root to the bellatrix header.
+func capellaExecutionHeader(g *genesisData) (*capella.ExecutionPayloadHeader, error) {
+	header, err := bellatrixExecutionHeader(g)
+	if err != nil {
+		return nil, err
 	}
 
-	baseFee, _ := uint256.FromBig(genesisBlock.BaseFee())
-
 	var withdrawalsRoot phase0.Root
 
-	if genesisBlock.Withdrawals() != nil {
-		root, err := beaconutils.ComputeWithdrawalsRoot(genesisBlock.Withdrawals(), b.clConfig)
+	if g.block.Withdrawals() != nil {
+		root, err := beaconutils.ComputeWithdrawalsRoot(g.block.Withdrawals(), g.clConfig)
 		if err != nil {
 			return nil, fmt.Errorf("failed to compute withdrawals root: %w", err)
 		}
@@ -70,128 +49,57 @@ func (b *capellaBuilder) BuildState() (*spec.VersionedBeaconState, error) {
 		withdrawalsRoot = root
 	}
 
-	transactionsRoot, err := beaconutils.ComputeTransactionsRoot(genesisBlock.Transactions(), b.clConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute transactions root: %w", err)
-	}
-
-	baseFeeBytes := baseFee.Bytes32()
-	for i, j := 0, len(baseFeeBytes)-1; i < j; i, j = i+1, j-1 {
-		baseFeeBytes[i], baseFeeBytes[j] = baseFeeBytes[j], baseFeeBytes[i]
-	}
-
-	execHeader := &capella.ExecutionPayloadHeader{
-		ParentHash:       phase0.Hash32(genesisBlock.ParentHash()),
-		FeeRecipient:     bellatrix.ExecutionAddress(genesisBlock.Coinbase()),
-		StateRoot:        phase0.Root(genesisBlock.Root()),
-		ReceiptsRoot:     phase0.Root(genesisBlock.ReceiptHash()),
-		LogsBloom:        genesisBlock.Bloom(),
-		BlockNumber:      genesisBlock.NumberU64(),
-		GasLimit:         genesisBlock.GasLimit(),
-		GasUsed:          genesisBlock.GasUsed(),
-		Timestamp:        genesisBlock.Time(),
-		ExtraData:        extra,
-		BaseFeePerGasLE:  baseFeeBytes,
-		BlockHash:        phase0.Hash32(genesisBlockHash),
-		TransactionsRoot: transactionsRoot,
+	return &capella.ExecutionPayloadHeader{
+		ParentHash:       header.ParentHash,
+		FeeRecipient:     header.FeeRecipient,
+		StateRoot:        header.StateRoot,
+		ReceiptsRoot:     header.ReceiptsRoot,
+		LogsBloom:        header.LogsBloom,
+		BlockNumber:      header.BlockNumber,
+		GasLimit:         header.GasLimit,
+		GasUsed:          header.GasUsed,
+		Timestamp:        header.Timestamp,
+		ExtraData:        header.ExtraData,
+		BaseFeePerGasLE:  header.BaseFeePerGasLE,
+		BlockHash:        header.BlockHash,
+		TransactionsRoot: header.TransactionsRoot,
 		WithdrawalsRoot:  withdrawalsRoot,
-	}
-
-	depositRoot, err := beaconutils.ComputeDepositRoot(b.clConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute deposit root: %w", err)
-	}
-
-	syncCommitteeSize := b.clConfig.GetUintDefault("SYNC_COMMITTEE_SIZE", 512)
-	syncCommitteeMaskBytes := syncCommitteeSize / 8
-
-	if syncCommitteeSize%8 != 0 {
-		syncCommitteeMaskBytes++
-	}
-
-	genesisBlockBody := &capella.BeaconBlockBody{
-		ETH1Data: &phase0.ETH1Data{
-			BlockHash: make([]byte, 32),
-		},
-		SyncAggregate: &altair.SyncAggregate{
-			SyncCommitteeBits: make([]byte, syncCommitteeMaskBytes),
-		},
-		ExecutionPayload: &capella.ExecutionPayload{},
-	}
-
-	genesisBlockBodyRoot, err := b.dynSsz.HashTreeRoot(genesisBlockBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute genesis block body root: %w", err)
-	}
-
-	clValidators, validatorsRoot := beaconutils.GetGenesisValidators(b.clConfig, b.validators)
+	}, nil
+}
 
-	syncCommittee, err := beaconutils.GetGenesisSyncCommittee(b.clConfig, clValidators, phase0.Hash32(genesisBlockHash))
+func buildCapellaState(g *genesisData, base *phase0.BeaconState) (*spec.VersionedBeaconState, error) {
+	execHeader, err := capellaExecutionHeader(g)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get genesis sync committee: %w", err)
-	}
-
-	genesisDelay := b.clConfig.GetUintDefault("GENESIS_DELAY", 604800)
-	blocksPerHistoricalRoot := b.clConfig.GetUintDefault("SLOTS_PER_HISTORICAL_ROOT", 8192)
-	epochsPerSlashingVector := b.clConfig.GetUintDefault("EPOCHS_PER_SLASHINGS_VECTOR", 8192)
-
-	minGenesisTime := b.clConfig.GetUintDefault("MIN_GENESIS_TIME", 0)
-	if minGenesisTime == 0 {
-		minGenesisTime = genesisBlock.Time()
+		return nil, err
 	}
 
 	genesisState := &capella.BeaconState{
-		GenesisTime:           minGenesisTime + genesisDelay,
-		GenesisValidatorsRoot: validatorsRoot,
-		Fork:                  GetStateForkConfig(spec.DataVersionCapella, b.clConfig),
-		LatestBlockHeader: &phase0.BeaconBlockHeader{
-			BodyRoot: genesisBlockBodyRoot,
-		},
-		BlockRoots: make([]phase0.Root, blocksPerHistoricalRoot),
-		StateRoots: make([]phase0.Root, blocksPerHistoricalRoot),
-		ETH1Data: &phase0.ETH1Data{
-			DepositRoot: depositRoot,
-			BlockHash:   genesisBlockHash[:],
-		},
-		JustificationBits:            make([]byte, 1),
-		PreviousJustifiedCheckpoint:  &phase0.Checkpoint{},
-		CurrentJustifiedCheckpoint:   &phase0.Checkpoint{},
-		FinalizedCheckpoint:          &phase0.Checkpoint{},
-		RANDAOMixes:                  beaconutils.SeedRandomMixes(phase0.Hash32(genesisBlockHash), b.clConfig),
-		Validators:                   clValidators,
-		Balances:                     beaconutils.GetGenesisBalances(b.clConfig, b.validators),
-		Slashings:                    make([]phase0.Gwei, epochsPerSlashingVector),
-		PreviousEpochParticipation:   make([]altair.ParticipationFlags, len(clValidators)),
-		CurrentEpochParticipation:    make([]altair.ParticipationFlags, len(clValidators)),
-		InactivityScores:             make([]uint64, len(clValidators)),
-		CurrentSyncCommittee:         syncCommittee,
-		NextSyncCommittee:            syncCommittee,
+		GenesisTime:                  base.GenesisTime,
+		GenesisValidatorsRoot:        base.GenesisValidatorsRoot,
+		Fork:                         base.Fork,
+		LatestBlockHeader:            base.LatestBlockHeader,
+		BlockRoots:                   base.BlockRoots,
+		StateRoots:                   base.StateRoots,
+		ETH1Data:                     base.ETH1Data,
+		ETH1DepositIndex:             base.ETH1DepositIndex,
+		JustificationBits:            base.JustificationBits,
+		PreviousJustifiedCheckpoint:  base.PreviousJustifiedCheckpoint,
+		CurrentJustifiedCheckpoint:   base.CurrentJustifiedCheckpoint,
+		FinalizedCheckpoint:          base.FinalizedCheckpoint,
+		RANDAOMixes:                  base.RANDAOMixes,
+		Validators:                   base.Validators,
+		Balances:                     base.Balances,
+		Slashings:                    base.Slashings,
+		PreviousEpochParticipation:   g.participationFlags(),
+		CurrentEpochParticipation:    g.participationFlags(),
+		InactivityScores:             g.inactivityScores(),
+		CurrentSyncCommittee:         g.syncCommittee,
+		NextSyncCommittee:            g.syncCommittee,
 		LatestExecutionPayloadHeader: execHeader,
 	}
 
-	versionedState := &spec.VersionedBeaconState{
+	return &spec.VersionedBeaconState{
 		Version: spec.DataVersionCapella,
 		Capella: genesisState,
-	}
-
-	logrus.Infof("genesis version: capella")
-	logrus.Infof("genesis time: %v", genesisState.GenesisTime)
-	logrus.Infof("genesis validators root: 0x%x", genesisState.GenesisValidatorsRoot)
-
-	return versionedState, nil
-}
-
-func (b *capellaBuilder) Serialize(state *spec.VersionedBeaconState, contentType http.ContentType) ([]byte, error) {
-	if state.Version != spec.DataVersionCapella {
-		return nil, fmt.Errorf("unsupported version: %s", state.Version)
-	}
-
-	switch contentType {
-	case http.ContentTypeSSZ:
-		return b.dynSsz.MarshalSSZ(state.Capella)
-	case http.ContentTypeJSON:
-		return state.Capella.MarshalJSON()
-	default:
-		return nil, fmt.Errorf("unsupported content type: %s", contentType)
-	}
+	}, nil
 }
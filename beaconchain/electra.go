@@ -1,206 +1,120 @@
 package beaconchain
 
 import (
-	"fmt"
-
 	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethpandaops/go-eth2-client/http"
 	"github.com/ethpandaops/go-eth2-client/spec"
-	"github.com/ethpandaops/go-eth2-client/spec/altair"
-	"github.com/ethpandaops/go-eth2-client/spec/bellatrix"
 	"github.com/ethpandaops/go-eth2-client/spec/deneb"
 	"github.com/ethpandaops/go-eth2-client/spec/electra"
 	"github.com/ethpandaops/go-eth2-client/spec/phase0"
 	"github.com/holiman/uint256"
-	"github.com/sirupsen/logrus"
 
 	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
 	"github.com/ethpandaops/eth-beacon-genesis/beaconutils"
-	"github.com/ethpandaops/eth-beacon-genesis/validators"
-	dynssz "github.com/pk910/dynamic-ssz"
 )
 
-type electraBuilder struct {
-	elGenesis       *core.Genesis
-	clConfig        *beaconconfig.Config
-	dynSsz          *dynssz.DynSsz
-	shadowForkBlock *types.Block
-	validators      []*validators.Validator
-}
+// unsetDepositRequestsStartIndex is UNSET_DEPOSIT_REQUESTS_START_INDEX.
+const unsetDepositRequestsStartIndex = uint64(18446744073709551615)
 
-func NewElectraBuilder(elGenesis *core.Genesis, clConfig *beaconconfig.Config) BeaconGenesisBuilder {
-	return &electraBuilder{
-		elGenesis: elGenesis,
-		clConfig:  clConfig,
-		dynSsz:    beaconutils.GetDynSSZ(clConfig),
-	}
+var electraGenesisFork = &genesisFork{
+	version:    spec.DataVersionElectra,
+	blockBody:  electraBlockBody,
+	buildState: buildElectraState,
 }
 
-func (b *electraBuilder) SetShadowForkBlock(block *types.Block) {
-	b.shadowForkBlock = block
-}
-
-func (b *electraBuilder) AddValidators(val []*validators.Validator) {
-	b.validators = append(b.validators, val...)
+func NewElectraBuilder(elGenesis *core.Genesis, clConfig *beaconconfig.Config) BeaconGenesisBuilder {
+	return newGenesisBuilder(elGenesis, clConfig, electraGenesisFork)
 }
 
-func (b *electraBuilder) BuildState() (*spec.VersionedBeaconState, error) {
-	genesisBlock := b.shadowForkBlock
-	if genesisBlock == nil {
-		genesisBlock = b.elGenesis.ToBlock()
-	}
-
-	genesisBlockHash := genesisBlock.Hash()
-
-	extra := genesisBlock.Extra()
-	if len(extra) > 32 {
-		return nil, fmt.Errorf("extra data is %d bytes, max is %d", len(extra), 32)
-	}
-
-	baseFee, _ := uint256.FromBig(genesisBlock.BaseFee())
-
-	var withdrawalsRoot phase0.Root
-
-	if genesisBlock.Withdrawals() != nil {
-		root, err := beaconutils.ComputeWithdrawalsRoot(genesisBlock.Withdrawals(), b.clConfig)
-		if err != nil {
-			return nil, fmt.Errorf("failed to compute withdrawals root: %w", err)
-		}
-
-		withdrawalsRoot = root
-	}
-
-	transactionsRoot, err := beaconutils.ComputeTransactionsRoot(genesisBlock.Transactions(), b.clConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute transactions root: %w", err)
-	}
-
-	if genesisBlock.BlobGasUsed() == nil {
-		return nil, fmt.Errorf("execution-layer Block has missing blob-gas-used field")
-	}
-
-	if genesisBlock.ExcessBlobGas() == nil {
-		return nil, fmt.Errorf("execution-layer Block has missing excess-blob-gas field")
-	}
-
-	execHeader := &deneb.ExecutionPayloadHeader{
-		ParentHash:       phase0.Hash32(genesisBlock.ParentHash()),
-		FeeRecipient:     bellatrix.ExecutionAddress(genesisBlock.Coinbase()),
-		StateRoot:        phase0.Root(genesisBlock.Root()),
-		ReceiptsRoot:     phase0.Root(genesisBlock.ReceiptHash()),
-		LogsBloom:        genesisBlock.Bloom(),
-		BlockNumber:      genesisBlock.NumberU64(),
-		GasLimit:         genesisBlock.GasLimit(),
-		GasUsed:          genesisBlock.GasUsed(),
-		Timestamp:        genesisBlock.Time(),
-		ExtraData:        extra,
-		BaseFeePerGas:    baseFee,
-		BlockHash:        phase0.Hash32(genesisBlockHash),
-		TransactionsRoot: transactionsRoot,
-		WithdrawalsRoot:  withdrawalsRoot,
-		BlobGasUsed:      *genesisBlock.BlobGasUsed(),
-		ExcessBlobGas:    *genesisBlock.ExcessBlobGas(),
-	}
-
-	depositRoot, err := beaconutils.ComputeDepositRoot(b.clConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute deposit root: %w", err)
-	}
-
-	syncCommitteeSize := b.clConfig.GetUintDefault("SYNC_COMMITTEE_SIZE", 512)
-	syncCommitteeMaskBytes := syncCommitteeSize / 8
-
-	if syncCommitteeSize%8 != 0 {
-		syncCommitteeMaskBytes++
-	}
-
-	genesisBlockBody := &electra.BeaconBlockBody{
-		ETH1Data: &phase0.ETH1Data{
-			BlockHash: make([]byte, 32),
-		},
-		SyncAggregate: &altair.SyncAggregate{
-			SyncCommitteeBits: make([]byte, syncCommitteeMaskBytes),
-		},
+func electraBlockBody(g *genesisData) (any, error) {
+	return &electra.BeaconBlockBody{
+		ETH1Data:      g.emptyETH1Data(),
+		SyncAggregate: g.emptySyncAggregate(),
 		ExecutionPayload: &deneb.ExecutionPayload{
 			BaseFeePerGas: uint256.NewInt(0),
 		},
 		ExecutionRequests: &electra.ExecutionRequests{},
-	}
-
-	genesisBlockBodyRoot, err := b.dynSsz.HashTreeRoot(genesisBlockBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute genesis block body root: %w", err)
-	}
-
-	clValidators, validatorsRoot := beaconutils.GetGenesisValidators(b.clConfig, b.validators)
+	}, nil
+}
 
-	syncCommittee, err := beaconutils.GetGenesisSyncCommittee(b.clConfig, clValidators, phase0.Hash32(genesisBlockHash))
+func buildElectraState(g *genesisData, base *phase0.BeaconState) (*spec.VersionedBeaconState, error) {
+	execHeader, err := denebExecutionHeader(g)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get genesis sync committee: %w", err)
+		return nil, err
 	}
 
-	genesisDelay := b.clConfig.GetUintDefault("GENESIS_DELAY", 604800)
-	blocksPerHistoricalRoot := b.clConfig.GetUintDefault("SLOTS_PER_HISTORICAL_ROOT", 8192)
-	epochsPerSlashingVector := b.clConfig.GetUintDefault("EPOCHS_PER_SLASHINGS_VECTOR", 8192)
-
-	minGenesisTime := b.clConfig.GetUintDefault("MIN_GENESIS_TIME", 0)
-	if minGenesisTime == 0 {
-		minGenesisTime = genesisBlock.Time()
-	}
+	churn := getElectraChurnFields(g.clConfig, base.Validators, 0)
 
 	genesisState := &electra.BeaconState{
-		GenesisTime:           minGenesisTime + genesisDelay,
-		GenesisValidatorsRoot: validatorsRoot,
-		Fork:                  GetStateForkConfig(spec.DataVersionElectra, b.clConfig),
-		LatestBlockHeader: &phase0.BeaconBlockHeader{
-			BodyRoot: genesisBlockBodyRoot,
-		},
-		BlockRoots: make([]phase0.Root, blocksPerHistoricalRoot),
-		StateRoots: make([]phase0.Root, blocksPerHistoricalRoot),
-		ETH1Data: &phase0.ETH1Data{
-			DepositRoot: depositRoot,
-			BlockHash:   genesisBlockHash[:],
-		},
-		JustificationBits:            make([]byte, 1),
-		PreviousJustifiedCheckpoint:  &phase0.Checkpoint{},
-		CurrentJustifiedCheckpoint:   &phase0.Checkpoint{},
-		FinalizedCheckpoint:          &phase0.Checkpoint{},
-		RANDAOMixes:                  beaconutils.SeedRandomMixes(phase0.Hash32(genesisBlockHash), b.clConfig),
-		Validators:                   clValidators,
-		Balances:                     beaconutils.GetGenesisBalances(b.clConfig, b.validators),
-		Slashings:                    make([]phase0.Gwei, epochsPerSlashingVector),
-		PreviousEpochParticipation:   make([]altair.ParticipationFlags, len(clValidators)),
-		CurrentEpochParticipation:    make([]altair.ParticipationFlags, len(clValidators)),
-		InactivityScores:             make([]uint64, len(clValidators)),
-		CurrentSyncCommittee:         syncCommittee,
-		NextSyncCommittee:            syncCommittee,
-		LatestExecutionPayloadHeader: execHeader,
-	}
-
-	versionedState := &spec.VersionedBeaconState{
+		GenesisTime:                   base.GenesisTime,
+		GenesisValidatorsRoot:         base.GenesisValidatorsRoot,
+		Fork:                          base.Fork,
+		LatestBlockHeader:             base.LatestBlockHeader,
+		BlockRoots:                    base.BlockRoots,
+		StateRoots:                    base.StateRoots,
+		ETH1Data:                      base.ETH1Data,
+		ETH1DepositIndex:              base.ETH1DepositIndex,
+		JustificationBits:             base.JustificationBits,
+		PreviousJustifiedCheckpoint:   base.PreviousJustifiedCheckpoint,
+		CurrentJustifiedCheckpoint:    base.CurrentJustifiedCheckpoint,
+		FinalizedCheckpoint:           base.FinalizedCheckpoint,
+		RANDAOMixes:                   base.RANDAOMixes,
+		Validators:                    base.Validators,
+		Balances:                      base.Balances,
+		Slashings:                     base.Slashings,
+		PreviousEpochParticipation:    g.participationFlags(),
+		CurrentEpochParticipation:     g.participationFlags(),
+		InactivityScores:              g.inactivityScores(),
+		CurrentSyncCommittee:          g.syncCommittee,
+		NextSyncCommittee:             g.syncCommittee,
+		LatestExecutionPayloadHeader:  execHeader,
+		DepositRequestsStartIndex:     churn.depositRequestsStartIndex,
+		DepositBalanceToConsume:       churn.depositBalanceToConsume,
+		ExitBalanceToConsume:          churn.exitBalanceToConsume,
+		EarliestExitEpoch:             churn.earliestExitEpoch,
+		ConsolidationBalanceToConsume: churn.consolidationBalanceToConsume,
+		EarliestConsolidationEpoch:    churn.earliestConsolidationEpoch,
+	}
+
+	return &spec.VersionedBeaconState{
 		Version: spec.DataVersionElectra,
 		Electra: genesisState,
-	}
-
-	logrus.Infof("genesis version: electra")
-	logrus.Infof("genesis time: %v", genesisState.GenesisTime)
-	logrus.Infof("genesis validators root: 0x%x", genesisState.GenesisValidatorsRoot)
+	}, nil
+}
 
-	return versionedState, nil
+// electraChurnFields holds the deposit request and churn fields added to the
+// beacon state in electra.
+type electraChurnFields struct {
+	depositRequestsStartIndex     uint64
+	depositBalanceToConsume       phase0.Gwei
+	exitBalanceToConsume          phase0.Gwei
+	earliestExitEpoch             phase0.Epoch
+	consolidationBalanceToConsume phase0.Gwei
+	earliestConsolidationEpoch    phase0.Epoch
 }
 
-func (b *electraBuilder) Serialize(state *spec.VersionedBeaconState, contentType http.ContentType) ([]byte, error) {
-	if state.Version != spec.DataVersionElectra {
-		return nil, fmt.Errorf("unsupported version: %s", state.Version)
+// getElectraChurnFields returns the electra fields of a state with validators
+// at epoch, like upgrade_to_electra sets them. Deposit requests are not
+// processed yet, so the start index is UNSET_DEPOSIT_REQUESTS_START_INDEX as
+// in initialize_beacon_state_from_eth1.
+func getElectraChurnFields(clConfig *beaconconfig.Config, validators []*phase0.Validator, epoch phase0.Epoch) *electraChurnFields {
+	farFutureEpoch := phase0.Epoch(clConfig.GetUintDefault("FAR_FUTURE_EPOCH", 18446744073709551615))
+	activationExitEpoch := beaconutils.ComputeActivationExitEpoch(clConfig, epoch)
+
+	earliestExitEpoch := activationExitEpoch
+	for _, validator := range validators {
+		if validator.ExitEpoch != farFutureEpoch && validator.ExitEpoch > earliestExitEpoch {
+			earliestExitEpoch = validator.ExitEpoch
+		}
 	}
 
-	switch contentType {
-	case http.ContentTypeSSZ:
-		return b.dynSsz.MarshalSSZ(state.Electra)
-	case http.ContentTypeJSON:
-		return state.Electra.MarshalJSON()
-	default:
-		return nil, fmt.Errorf("unsupported content type: %s", contentType)
+	totalActiveBalance := beaconutils.GetTotalActiveBalance(clConfig, validators, epoch)
+
+	return &electraChurnFields{
+		depositRequestsStartIndex:     unsetDepositRequestsStartIndex,
+		depositBalanceToConsume:       0,
+		exitBalanceToConsume:          beaconutils.GetActivationExitChurnLimit(clConfig, totalActiveBalance),
+		earliestExitEpoch:             earliestExitEpoch + 1,
+		consolidationBalanceToConsume: beaconutils.GetConsolidationChurnLimit(clConfig, totalActiveBalance),
+		earliestConsolidationEpoch:    activationExitEpoch,
 	}
 }
@@ -0,0 +1,175 @@
+package beaconchain
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ethpandaops/go-eth2-client/spec"
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+)
+
+// TestElectraGenesisChurnFields checks the electra fields of genesis states
+// against values computed by hand from the spec functions.
+func TestElectraGenesisChurnFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		// expected values
+		exitBalanceToConsume          phase0.Gwei
+		earliestExitEpoch             phase0.Epoch
+		consolidationBalanceToConsume phase0.Gwei
+		earliestConsolidationEpoch    phase0.Epoch
+	}{
+		{
+			// 68 active validators with 32 ETH (the 0x02 validators are capped
+			// at MAX_EFFECTIVE_BALANCE without ELECTRA_FORK_EPOCH: 0), so
+			// get_balance_churn_limit is max(68 * 32 ETH / 65536, 128 ETH
+			// MIN_PER_EPOCH_CHURN_LIMIT_ELECTRA) = 128 ETH. The 256 ETH
+			// activation exit cap leaves it at 128 ETH for activations and
+			// exits and nothing for consolidations
+			name:                          "default churn",
+			exitBalanceToConsume:          128_000_000_000,
+			earliestExitEpoch:             6,
+			consolidationBalanceToConsume: 0,
+			earliestConsolidationEpoch:    5,
+		},
+		{
+			// max(68 * 32 ETH / 32, 1 ETH) = 68 ETH churn, capped at 64 ETH
+			// for activations and exits, the other 4 ETH for consolidations
+			name: "custom churn",
+			config: `
+MIN_PER_EPOCH_CHURN_LIMIT_ELECTRA: 1000000000
+CHURN_LIMIT_QUOTIENT: 32
+MAX_PER_EPOCH_ACTIVATION_EXIT_CHURN_LIMIT: 64000000000
+`,
+			exitBalanceToConsume:          64_000_000_000,
+			earliestExitEpoch:             6,
+			consolidationBalanceToConsume: 4_000_000_000,
+			earliestConsolidationEpoch:    5,
+		},
+		{
+			// compute_activation_exit_epoch(0) = 1 + MAX_SEED_LOOKAHEAD
+			name: "max seed lookahead",
+			config: `
+MAX_SEED_LOOKAHEAD: 8
+`,
+			exitBalanceToConsume:          128_000_000_000,
+			earliestExitEpoch:             10,
+			consolidationBalanceToConsume: 0,
+			earliestConsolidationEpoch:    9,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clConfig := createTestGenesisConfigWith(t, test.config)
+			vals := createTestGenesisValidators(t)[:68]
+
+			for _, version := range []spec.DataVersion{spec.DataVersionElectra, spec.DataVersionFulu, spec.DataVersionGloas} {
+				builder := GetForkConfig(version).BuilderFn(createTestELGenesis(), clConfig)
+				builder.AddValidators(vals)
+
+				state, err := builder.BuildState()
+				if err != nil {
+					t.Fatalf("failed to build %s state: %v", version, err)
+				}
+
+				churn := getStateChurnFields(t, state)
+				expected := &electraChurnFields{
+					depositRequestsStartIndex:     18446744073709551615,
+					depositBalanceToConsume:       0,
+					exitBalanceToConsume:          test.exitBalanceToConsume,
+					earliestExitEpoch:             test.earliestExitEpoch,
+					consolidationBalanceToConsume: test.consolidationBalanceToConsume,
+					earliestConsolidationEpoch:    test.earliestConsolidationEpoch,
+				}
+
+				if *churn != *expected {
+					t.Fatalf("unexpected %s churn fields: got %+v, expected %+v", version, *churn, *expected)
+				}
+			}
+		})
+	}
+}
+
+// TestElectraGenesisChurnFields_MatchUpgrade checks that an electra genesis
+// has the same electra fields as a deneb genesis upgraded to electra.
+func TestElectraGenesisChurnFields_MatchUpgrade(t *testing.T) {
+	clConfig := createTestGenesisConfig(t)
+	vals := createTestGenesisValidators(t)[:64]
+
+	denebBuilder := NewDenebBuilder(createTestELGenesis(), clConfig)
+	denebBuilder.AddValidators(vals)
+
+	electraBuilder := NewElectraBuilder(createTestELGenesis(), clConfig)
+	electraBuilder.AddValidators(vals)
+
+	denebState, err := denebBuilder.BuildState()
+	if err != nil {
+		t.Fatalf("failed to build deneb state: %v", err)
+	}
+
+	electraState, err := electraBuilder.BuildState()
+	if err != nil {
+		t.Fatalf("failed to build electra state: %v", err)
+	}
+
+	upgradedState, err := UpgradeState(denebState, spec.DataVersionElectra, clConfig)
+	if err != nil {
+		t.Fatalf("failed to upgrade state: %v", err)
+	}
+
+	diffs, err := DiffStates(electraState, upgradedState)
+	if err != nil {
+		t.Fatalf("failed to compare states: %v", err)
+	}
+
+	for _, field := range []string{
+		"deposit_requests_start_index", "deposit_balance_to_consume", "exit_balance_to_consume",
+		"earliest_exit_epoch", "consolidation_balance_to_consume", "earliest_consolidation_epoch",
+	} {
+		if slices.Contains(diffs, field) {
+			t.Fatalf("%s differs between electra genesis and upgraded deneb genesis", field)
+		}
+	}
+}
+
+// getStateChurnFields returns the electra fields of an electra, fulu or gloas
+// state.
+func getStateChurnFields(t *testing.T, state *spec.VersionedBeaconState) *electraChurnFields {
+	t.Helper()
+
+	switch state.Version {
+	case spec.DataVersionElectra:
+		return &electraChurnFields{
+			depositRequestsStartIndex:     state.Electra.DepositRequestsStartIndex,
+			depositBalanceToConsume:       state.Electra.DepositBalanceToConsume,
+			exitBalanceToConsume:          state.Electra.ExitBalanceToConsume,
+			earliestExitEpoch:             state.Electra.EarliestExitEpoch,
+			consolidationBalanceToConsume: state.Electra.ConsolidationBalanceToConsume,
+			earliestConsolidationEpoch:    state.Electra.EarliestConsolidationEpoch,
+		}
+	case spec.DataVersionFulu:
+		return &electraChurnFields{
+			depositRequestsStartIndex:     state.Fulu.DepositRequestsStartIndex,
+			depositBalanceToConsume:       state.Fulu.DepositBalanceToConsume,
+			exitBalanceToConsume:          state.Fulu.ExitBalanceToConsume,
+			earliestExitEpoch:             state.Fulu.EarliestExitEpoch,
+			consolidationBalanceToConsume: state.Fulu.ConsolidationBalanceToConsume,
+			earliestConsolidationEpoch:    state.Fulu.EarliestConsolidationEpoch,
+		}
+	case spec.DataVersionGloas:
+		return &electraChurnFields{
+			depositRequestsStartIndex:     state.Gloas.DepositRequestsStartIndex,
+			depositBalanceToConsume:       state.Gloas.DepositBalanceToConsume,
+			exitBalanceToConsume:          state.Gloas.ExitBalanceToConsume,
+			earliestExitEpoch:             state.Gloas.EarliestExitEpoch,
+			consolidationBalanceToConsume: state.Gloas.ConsolidationBalanceToConsume,
+			earliestConsolidationEpoch:    state.Gloas.EarliestConsolidationEpoch,
+		}
+	default:
+		t.Fatalf("state version %s has no electra fields", state.Version)
+
+		return nil
+	}
+}
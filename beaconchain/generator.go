@@ -1,6 +1,9 @@
 package beaconchain
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethpandaops/go-eth2-client/http"
@@ -12,6 +15,17 @@ import (
 	"github.com/ethpandaops/eth-beacon-genesis/validators"
 )
 
+// Genesis state construction modes.
+const (
+	// ConstructionDirect builds the genesis state with the fork specific
+	// genesis builder of the genesis fork.
+	ConstructionDirect = "direct"
+
+	// ConstructionUpgradeChain builds a phase0 genesis state and applies the
+	// fork upgrades up to the genesis fork.
+	ConstructionUpgradeChain = "upgrade-chain"
+)
+
 type NewBeaconGenesisBuilderFn func(elGenesis *core.Genesis, clConfig *beaconconfig.Config) BeaconGenesisBuilder
 
 type BeaconGenesisBuilder interface {
@@ -21,6 +35,31 @@ type BeaconGenesisBuilder interface {
 	Serialize(state *spec.VersionedBeaconState, contentType http.ContentType) ([]byte, error)
 }
 
+// BuilderRegistry is implemented by the genesis builders of forks with a
+// builder registry (gloas and later).
+type BuilderRegistry interface {
+	AddBuilders(builders []*validators.Builder)
+
+	// GetGenesisRegistry returns the validators and builders of the genesis
+	// state. Validators with builder (0x03) withdrawal credentials are moved
+	// to the builder registry, after the added builders.
+	GetGenesisRegistry() ([]*validators.Validator, []*validators.Builder)
+}
+
+// DepositTree is implemented by the genesis builders that can build the
+// deposit tree from the deposits of the genesis validators instead of using
+// the empty deposit tree. All validators need a deposit signature then.
+type DepositTree interface {
+	SetDepositTree(enabled bool)
+}
+
+// PreMerge is implemented by the genesis builders that can build a pre-merge
+// bellatrix genesis state with an empty execution payload header. The merge
+// happens when the execution chain reaches TERMINAL_TOTAL_DIFFICULTY then.
+type PreMerge interface {
+	SetPreMerge(enabled bool)
+}
+
 type ForkConfig struct {
 	Version      spec.DataVersion
 	EpochField   string
@@ -106,6 +145,17 @@ func GetForkConfig(version spec.DataVersion) *ForkConfig {
 	return nil
 }
 
+// GetForkVersionByName returns the fork with the given name (phase0, altair, …).
+func GetForkVersionByName(name string) (spec.DataVersion, error) {
+	for _, forkConfig := range ForkConfigs {
+		if strings.EqualFold(forkConfig.Version.String(), name) {
+			return forkConfig.Version, nil
+		}
+	}
+
+	return spec.DataVersionUnknown, fmt.Errorf("unknown fork %q", name)
+}
+
 func GetStateForkConfig(version spec.DataVersion, cfg *beaconconfig.Config) *phase0.Fork {
 	thisForkConfig := GetForkConfig(version)
 
@@ -137,3 +187,16 @@ func NewGenesisBuilder(elGenesis *core.Genesis, clConfig *beaconconfig.Config) B
 
 	return forkConfig.BuilderFn(elGenesis, clConfig)
 }
+
+// NewGenesisBuilderWithMode returns the genesis builder of the genesis fork
+// for a construction mode (ConstructionDirect or ConstructionUpgradeChain).
+func NewGenesisBuilderWithMode(elGenesis *core.Genesis, clConfig *beaconconfig.Config, mode string) (BeaconGenesisBuilder, error) {
+	switch mode {
+	case "", ConstructionDirect:
+		return NewGenesisBuilder(elGenesis, clConfig), nil
+	case ConstructionUpgradeChain:
+		return NewUpgradeChainBuilder(elGenesis, clConfig), nil
+	default:
+		return nil, fmt.Errorf("unsupported construction mode %q", mode)
+	}
+}
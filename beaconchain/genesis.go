@@ -0,0 +1,278 @@
+package beaconchain
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethpandaops/go-eth2-client/http"
+	"github.com/ethpandaops/go-eth2-client/spec"
+	"github.com/ethpandaops/go-eth2-client/spec/altair"
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+	dynssz "github.com/pk910/dynamic-ssz"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
+	"github.com/ethpandaops/eth-beacon-genesis/beaconutils"
+	"github.com/ethpandaops/eth-beacon-genesis/validators"
+)
+
+// genesisFork defines the fork specific parts of a genesis state. All other
+// steps of the state construction are shared by the genesisBuilder.
+type genesisFork struct {
+	version spec.DataVersion
+
+	// blockBody returns the empty body of the genesis block. Its root is the
+	// body root of the latest block header. The validators are not set yet.
+	blockBody func(g *genesisData) (any, error)
+
+	// buildState returns the genesis state from the fields shared by all forks
+	// (base) and the fork specific fields. It is unset for builders that
+	// override BuildState.
+	buildState func(g *genesisData, base *phase0.BeaconState) (*spec.VersionedBeaconState, error)
+}
+
+// getGenesisFork returns the fork specific parts of the genesis state of version.
+func getGenesisFork(version spec.DataVersion) *genesisFork {
+	switch version {
+	case spec.DataVersionPhase0:
+		return phase0GenesisFork
+	case spec.DataVersionAltair:
+		return altairGenesisFork
+	case spec.DataVersionBellatrix:
+		return bellatrixGenesisFork
+	case spec.DataVersionCapella:
+		return capellaGenesisFork
+	case spec.DataVersionDeneb:
+		return denebGenesisFork
+	case spec.DataVersionElectra:
+		return electraGenesisFork
+	case spec.DataVersionFulu:
+		return fuluGenesisFork
+	case spec.DataVersionGloas:
+		return gloasGenesisFork
+	default:
+		return nil
+	}
+}
+
+// genesisData holds the inputs and shared results of a genesis state build.
+type genesisData struct {
+	clConfig      *beaconconfig.Config
+	dynSsz        *dynssz.DynSsz
+	block         *types.Block
+	blockHash     phase0.Hash32
+	preMerge      bool
+	validators    []*validators.Validator
+	clValidators  []*phase0.Validator
+	syncCommittee *altair.SyncCommittee
+}
+
+// genesisBuilder is the BeaconGenesisBuilder of all forks.
+type genesisBuilder struct {
+	elGenesis       *core.Genesis
+	clConfig        *beaconconfig.Config
+	dynSsz          *dynssz.DynSsz
+	fork            *genesisFork
+	shadowForkBlock *types.Block
+	validators      []*validators.Validator
+	depositTree     bool
+	preMerge        bool
+}
+
+func newGenesisBuilder(elGenesis *core.Genesis, clConfig *beaconconfig.Config, fork *genesisFork) *genesisBuilder {
+	return &genesisBuilder{
+		elGenesis: elGenesis,
+		clConfig:  clConfig,
+		dynSsz:    beaconutils.GetDynSSZ(clConfig),
+		fork:      fork,
+	}
+}
+
+func (b *genesisBuilder) SetShadowForkBlock(block *types.Block) {
+	b.shadowForkBlock = block
+}
+
+func (b *genesisBuilder) SetDepositTree(enabled bool) {
+	b.depositTree = enabled
+}
+
+func (b *genesisBuilder) SetPreMerge(enabled bool) {
+	b.preMerge = enabled
+}
+
+func (b *genesisBuilder) AddValidators(val []*validators.Validator) {
+	b.validators = append(b.validators, val...)
+}
+
+func (b *genesisBuilder) BuildState() (*spec.VersionedBeaconState, error) {
+	return b.buildState(b.validators, b.fork.buildState)
+}
+
+// buildState runs the shared state construction for vals and passes the
+// result to buildFn for the fork specific fields.
+func (b *genesisBuilder) buildState(vals []*validators.Validator, buildFn func(g *genesisData, base *phase0.BeaconState) (*spec.VersionedBeaconState, error)) (*spec.VersionedBeaconState, error) {
+	genesisBlock := b.shadowForkBlock
+	if genesisBlock == nil {
+		genesisBlock = b.elGenesis.ToBlock()
+	}
+
+	extra := genesisBlock.Extra()
+	if len(extra) > 32 {
+		return nil, fmt.Errorf("extra data is %d bytes, max is %d", len(extra), 32)
+	}
+
+	if b.preMerge {
+		if b.fork.version != spec.DataVersionBellatrix {
+			return nil, fmt.Errorf("pre-merge genesis requires bellatrix as genesis fork, got %s", b.fork.version.String())
+		}
+
+		if err := checkPreMergeConfig(b.elGenesis, b.clConfig, genesisBlock); err != nil {
+			return nil, fmt.Errorf("invalid pre-merge genesis: %w", err)
+		}
+	}
+
+	g := &genesisData{
+		clConfig:   b.clConfig,
+		dynSsz:     b.dynSsz,
+		block:      genesisBlock,
+		blockHash:  phase0.Hash32(genesisBlock.Hash()),
+		preMerge:   b.preMerge,
+		validators: vals,
+	}
+
+	var deposits []*phase0.DepositData
+
+	if b.depositTree {
+		var err error
+
+		deposits, err = beaconutils.GetGenesisDeposits(b.clConfig, vals)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get genesis deposits: %w", err)
+		}
+	}
+
+	depositRoot, err := beaconutils.ComputeDepositTreeRoot(b.clConfig, deposits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute deposit root: %w", err)
+	}
+
+	genesisBlockBody, err := b.fork.blockBody(g)
+	if err != nil {
+		return nil, err
+	}
+
+	genesisBlockBodyRoot, err := b.dynSsz.HashTreeRoot(genesisBlockBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute genesis block body root: %w", err)
+	}
+
+	clValidators, validatorsRoot := beaconutils.GetGenesisValidators(b.clConfig, vals)
+	g.clValidators = clValidators
+
+	if b.fork.version >= spec.DataVersionAltair {
+		g.syncCommittee, err = beaconutils.GetGenesisSyncCommittee(b.clConfig, clValidators, g.blockHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get genesis sync committee: %w", err)
+		}
+	}
+
+	genesisDelay := b.clConfig.GetUintDefault("GENESIS_DELAY", 604800)
+	blocksPerHistoricalRoot := b.clConfig.GetUintDefault("SLOTS_PER_HISTORICAL_ROOT", 8192)
+	epochsPerSlashingVector := b.clConfig.GetUintDefault("EPOCHS_PER_SLASHINGS_VECTOR", 8192)
+
+	minGenesisTime := b.clConfig.GetUintDefault("MIN_GENESIS_TIME", 0)
+	if minGenesisTime == 0 {
+		minGenesisTime = genesisBlock.Time()
+	}
+
+	base := &phase0.BeaconState{
+		GenesisTime:           minGenesisTime + genesisDelay,
+		GenesisValidatorsRoot: validatorsRoot,
+		Fork:                  GetStateForkConfig(b.fork.version, b.clConfig),
+		LatestBlockHeader: &phase0.BeaconBlockHeader{
+			BodyRoot: genesisBlockBodyRoot,
+		},
+		BlockRoots: make([]phase0.Root, blocksPerHistoricalRoot),
+		StateRoots: make([]phase0.Root, blocksPerHistoricalRoot),
+		ETH1Data: &phase0.ETH1Data{
+			DepositRoot:  depositRoot,
+			DepositCount: uint64(len(deposits)),
+			BlockHash:    g.blockHash[:],
+		},
+		ETH1DepositIndex:            uint64(len(deposits)),
+		JustificationBits:           make([]byte, 1),
+		PreviousJustifiedCheckpoint: &phase0.Checkpoint{},
+		CurrentJustifiedCheckpoint:  &phase0.Checkpoint{},
+		FinalizedCheckpoint:         &phase0.Checkpoint{},
+		RANDAOMixes:                 beaconutils.SeedRandomMixes(g.blockHash, b.clConfig),
+		Validators:                  clValidators,
+		Balances:                    beaconutils.GetGenesisBalances(b.clConfig, vals),
+		Slashings:                   make([]phase0.Gwei, epochsPerSlashingVector),
+	}
+
+	versionedState, err := buildFn(g, base)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("genesis version: %s", b.fork.version.String())
+	logrus.Infof("genesis time: %v", base.GenesisTime)
+	logrus.Infof("genesis validators root: 0x%x", base.GenesisValidatorsRoot)
+	logrus.Infof("genesis execution block: %d (hash: %s)", genesisBlock.NumberU64(), genesisBlock.Hash().String())
+
+	if b.depositTree {
+		logrus.Infof("genesis deposit root: 0x%x (%d deposits)", depositRoot, len(deposits))
+	}
+
+	return versionedState, nil
+}
+
+func (b *genesisBuilder) Serialize(state *spec.VersionedBeaconState, contentType http.ContentType) ([]byte, error) {
+	if state.Version != b.fork.version {
+		return nil, fmt.Errorf("unsupported version: %s", state.Version)
+	}
+
+	return serializeState(b.dynSsz, state, contentType)
+}
+
+// emptyETH1Data returns the eth1 data of the genesis block body.
+func (g *genesisData) emptyETH1Data() *phase0.ETH1Data {
+	return &phase0.ETH1Data{
+		BlockHash: make([]byte, 32),
+	}
+}
+
+// emptySyncAggregate returns the sync aggregate of the genesis block body.
+func (g *genesisData) emptySyncAggregate() *altair.SyncAggregate {
+	syncCommitteeSize := g.clConfig.GetUintDefault("SYNC_COMMITTEE_SIZE", 512)
+	syncCommitteeMaskBytes := syncCommitteeSize / 8
+
+	if syncCommitteeSize%8 != 0 {
+		syncCommitteeMaskBytes++
+	}
+
+	return &altair.SyncAggregate{
+		SyncCommitteeBits: make([]byte, syncCommitteeMaskBytes),
+	}
+}
+
+// participationFlags returns empty participation flags for all validators.
+func (g *genesisData) participationFlags() []altair.ParticipationFlags {
+	return make([]altair.ParticipationFlags, len(g.clValidators))
+}
+
+// inactivityScores returns zero inactivity scores for all validators.
+func (g *genesisData) inactivityScores() []uint64 {
+	return make([]uint64, len(g.clValidators))
+}
+
+// proposerLookahead returns the proposer lookahead of the genesis state.
+func (g *genesisData) proposerLookahead() ([]phase0.ValidatorIndex, error) {
+	proposers, err := beaconutils.GetGenesisProposers(g.clConfig, g.clValidators, g.blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate proposer lookahead: %w", err)
+	}
+
+	return proposers, nil
+}
@@ -0,0 +1,337 @@
+package beaconchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethpandaops/go-eth2-client/http"
+	"github.com/ethpandaops/go-eth2-client/spec"
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+
+	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
+	"github.com/ethpandaops/eth-beacon-genesis/beaconutils"
+	"github.com/ethpandaops/eth-beacon-genesis/validators"
+)
+
+const testGenesisMnemonic = "rare observe fox place unfold bargain cannon direct title sorry rabbit juice body autumn quality decrease mixture transfer crisp unveil path depend brick scissors"
+
+func writeTestFile(t *testing.T, dir, name, data string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil { //nolint:gosec // test file
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+
+	return path
+}
+
+func createTestGenesisConfig(t *testing.T) *beaconconfig.Config {
+	t.Helper()
+
+	return createTestGenesisConfigWith(t, "")
+}
+
+// createTestGenesisConfigWith returns the test config with additional YAML
+// config lines.
+func createTestGenesisConfigWith(t *testing.T, extra string) *beaconconfig.Config {
+	t.Helper()
+
+	configPath := writeTestFile(t, t.TempDir(), "config.yaml", `
+PRESET_BASE: minimal
+MIN_GENESIS_TIME: 1700000000
+GENESIS_DELAY: 60
+GENESIS_FORK_VERSION: 0x10000038
+ALTAIR_FORK_VERSION: 0x20000038
+BELLATRIX_FORK_VERSION: 0x30000038
+CAPELLA_FORK_VERSION: 0x40000038
+DENEB_FORK_VERSION: 0x50000038
+ELECTRA_FORK_VERSION: 0x60000038
+FULU_FORK_VERSION: 0x70000038
+GLOAS_FORK_VERSION: 0x80000038
+DEPOSIT_CONTRACT_ADDRESS: 0x4242424242424242424242424242424242424242
+`+extra)
+
+	cfg, err := beaconconfig.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	return cfg
+}
+
+func createTestGenesisValidators(t *testing.T) []*validators.Validator {
+	t.Helper()
+
+	mnemonicsPath := writeTestFile(t, t.TempDir(), "mnemonics.yaml", `
+- mnemonic: "`+testGenesisMnemonic+`"
+  count: 64
+- mnemonic: "`+testGenesisMnemonic+`"
+  start: 64
+  count: 4
+  balance: 64000000000
+  wd_prefix: "0x02"
+  wd_address: "0x1234567890abcdef1234567890abcdef12345678"
+- mnemonic: "`+testGenesisMnemonic+`"
+  start: 68
+  count: 2
+  wd_prefix: "0x03"
+  wd_address: "0x1234567890abcdef1234567890abcdef12345678"
+`)
+
+	vals, err := validators.GenerateValidatorsByMnemonic(mnemonicsPath)
+	if err != nil {
+		t.Fatalf("failed to generate validators: %v", err)
+	}
+
+	return vals
+}
+
+func createTestELGenesis() *core.Genesis {
+	return &core.Genesis{
+		Config:     params.AllDevChainProtocolChanges,
+		Timestamp:  1700000000,
+		ExtraData:  []byte("eth-beacon-genesis"),
+		GasLimit:   30_000_000,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		Difficulty: big.NewInt(0),
+	}
+}
+
+func TestGenesisBuilder_SerializeVersionMismatch(t *testing.T) {
+	clConfig := createTestGenesisConfig(t)
+	elGenesis := createTestELGenesis()
+	vals := createTestGenesisValidators(t)
+
+	builder := NewCapellaBuilder(elGenesis, clConfig)
+	builder.AddValidators(vals)
+
+	state, err := builder.BuildState()
+	if err != nil {
+		t.Fatalf("failed to build state: %v", err)
+	}
+
+	if _, err := NewDenebBuilder(elGenesis, clConfig).Serialize(state, http.ContentTypeSSZ); err == nil {
+		t.Fatalf("expected error when serializing a capella state with the deneb builder")
+	}
+}
+
+func TestGenesisBuilder_ExtraDataTooLong(t *testing.T) {
+	clConfig := createTestGenesisConfig(t)
+	elGenesis := createTestELGenesis()
+	elGenesis.ExtraData = make([]byte, 33)
+
+	builder := NewPhase0Builder(elGenesis, clConfig)
+	builder.AddValidators(createTestGenesisValidators(t))
+
+	if _, err := builder.BuildState(); err == nil {
+		t.Fatalf("expected error for 33 bytes extra data")
+	}
+}
+
+func TestGenesisBuilder_DepositTree(t *testing.T) {
+	clConfig := createTestGenesisConfig(t)
+	elGenesis := createTestELGenesis()
+
+	mnemonicsPath := writeTestFile(t, t.TempDir(), "mnemonics.yaml", `
+- mnemonic: "`+testGenesisMnemonic+`"
+  count: 8
+- mnemonic: "`+testGenesisMnemonic+`"
+  start: 8
+  count: 2
+  balance: 64000000000
+  wd_prefix: "0x02"
+  wd_address: "0x1234567890abcdef1234567890abcdef12345678"
+`)
+
+	vals, err := validators.GenerateValidatorsByMnemonic(mnemonicsPath)
+	if err != nil {
+		t.Fatalf("failed to generate validators: %v", err)
+	}
+
+	builder := NewElectraBuilder(elGenesis, clConfig)
+	builder.AddValidators(vals)
+	builder.(DepositTree).SetDepositTree(true)
+
+	if _, err := builder.BuildState(); err == nil || !strings.Contains(err.Error(), "has no deposit signature") {
+		t.Fatalf("expected missing deposit signature error, got %v", err)
+	}
+
+	keys, err := validators.DeriveSigningKeys(mnemonicsPath, nil)
+	if err != nil {
+		t.Fatalf("failed to derive signing keys: %v", err)
+	}
+
+	if _, err := beaconutils.SignGenesisDeposits(clConfig, vals, keys); err != nil {
+		t.Fatalf("failed to sign genesis deposits: %v", err)
+	}
+
+	deposits, err := beaconutils.GetGenesisDeposits(clConfig, vals)
+	if err != nil {
+		t.Fatalf("failed to get genesis deposits: %v", err)
+	}
+
+	depositRoot, err := beaconutils.ComputeDepositTreeRoot(clConfig, deposits)
+	if err != nil {
+		t.Fatalf("failed to compute deposit tree root: %v", err)
+	}
+
+	for _, mode := range []string{ConstructionDirect, ConstructionUpgradeChain} {
+		for _, forkConfig := range ForkConfigs {
+			t.Run(mode+"/"+forkConfig.Version.String(), func(t *testing.T) {
+				builder := forkConfig.BuilderFn(elGenesis, clConfig)
+				if mode == ConstructionUpgradeChain {
+					builder = newTestUpgradeChainBuilder(t, forkConfig.Version)
+				}
+
+				builder.AddValidators(vals)
+				builder.(DepositTree).SetDepositTree(true)
+
+				state, err := builder.BuildState()
+				if err != nil {
+					t.Fatalf("failed to build state: %v", err)
+				}
+
+				fields, err := getStateFields(state)
+				if err != nil {
+					t.Fatalf("failed to get state fields: %v", err)
+				}
+
+				eth1Data := &phase0.ETH1Data{}
+				if err := json.Unmarshal(fields["eth1_data"], eth1Data); err != nil {
+					t.Fatalf("failed to decode eth1 data: %v", err)
+				}
+
+				if eth1Data.DepositRoot != depositRoot {
+					t.Fatalf("expected deposit root 0x%x, got 0x%x", depositRoot, eth1Data.DepositRoot)
+				}
+
+				if eth1Data.DepositCount != uint64(len(vals)) {
+					t.Fatalf("expected deposit count %d, got %d", len(vals), eth1Data.DepositCount)
+				}
+
+				if string(fields["eth1_deposit_index"]) != fmt.Sprintf("\"%d\"", len(vals)) {
+					t.Fatalf("expected eth1 deposit index %d, got %s", len(vals), fields["eth1_deposit_index"])
+				}
+			})
+		}
+	}
+}
+
+func TestGenesisBuilder_GenesisRegistry(t *testing.T) {
+	clConfig := createTestGenesisConfig(t)
+	vals := createTestGenesisValidators(t)
+
+	builders := map[string]BeaconGenesisBuilder{
+		ConstructionDirect:       NewGloasBuilder(createTestELGenesis(), clConfig),
+		ConstructionUpgradeChain: newTestUpgradeChainBuilder(t, spec.DataVersionGloas),
+	}
+
+	for mode, builder := range builders {
+		t.Run(mode, func(t *testing.T) {
+			builder.AddValidators(vals)
+
+			// the two validators with builder credentials of the fixture are
+			// moved to the builder registry
+			genesisVals, genesisBuilders := builder.(BuilderRegistry).GetGenesisRegistry()
+			if len(genesisVals) != len(vals)-2 || len(genesisBuilders) != 2 {
+				t.Fatalf("expected %d validators and 2 builders, got %d and %d", len(vals)-2, len(genesisVals), len(genesisBuilders))
+			}
+
+			state, err := builder.BuildState()
+			if err != nil {
+				t.Fatalf("failed to build state: %v", err)
+			}
+
+			if len(state.Gloas.Validators) != len(genesisVals) || len(state.Gloas.Builders) != len(genesisBuilders) {
+				t.Fatalf("expected the genesis registry in the state, got %d validators and %d builders", len(state.Gloas.Validators), len(state.Gloas.Builders))
+			}
+		})
+	}
+}
+
+func TestGenesisBuilder_PreMerge(t *testing.T) {
+	clConfig := createTestGenesisConfigWith(t, `
+TERMINAL_TOTAL_DIFFICULTY: 58750000000000000000000
+`)
+	vals := createTestGenesisValidators(t)
+
+	ttd, _ := new(big.Int).SetString("58750000000000000000000", 10)
+	chainConfig := *params.AllDevChainProtocolChanges
+	chainConfig.TerminalTotalDifficulty = ttd
+
+	elGenesis := createTestELGenesis()
+	elGenesis.Config = &chainConfig
+	elGenesis.Difficulty = big.NewInt(1)
+
+	builders := map[string]BeaconGenesisBuilder{
+		ConstructionDirect:       NewBellatrixBuilder(elGenesis, clConfig),
+		ConstructionUpgradeChain: &upgradeChainBuilder{genesisBuilder: newGenesisBuilder(elGenesis, clConfig, bellatrixGenesisFork)},
+	}
+
+	for mode, builder := range builders {
+		t.Run(mode, func(t *testing.T) {
+			builder.AddValidators(vals)
+			builder.(PreMerge).SetPreMerge(true)
+
+			state, err := builder.BuildState()
+			if err != nil {
+				t.Fatalf("failed to build state: %v", err)
+			}
+
+			if header := state.Bellatrix.LatestExecutionPayloadHeader; header.BlockHash != (phase0.Hash32{}) || header.Timestamp != 0 {
+				t.Fatalf("expected empty execution payload header, got block hash %s", header.BlockHash.String())
+			}
+		})
+	}
+}
+
+func TestGenesisBuilder_PreMergeErrors(t *testing.T) {
+	vals := createTestGenesisValidators(t)
+
+	chainConfig := *params.AllDevChainProtocolChanges
+	chainConfig.TerminalTotalDifficulty = big.NewInt(100)
+
+	tests := []struct {
+		name       string
+		config     string
+		difficulty int64
+		builderFn  NewBeaconGenesisBuilderFn
+		want       string
+	}{
+		{name: "capella", config: "TERMINAL_TOTAL_DIFFICULTY: 100", difficulty: 1, builderFn: NewCapellaBuilder, want: "requires bellatrix"},
+		{name: "no ttd", config: "", difficulty: 1, builderFn: NewBellatrixBuilder, want: "TERMINAL_TOTAL_DIFFICULTY is not set"},
+		{name: "ttd mismatch", config: "TERMINAL_TOTAL_DIFFICULTY: 200", difficulty: 1, builderFn: NewBellatrixBuilder, want: "does not match TERMINAL_TOTAL_DIFFICULTY"},
+		{name: "genesis above ttd", config: "TERMINAL_TOTAL_DIFFICULTY: 100", difficulty: 100, builderFn: NewBellatrixBuilder, want: "the chain can not merge"},
+		{
+			name:       "terminal block hash without epoch",
+			config:     "TERMINAL_TOTAL_DIFFICULTY: 100\nTERMINAL_BLOCK_HASH: 0x0101010101010101010101010101010101010101010101010101010101010101",
+			difficulty: 1,
+			builderFn:  NewBellatrixBuilder,
+			want:       "TERMINAL_BLOCK_HASH_ACTIVATION_EPOCH is not",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			elGenesis := createTestELGenesis()
+			elGenesis.Config = &chainConfig
+			elGenesis.Difficulty = big.NewInt(test.difficulty)
+
+			builder := test.builderFn(elGenesis, createTestGenesisConfigWith(t, "\n"+test.config+"\n"))
+			builder.AddValidators(vals)
+			builder.(PreMerge).SetPreMerge(true)
+
+			if _, err := builder.BuildState(); err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("expected error containing %q, got %v", test.want, err)
+			}
+		})
+	}
+}
@@ -4,10 +4,7 @@ import (
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethpandaops/go-eth2-client/http"
 	"github.com/ethpandaops/go-eth2-client/spec"
-	"github.com/ethpandaops/go-eth2-client/spec/altair"
 	"github.com/ethpandaops/go-eth2-client/spec/bellatrix"
 	"github.com/ethpandaops/go-eth2-client/spec/capella"
 	"github.com/ethpandaops/go-eth2-client/spec/gloas"
@@ -17,107 +14,107 @@ import (
 	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
 	"github.com/ethpandaops/eth-beacon-genesis/beaconutils"
 	"github.com/ethpandaops/eth-beacon-genesis/validators"
-	dynssz "github.com/pk910/dynamic-ssz"
 )
 
 type gloasBuilder struct {
-	elGenesis       *core.Genesis
-	clConfig        *beaconconfig.Config
-	dynSsz          *dynssz.DynSsz
-	shadowForkBlock *types.Block
-	validators      []*validators.Validator
+	*genesisBuilder
+	builders []*validators.Builder
+}
+
+// the gloas state is built by gloasBuilder.BuildState, as the builder
+// registry is split off the validators before the shared construction
+var gloasGenesisFork = &genesisFork{
+	version:   spec.DataVersionGloas,
+	blockBody: gloasBlockBody,
 }
 
 func NewGloasBuilder(elGenesis *core.Genesis, clConfig *beaconconfig.Config) BeaconGenesisBuilder {
 	return &gloasBuilder{
-		elGenesis: elGenesis,
-		clConfig:  clConfig,
-		dynSsz:    beaconutils.GetDynSSZ(clConfig),
+		genesisBuilder: newGenesisBuilder(elGenesis, clConfig, gloasGenesisFork),
 	}
 }
 
-func (b *gloasBuilder) SetShadowForkBlock(block *types.Block) {
-	b.shadowForkBlock = block
+func (b *gloasBuilder) AddBuilders(builders []*validators.Builder) {
+	b.builders = append(b.builders, builders...)
 }
 
-func (b *gloasBuilder) AddValidators(val []*validators.Validator) {
-	b.validators = append(b.validators, val...)
+func (b *gloasBuilder) GetGenesisRegistry() ([]*validators.Validator, []*validators.Builder) {
+	return getGenesisRegistry(b.validators, b.builders)
 }
 
 func (b *gloasBuilder) BuildState() (*spec.VersionedBeaconState, error) {
-	genesisBlock := b.shadowForkBlock
-	if genesisBlock == nil {
-		genesisBlock = b.elGenesis.ToBlock()
-	}
+	genesisVals, genesisBuilders := b.GetGenesisRegistry()
 
-	genesisBlockHash := genesisBlock.Hash()
+	return b.buildState(genesisVals, func(g *genesisData, base *phase0.BeaconState) (*spec.VersionedBeaconState, error) {
+		return buildGloasState(g, base, genesisBuilders)
+	})
+}
 
-	extra := genesisBlock.Extra()
-	if len(extra) > 32 {
-		return nil, fmt.Errorf("extra data is %d bytes, max is %d", len(extra), 32)
-	}
+// getGenesisRegistry splits vals into the validators and builders of a gloas
+// genesis state. Validators with builder credentials are added to the builder
+// registry after the explicitly defined builders.
+func getGenesisRegistry(vals []*validators.Validator, builders []*validators.Builder) ([]*validators.Validator, []*validators.Builder) {
+	validatorBuilders, genesisVals := validators.SeparateBuilderValidators(vals)
+	genesisBuilders := make([]*validators.Builder, 0, len(builders)+len(validatorBuilders))
+	genesisBuilders = append(genesisBuilders, builders...)
+	genesisBuilders = append(genesisBuilders, validatorBuilders...)
 
-	depositRoot, err := beaconutils.ComputeDepositRoot(b.clConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute deposit root: %w", err)
-	}
+	return genesisVals, genesisBuilders
+}
 
-	syncCommitteeSize := b.clConfig.GetUintDefault("SYNC_COMMITTEE_SIZE", 512)
-	syncCommitteeMaskBytes := syncCommitteeSize / 8
+// emptyExecutionRequests returns the execution requests of the genesis block
+// and their root, which is referenced by the genesis payload bid.
+func emptyExecutionRequests(g *genesisData) (*gloas.ExecutionRequests, phase0.Root, error) {
+	executionRequests := &gloas.ExecutionRequests{}
 
-	if syncCommitteeSize%8 != 0 {
-		syncCommitteeMaskBytes++
+	executionRequestsRoot, err := g.dynSsz.HashTreeRoot(executionRequests)
+	if err != nil {
+		return nil, phase0.Root{}, fmt.Errorf("failed to compute empty execution requests root: %w", err)
 	}
 
-	emptyExecutionRequests := &gloas.ExecutionRequests{}
+	return executionRequests, executionRequestsRoot, nil
+}
 
-	executionRequestsRoot, err := b.dynSsz.HashTreeRoot(emptyExecutionRequests)
+func gloasBlockBody(g *genesisData) (any, error) {
+	executionRequests, executionRequestsRoot, err := emptyExecutionRequests(g)
 	if err != nil {
-		return nil, fmt.Errorf("failed to compute empty execution requests root: %w", err)
+		return nil, err
 	}
 
-	genesisBlockBody := &gloas.BeaconBlockBody{
-		ETH1Data: &phase0.ETH1Data{
-			BlockHash: make([]byte, 32),
-		},
-		SyncAggregate: &altair.SyncAggregate{
-			SyncCommitteeBits: make([]byte, syncCommitteeMaskBytes),
-		},
+	return &gloas.BeaconBlockBody{
+		ETH1Data:      g.emptyETH1Data(),
+		SyncAggregate: g.emptySyncAggregate(),
 		SignedExecutionPayloadBid: &gloas.SignedExecutionPayloadBid{
 			Message: &gloas.ExecutionPayloadBid{
-				ParentBlockHash:       phase0.Hash32(genesisBlockHash),
+				ParentBlockHash:       g.blockHash,
 				ExecutionRequestsRoot: executionRequestsRoot,
 			},
 			Signature: phase0.BLSSignature(make([]byte, 96)),
 		},
-		ParentExecutionRequests: emptyExecutionRequests,
-	}
+		ParentExecutionRequests: executionRequests,
+	}, nil
+}
 
-	genesisBlockBodyRoot, err := b.dynSsz.HashTreeRoot(genesisBlockBody)
+func buildGloasState(g *genesisData, base *phase0.BeaconState, builders []*validators.Builder) (*spec.VersionedBeaconState, error) {
+	_, executionRequestsRoot, err := emptyExecutionRequests(g)
 	if err != nil {
-		return nil, fmt.Errorf("failed to compute genesis block body root: %w", err)
+		return nil, err
 	}
 
-	genesisBuilders, genesisVals := beaconutils.SeparateBuildersFromValidators(b.validators)
-	clValidators, validatorsRoot := beaconutils.GetGenesisValidators(b.clConfig, genesisVals)
-	clBuilders := beaconutils.GetGenesisBuilders(b.clConfig, genesisBuilders)
-
-	syncCommittee, err := beaconutils.GetGenesisSyncCommittee(b.clConfig, clValidators, phase0.Hash32(genesisBlockHash))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get genesis sync committee: %w", err)
-	}
+	clBuilders := beaconutils.GetGenesisBuilders(g.clConfig, builders)
 
-	proposers, err := beaconutils.GetGenesisProposers(b.clConfig, clValidators, phase0.Hash32(genesisBlockHash))
+	proposers, err := g.proposerLookahead()
 	if err != nil {
-		return nil, fmt.Errorf("failed to calculate proposer lookahead: %w", err)
+		return nil, err
 	}
 
-	ptcWindow, err := beaconutils.GetGenesisPTCWindow(b.clConfig, clValidators, phase0.Hash32(genesisBlockHash))
+	ptcWindow, err := beaconutils.GetGenesisPTCWindow(g.clConfig, g.clValidators, g.blockHash)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate PTC window: %w", err)
 	}
 
-	slotsPerEpoch := b.clConfig.GetUintDefault("SLOTS_PER_EPOCH", 32)
+	slotsPerEpoch := g.clConfig.GetUintDefault("SLOTS_PER_EPOCH", 32)
+	blocksPerHistoricalRoot := g.clConfig.GetUintDefault("SLOTS_PER_HISTORICAL_ROOT", 8192)
 
 	emptyBuilderPendingPayments := make([]*gloas.BuilderPendingPayment, slotsPerEpoch*2)
 	for i := range slotsPerEpoch * 2 {
@@ -131,77 +128,53 @@ func (b *gloasBuilder) BuildState() (*spec.VersionedBeaconState, error) {
 		}
 	}
 
-	genesisDelay := b.clConfig.GetUintDefault("GENESIS_DELAY", 604800)
-	blocksPerHistoricalRoot := b.clConfig.GetUintDefault("SLOTS_PER_HISTORICAL_ROOT", 8192)
-	epochsPerSlashingVector := b.clConfig.GetUintDefault("EPOCHS_PER_SLASHINGS_VECTOR", 8192)
-
-	minGenesisTime := b.clConfig.GetUintDefault("MIN_GENESIS_TIME", 0)
-	if minGenesisTime == 0 {
-		minGenesisTime = genesisBlock.Time()
-	}
+	churn := getElectraChurnFields(g.clConfig, base.Validators, 0)
 
 	genesisState := &gloas.BeaconState{
-		GenesisTime:           minGenesisTime + genesisDelay,
-		GenesisValidatorsRoot: validatorsRoot,
-		Fork:                  GetStateForkConfig(spec.DataVersionGloas, b.clConfig),
-		LatestBlockHeader: &phase0.BeaconBlockHeader{
-			BodyRoot: genesisBlockBodyRoot,
-		},
-		BlockRoots: make([]phase0.Root, blocksPerHistoricalRoot),
-		StateRoots: make([]phase0.Root, blocksPerHistoricalRoot),
-		ETH1Data: &phase0.ETH1Data{
-			DepositRoot: depositRoot,
-			BlockHash:   genesisBlockHash[:],
-		},
-		JustificationBits:           make([]byte, 1),
-		PreviousJustifiedCheckpoint: &phase0.Checkpoint{},
-		CurrentJustifiedCheckpoint:  &phase0.Checkpoint{},
-		FinalizedCheckpoint:         &phase0.Checkpoint{},
-		RANDAOMixes:                 beaconutils.SeedRandomMixes(phase0.Hash32(genesisBlockHash), b.clConfig),
-		Validators:                  clValidators,
-		Balances:                    beaconutils.GetGenesisBalances(b.clConfig, genesisVals),
-		Slashings:                   make([]phase0.Gwei, epochsPerSlashingVector),
-		PreviousEpochParticipation:  make([]altair.ParticipationFlags, len(clValidators)),
-		CurrentEpochParticipation:   make([]altair.ParticipationFlags, len(clValidators)),
-		InactivityScores:            make([]uint64, len(clValidators)),
-		CurrentSyncCommittee:        syncCommittee,
-		NextSyncCommittee:           syncCommittee,
-		ProposerLookahead:           proposers,
-		Builders:                    clBuilders,
+		GenesisTime:                   base.GenesisTime,
+		GenesisValidatorsRoot:         base.GenesisValidatorsRoot,
+		Fork:                          base.Fork,
+		LatestBlockHeader:             base.LatestBlockHeader,
+		BlockRoots:                    base.BlockRoots,
+		StateRoots:                    base.StateRoots,
+		ETH1Data:                      base.ETH1Data,
+		ETH1DepositIndex:              base.ETH1DepositIndex,
+		JustificationBits:             base.JustificationBits,
+		PreviousJustifiedCheckpoint:   base.PreviousJustifiedCheckpoint,
+		CurrentJustifiedCheckpoint:    base.CurrentJustifiedCheckpoint,
+		FinalizedCheckpoint:           base.FinalizedCheckpoint,
+		RANDAOMixes:                   base.RANDAOMixes,
+		Validators:                    base.Validators,
+		Balances:                      base.Balances,
+		Slashings:                     base.Slashings,
+		PreviousEpochParticipation:    g.participationFlags(),
+		CurrentEpochParticipation:     g.participationFlags(),
+		InactivityScores:              g.inactivityScores(),
+		CurrentSyncCommittee:          g.syncCommittee,
+		NextSyncCommittee:             g.syncCommittee,
+		DepositRequestsStartIndex:     churn.depositRequestsStartIndex,
+		DepositBalanceToConsume:       churn.depositBalanceToConsume,
+		ExitBalanceToConsume:          churn.exitBalanceToConsume,
+		EarliestExitEpoch:             churn.earliestExitEpoch,
+		ConsolidationBalanceToConsume: churn.consolidationBalanceToConsume,
+		EarliestConsolidationEpoch:    churn.earliestConsolidationEpoch,
+		ProposerLookahead:             proposers,
+		Builders:                      clBuilders,
 		LatestExecutionPayloadBid: &gloas.ExecutionPayloadBid{
-			ParentBlockHash:       phase0.Hash32(genesisBlockHash),
+			ParentBlockHash:       g.blockHash,
 			ExecutionRequestsRoot: executionRequestsRoot,
 		},
 		ExecutionPayloadAvailability: beaconutils.MakeAllOnesBitvector(blocksPerHistoricalRoot),
 		BuilderPendingPayments:       emptyBuilderPendingPayments,
 		PayloadExpectedWithdrawals:   []*capella.Withdrawal{},
-		LatestBlockHash:              phase0.Hash32(genesisBlockHash),
+		LatestBlockHash:              g.blockHash,
 		PTCWindow:                    ptcWindow,
 	}
 
-	versionedState := &spec.VersionedBeaconState{
+	logrus.Infof("genesis validators: %d, builders: %d", len(g.clValidators), len(clBuilders))
+
+	return &spec.VersionedBeaconState{
 		Version: spec.DataVersionGloas,
 		Gloas:   genesisState,
-	}
-
-	logrus.Infof("genesis version: gloas")
-	logrus.Infof("genesis time: %v", genesisState.GenesisTime)
-	logrus.Infof("genesis validators root: 0x%x", genesisState.GenesisValidatorsRoot)
-
-	return versionedState, nil
-}
-
-func (b *gloasBuilder) Serialize(state *spec.VersionedBeaconState, contentType http.ContentType) ([]byte, error) {
-	if state.Version != spec.DataVersionGloas {
-		return nil, fmt.Errorf("unsupported version: %s", state.Version)
-	}
-
-	switch contentType {
-	case http.ContentTypeSSZ:
-		return b.dynSsz.MarshalSSZ(state.Gloas)
-	case http.ContentTypeJSON:
-		return state.Gloas.MarshalJSON()
-	default:
-		return nil, fmt.Errorf("unsupported content type: %s", contentType)
-	}
+	}, nil
 }
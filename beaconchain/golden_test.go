@@ -0,0 +1,99 @@
+package beaconchain
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethpandaops/go-eth2-client/http"
+
+	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
+	"github.com/ethpandaops/eth-beacon-genesis/eth1"
+	"github.com/ethpandaops/eth-beacon-genesis/validators"
+)
+
+const goldenDir = "testdata/golden"
+
+// updateGolden rewrites the golden states with the states of the current
+// builders. Only meant for intentional state changes, the changed fields are
+// logged so they can be listed in the commit.
+var updateGolden = flag.Bool("update-golden", false, "update the golden genesis states")
+
+// TestGenesisBuilder_Golden checks the genesis states of all forks against
+// the golden states generated once with the per-fork builders of the
+// baseline (make golden-states), see testdata/golden/generate.go.
+func TestGenesisBuilder_Golden(t *testing.T) {
+	clConfig, err := beaconconfig.LoadConfig(filepath.Join(goldenDir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	elGenesis, err := eth1.LoadEth1GenesisConfig(filepath.Join(goldenDir, "genesis.json"))
+	if err != nil {
+		t.Fatalf("failed to load execution genesis: %v", err)
+	}
+
+	vals, err := validators.GenerateValidatorsByMnemonic(filepath.Join(goldenDir, "mnemonics.yaml"))
+	if err != nil {
+		t.Fatalf("failed to generate validators: %v", err)
+	}
+
+	for _, forkConfig := range ForkConfigs {
+		t.Run(forkConfig.Version.String(), func(t *testing.T) {
+			goldenPath := filepath.Join(goldenDir, forkConfig.Version.String()+".ssz")
+
+			goldenData, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("failed to read golden state, generate the baseline goldens with make golden-states: %v", err)
+			}
+
+			golden, err := DecodeStateSSZ(goldenData, clConfig)
+			if err != nil {
+				t.Fatalf("failed to decode golden state: %v", err)
+			}
+
+			builder := forkConfig.BuilderFn(elGenesis, clConfig)
+			builder.AddValidators(vals)
+
+			state, err := builder.BuildState()
+			if err != nil {
+				t.Fatalf("failed to build state: %v", err)
+			}
+
+			diff, err := DiffStates(golden, state)
+			if err != nil {
+				t.Fatalf("failed to compare states: %v", err)
+			}
+
+			data, err := builder.Serialize(state, http.ContentTypeSSZ)
+			if err != nil {
+				t.Fatalf("failed to serialize state: %v", err)
+			}
+
+			if *updateGolden {
+				for _, field := range diff {
+					t.Logf("updating field %s of the golden state", field)
+				}
+
+				if err := os.WriteFile(goldenPath, data, 0o644); err != nil { //nolint:gosec // golden file
+					t.Fatalf("failed to write golden state: %v", err)
+				}
+
+				return
+			}
+
+			for _, field := range diff {
+				t.Errorf("field %s differs from the golden state", field)
+			}
+
+			if len(diff) > 0 {
+				return
+			}
+
+			if string(data) != string(goldenData) {
+				t.Fatalf("state differs from the golden state (%d vs %d bytes)", len(data), len(goldenData))
+			}
+		})
+	}
+}
@@ -1,131 +1,32 @@
 package beaconchain
 
 import (
-	"fmt"
-
 	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethpandaops/go-eth2-client/http"
 	"github.com/ethpandaops/go-eth2-client/spec"
 	"github.com/ethpandaops/go-eth2-client/spec/phase0"
-	"github.com/sirupsen/logrus"
 
 	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
-	"github.com/ethpandaops/eth-beacon-genesis/beaconutils"
-	"github.com/ethpandaops/eth-beacon-genesis/validators"
-	dynssz "github.com/pk910/dynamic-ssz"
 )
 
-type phase0Builder struct {
-	elGenesis       *core.Genesis
-	clConfig        *beaconconfig.Config
-	dynSsz          *dynssz.DynSsz
-	shadowForkBlock *types.Block
-	validators      []*validators.Validator
+var phase0GenesisFork = &genesisFork{
+	version:    spec.DataVersionPhase0,
+	blockBody:  phase0BlockBody,
+	buildState: buildPhase0State,
 }
 
 func NewPhase0Builder(elGenesis *core.Genesis, clConfig *beaconconfig.Config) BeaconGenesisBuilder {
-	return &phase0Builder{
-		elGenesis: elGenesis,
-		clConfig:  clConfig,
-		dynSsz:    beaconutils.GetDynSSZ(clConfig),
-	}
-}
-
-func (b *phase0Builder) SetShadowForkBlock(block *types.Block) {
-	b.shadowForkBlock = block
+	return newGenesisBuilder(elGenesis, clConfig, phase0GenesisFork)
 }
 
-func (b *phase0Builder) AddValidators(val []*validators.Validator) {
-	b.validators = append(b.validators, val...)
+func phase0BlockBody(g *genesisData) (any, error) {
+	return &phase0.BeaconBlockBody{
+		ETH1Data: g.emptyETH1Data(),
+	}, nil
 }
 
-func (b *phase0Builder) BuildState() (*spec.VersionedBeaconState, error) {
-	genesisBlock := b.shadowForkBlock
-	if genesisBlock == nil {
-		genesisBlock = b.elGenesis.ToBlock()
-	}
-
-	genesisBlockHash := genesisBlock.Hash()
-
-	extra := genesisBlock.Extra()
-	if len(extra) > 32 {
-		return nil, fmt.Errorf("extra data is %d bytes, max is %d", len(extra), 32)
-	}
-
-	depositRoot, err := beaconutils.ComputeDepositRoot(b.clConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute deposit root: %w", err)
-	}
-
-	genesisBlockBody := &phase0.BeaconBlockBody{
-		ETH1Data: &phase0.ETH1Data{
-			BlockHash: make([]byte, 32),
-		},
-	}
-
-	genesisBlockBodyRoot, err := b.dynSsz.HashTreeRoot(genesisBlockBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compute genesis block body root: %w", err)
-	}
-
-	clValidators, validatorsRoot := beaconutils.GetGenesisValidators(b.clConfig, b.validators)
-
-	genesisDelay := b.clConfig.GetUintDefault("GENESIS_DELAY", 604800)
-	blocksPerHistoricalRoot := b.clConfig.GetUintDefault("SLOTS_PER_HISTORICAL_ROOT", 8192)
-	epochsPerSlashingVector := b.clConfig.GetUintDefault("EPOCHS_PER_SLASHINGS_VECTOR", 8192)
-
-	minGenesisTime := b.clConfig.GetUintDefault("MIN_GENESIS_TIME", 0)
-	if minGenesisTime == 0 {
-		minGenesisTime = genesisBlock.Time()
-	}
-
-	genesisState := &phase0.BeaconState{
-		GenesisTime:           minGenesisTime + genesisDelay,
-		GenesisValidatorsRoot: validatorsRoot,
-		Fork:                  GetStateForkConfig(spec.DataVersionPhase0, b.clConfig),
-		LatestBlockHeader: &phase0.BeaconBlockHeader{
-			BodyRoot: genesisBlockBodyRoot,
-		},
-		BlockRoots: make([]phase0.Root, blocksPerHistoricalRoot),
-		StateRoots: make([]phase0.Root, blocksPerHistoricalRoot),
-		ETH1Data: &phase0.ETH1Data{
-			DepositRoot: depositRoot,
-			BlockHash:   genesisBlockHash[:],
-		},
-		JustificationBits:           make([]byte, 1),
-		PreviousJustifiedCheckpoint: &phase0.Checkpoint{},
-		CurrentJustifiedCheckpoint:  &phase0.Checkpoint{},
-		FinalizedCheckpoint:         &phase0.Checkpoint{},
-		RANDAOMixes:                 beaconutils.SeedRandomMixes(phase0.Hash32(genesisBlockHash), b.clConfig),
-		Validators:                  clValidators,
-		Balances:                    beaconutils.GetGenesisBalances(b.clConfig, b.validators),
-		Slashings:                   make([]phase0.Gwei, epochsPerSlashingVector),
-	}
-
-	versionedState := &spec.VersionedBeaconState{
+func buildPhase0State(_ *genesisData, base *phase0.BeaconState) (*spec.VersionedBeaconState, error) {
+	return &spec.VersionedBeaconState{
 		Version: spec.DataVersionPhase0,
-		Phase0:  genesisState,
-	}
-
-	logrus.Infof("genesis version: phase0")
-	logrus.Infof("genesis time: %v", genesisState.GenesisTime)
-	logrus.Infof("genesis validators root: 0x%x", genesisState.GenesisValidatorsRoot)
-
-	return versionedState, nil
-}
-
-func (b *phase0Builder) Serialize(state *spec.VersionedBeaconState, contentType http.ContentType) ([]byte, error) {
-	if state.Version != spec.DataVersionPhase0 {
-		return nil, fmt.Errorf("unsupported version: %s", state.Version)
-	}
-
-	switch contentType {
-	case http.ContentTypeSSZ:
-		return b.dynSsz.MarshalSSZ(state.Phase0)
-	case http.ContentTypeJSON:
-		return state.Phase0.MarshalJSON()
-	default:
-		return nil, fmt.Errorf("unsupported content type: %s", contentType)
-	}
+		Phase0:  base,
+	}, nil
 }
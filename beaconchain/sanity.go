@@ -0,0 +1,56 @@
+package beaconchain
+
+import (
+	"fmt"
+
+	"github.com/ethpandaops/go-eth2-client/spec"
+
+	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
+	"github.com/ethpandaops/eth-beacon-genesis/beaconutils"
+)
+
+// CheckGenesisState runs the genesis sanity checks (see
+// beaconutils.CheckGenesisSanity) on a genesis state of any supported fork.
+func CheckGenesisState(state *spec.VersionedBeaconState, clConfig *beaconconfig.Config) (*beaconutils.SanityReport, error) {
+	input := &beaconutils.GenesisSanityInput{
+		Version: state.Version,
+	}
+
+	switch state.Version {
+	case spec.DataVersionPhase0:
+		input.GenesisTime = state.Phase0.GenesisTime
+	case spec.DataVersionAltair:
+		input.GenesisTime = state.Altair.GenesisTime
+		input.SyncCommittee = state.Altair.CurrentSyncCommittee
+	case spec.DataVersionBellatrix:
+		input.GenesisTime = state.Bellatrix.GenesisTime
+		input.SyncCommittee = state.Bellatrix.CurrentSyncCommittee
+	case spec.DataVersionCapella:
+		input.GenesisTime = state.Capella.GenesisTime
+		input.SyncCommittee = state.Capella.CurrentSyncCommittee
+	case spec.DataVersionDeneb:
+		input.GenesisTime = state.Deneb.GenesisTime
+		input.SyncCommittee = state.Deneb.CurrentSyncCommittee
+	case spec.DataVersionElectra:
+		input.GenesisTime = state.Electra.GenesisTime
+		input.SyncCommittee = state.Electra.CurrentSyncCommittee
+	case spec.DataVersionFulu:
+		input.GenesisTime = state.Fulu.GenesisTime
+		input.SyncCommittee = state.Fulu.CurrentSyncCommittee
+	case spec.DataVersionGloas:
+		input.GenesisTime = state.Gloas.GenesisTime
+		input.SyncCommittee = state.Gloas.CurrentSyncCommittee
+	default:
+		return nil, fmt.Errorf("unsupported version: %s", state.Version)
+	}
+
+	validators, balances, err := GetStateValidators(state)
+	if err != nil {
+		return nil, err
+	}
+
+	input.Validators = validators
+	input.Balances = balances
+
+	return beaconutils.CheckGenesisSanity(clConfig, input), nil
+}
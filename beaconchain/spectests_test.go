@@ -0,0 +1,173 @@
+package beaconchain
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethpandaops/go-eth2-client/spec"
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+	"github.com/golang/snappy"
+
+	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
+	"github.com/ethpandaops/eth-beacon-genesis/validators"
+)
+
+// specVectorsDir holds the electra fork tests of the consensus spec tests
+// used by TestElectraChurnFields_SpecTests, copied from the downloaded spec
+// tests by `make spec-vectors`.
+const specVectorsDir = "testdata/spectests/electra/fork"
+
+// specTestsConfig is the part of the minimal config used by the spec tests
+// that affects the electra fields, with the genesis settings of the builders.
+const specTestsConfig = `
+PRESET_BASE: minimal
+MIN_GENESIS_TIME: 1578009600
+GENESIS_DELAY: 300
+GENESIS_FORK_VERSION: 0x00000001
+ALTAIR_FORK_VERSION: 0x01000001
+BELLATRIX_FORK_VERSION: 0x02000001
+CAPELLA_FORK_VERSION: 0x03000001
+DENEB_FORK_VERSION: 0x04000001
+ELECTRA_FORK_VERSION: 0x05000001
+FULU_FORK_VERSION: 0x06000001
+GLOAS_FORK_VERSION: 0x07000001
+DEPOSIT_CONTRACT_ADDRESS: 0x1234567890123456789012345678901234567890
+CHURN_LIMIT_QUOTIENT: 32
+MIN_PER_EPOCH_CHURN_LIMIT_ELECTRA: 64000000000
+MAX_PER_EPOCH_ACTIVATION_EXIT_CHURN_LIMIT: 128000000000
+`
+
+// TestElectraChurnFields_SpecTests checks the electra deposit request and
+// churn fields against the electra fork tests of the consensus spec tests.
+// The pre states are upgraded with UpgradeState, and the pre states that could
+// be genesis states (epoch 0, all validators active since genesis with 32 ETH)
+// are also built from their validators with the electra, fulu and gloas
+// builders.
+func TestElectraChurnFields_SpecTests(t *testing.T) {
+	cases, err := os.ReadDir(specVectorsDir)
+	if err != nil || len(cases) == 0 {
+		t.Fatalf("spec test vectors not found, copy them with `make spec-vectors`: %v", err)
+	}
+
+	configPath := writeTestFile(t, t.TempDir(), "config.yaml", specTestsConfig)
+
+	clConfig, err := beaconconfig.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	genesisCases := 0
+
+	for _, testCase := range cases {
+		pre := loadSpecTestState(t, clConfig, filepath.Join(specVectorsDir, testCase.Name(), "pre.ssz_snappy"))
+		post := loadSpecTestState(t, clConfig, filepath.Join(specVectorsDir, testCase.Name(), "post.ssz_snappy"))
+
+		if pre.Version != spec.DataVersionDeneb || post.Version != spec.DataVersionElectra {
+			t.Fatalf("%s: expected deneb to electra fork test, got %s to %s", testCase.Name(), pre.Version, post.Version)
+		}
+
+		expected := getStateChurnFields(t, post)
+
+		t.Run(testCase.Name()+"/upgrade", func(t *testing.T) {
+			upgraded, err := UpgradeState(pre, spec.DataVersionElectra, clConfig)
+			if err != nil {
+				t.Fatalf("failed to upgrade state: %v", err)
+			}
+
+			if churn := getStateChurnFields(t, upgraded); *churn != *expected {
+				t.Fatalf("unexpected churn fields: got %+v, expected %+v", *churn, *expected)
+			}
+		})
+
+		genesisVals := getSpecTestGenesisValidators(t, clConfig, pre)
+		if genesisVals == nil {
+			continue
+		}
+
+		genesisCases++
+
+		t.Run(testCase.Name()+"/genesis", func(t *testing.T) {
+			for _, version := range []spec.DataVersion{spec.DataVersionElectra, spec.DataVersionFulu, spec.DataVersionGloas} {
+				builder := GetForkConfig(version).BuilderFn(createTestELGenesis(), clConfig)
+				builder.AddValidators(genesisVals)
+
+				state, err := builder.BuildState()
+				if err != nil {
+					t.Fatalf("failed to build %s state: %v", version, err)
+				}
+
+				if churn := getStateChurnFields(t, state); *churn != *expected {
+					t.Fatalf("unexpected %s churn fields: got %+v, expected %+v", version, *churn, *expected)
+				}
+			}
+		})
+	}
+
+	if genesisCases == 0 {
+		t.Fatalf("no spec test vector has a genesis like pre state")
+	}
+}
+
+// getSpecTestGenesisValidators returns the validators of a spec test pre state
+// if the genesis builders would produce the same registry: the state is at
+// epoch 0 and all validators are active since genesis with 32 ETH. It returns
+// nil for other states.
+func getSpecTestGenesisValidators(t *testing.T, clConfig *beaconconfig.Config, state *spec.VersionedBeaconState) []*validators.Validator {
+	t.Helper()
+
+	epoch, err := GetStateEpoch(state, clConfig)
+	if err != nil {
+		t.Fatalf("failed to get state epoch: %v", err)
+	}
+
+	stateVals, _, err := GetStateValidators(state)
+	if err != nil {
+		t.Fatalf("failed to get state validators: %v", err)
+	}
+
+	if epoch != 0 {
+		return nil
+	}
+
+	maxEffectiveBalance := phase0.Gwei(clConfig.GetUintDefault("MAX_EFFECTIVE_BALANCE", 32_000_000_000))
+	farFutureEpoch := phase0.Epoch(clConfig.GetUintDefault("FAR_FUTURE_EPOCH", 18446744073709551615))
+	vals := make([]*validators.Validator, 0, len(stateVals))
+
+	for _, stateVal := range stateVals {
+		if stateVal.ActivationEpoch != 0 || stateVal.ExitEpoch != farFutureEpoch || stateVal.Slashed || stateVal.EffectiveBalance != maxEffectiveBalance {
+			return nil
+		}
+
+		balance := uint64(stateVal.EffectiveBalance)
+		vals = append(vals, &validators.Validator{
+			PublicKey:             stateVal.PublicKey,
+			WithdrawalCredentials: stateVal.WithdrawalCredentials,
+			Balance:               &balance,
+			Status:                validators.ValidatorStatusActive,
+		})
+	}
+
+	return vals
+}
+
+func loadSpecTestState(t *testing.T, clConfig *beaconconfig.Config, path string) *spec.VersionedBeaconState {
+	t.Helper()
+
+	compressed, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read state: %v", err)
+	}
+
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		t.Fatalf("failed to decompress state: %v", err)
+	}
+
+	state, err := DecodeStateSSZ(data, clConfig)
+	if err != nil {
+		t.Fatalf("failed to decode state: %v", err)
+	}
+
+	return state
+}
@@ -0,0 +1,298 @@
+package beaconchain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethpandaops/go-eth2-client/http"
+	"github.com/ethpandaops/go-eth2-client/spec"
+	"github.com/ethpandaops/go-eth2-client/spec/altair"
+	"github.com/ethpandaops/go-eth2-client/spec/bellatrix"
+	"github.com/ethpandaops/go-eth2-client/spec/capella"
+	"github.com/ethpandaops/go-eth2-client/spec/deneb"
+	"github.com/ethpandaops/go-eth2-client/spec/electra"
+	"github.com/ethpandaops/go-eth2-client/spec/fulu"
+	"github.com/ethpandaops/go-eth2-client/spec/gloas"
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+	dynssz "github.com/pk910/dynamic-ssz"
+
+	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
+	"github.com/ethpandaops/eth-beacon-genesis/beaconutils"
+)
+
+// sszForkVersionOffset is the offset of fork.current_version in a SSZ encoded
+// beacon state (after genesis_time, genesis_validators_root, slot and
+// fork.previous_version). It is the same for all forks.
+const sszForkVersionOffset = 8 + 32 + 8 + 4
+
+// LoadStateFromFile loads a beacon state from a SSZ file, or a JSON file if the
+// file name ends with .json. The fork of the state is detected by matching its
+// fork version against the fork versions in clConfig.
+func LoadStateFromFile(path string, clConfig *beaconconfig.Config) (*spec.VersionedBeaconState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read beacon state: %w", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return DecodeStateJSON(data, clConfig)
+	}
+
+	return DecodeStateSSZ(data, clConfig)
+}
+
+// DecodeStateSSZ decodes a SSZ encoded beacon state of any supported fork.
+func DecodeStateSSZ(data []byte, clConfig *beaconconfig.Config) (*spec.VersionedBeaconState, error) {
+	if len(data) < sszForkVersionOffset+4 {
+		return nil, fmt.Errorf("beacon state too short (%d bytes)", len(data))
+	}
+
+	version, err := GetForkVersionByValue(clConfig, data[sszForkVersionOffset:sszForkVersionOffset+4])
+	if err != nil {
+		return nil, err
+	}
+
+	state, target := newVersionedState(version)
+	if err := beaconutils.GetDynSSZ(clConfig).UnmarshalSSZ(target, data); err != nil {
+		return nil, fmt.Errorf("failed to decode %s beacon state: %w", version, err)
+	}
+
+	return state, nil
+}
+
+// DecodeStateJSON decodes a JSON encoded beacon state of any supported fork.
+func DecodeStateJSON(data []byte, clConfig *beaconconfig.Config) (*spec.VersionedBeaconState, error) {
+	var header struct {
+		Fork *phase0.Fork `json:"fork"`
+	}
+
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to decode beacon state: %w", err)
+	}
+
+	if header.Fork == nil {
+		return nil, fmt.Errorf("beacon state has no fork")
+	}
+
+	version, err := GetForkVersionByValue(clConfig, header.Fork.CurrentVersion[:])
+	if err != nil {
+		return nil, err
+	}
+
+	state, target := newVersionedState(version)
+	if err := json.Unmarshal(data, target); err != nil {
+		return nil, fmt.Errorf("failed to decode %s beacon state: %w", version, err)
+	}
+
+	return state, nil
+}
+
+// GetForkVersionByValue returns the fork whose fork version in clConfig equals
+// forkVersion. If several forks share the version, the latest one is returned.
+func GetForkVersionByValue(clConfig *beaconconfig.Config, forkVersion []byte) (spec.DataVersion, error) {
+	for i := len(ForkConfigs) - 1; i >= 0; i-- {
+		if version, found := clConfig.GetBytes(ForkConfigs[i].VersionField); found && bytes.Equal(version, forkVersion) {
+			return ForkConfigs[i].Version, nil
+		}
+	}
+
+	return spec.DataVersionUnknown, fmt.Errorf("fork version 0x%x not found in consensus config", forkVersion)
+}
+
+// newVersionedState returns an empty versioned state of the given fork and a
+// pointer to the fork specific state to decode into.
+func newVersionedState(version spec.DataVersion) (state *spec.VersionedBeaconState, target any) {
+	state = &spec.VersionedBeaconState{Version: version}
+
+	switch version {
+	case spec.DataVersionPhase0:
+		state.Phase0 = &phase0.BeaconState{}
+		target = state.Phase0
+	case spec.DataVersionAltair:
+		state.Altair = &altair.BeaconState{}
+		target = state.Altair
+	case spec.DataVersionBellatrix:
+		state.Bellatrix = &bellatrix.BeaconState{}
+		target = state.Bellatrix
+	case spec.DataVersionCapella:
+		state.Capella = &capella.BeaconState{}
+		target = state.Capella
+	case spec.DataVersionDeneb:
+		state.Deneb = &deneb.BeaconState{}
+		target = state.Deneb
+	case spec.DataVersionElectra:
+		state.Electra = &electra.BeaconState{}
+		target = state.Electra
+	case spec.DataVersionFulu:
+		state.Fulu = &fulu.BeaconState{}
+		target = state.Fulu
+	case spec.DataVersionGloas:
+		state.Gloas = &gloas.BeaconState{}
+		target = state.Gloas
+	}
+
+	return state, target
+}
+
+// GetStateValidators returns the validator registry and balances of a state.
+func GetStateValidators(state *spec.VersionedBeaconState) ([]*phase0.Validator, []phase0.Gwei, error) {
+	switch state.Version {
+	case spec.DataVersionPhase0:
+		return state.Phase0.Validators, state.Phase0.Balances, nil
+	case spec.DataVersionAltair:
+		return state.Altair.Validators, state.Altair.Balances, nil
+	case spec.DataVersionBellatrix:
+		return state.Bellatrix.Validators, state.Bellatrix.Balances, nil
+	case spec.DataVersionCapella:
+		return state.Capella.Validators, state.Capella.Balances, nil
+	case spec.DataVersionDeneb:
+		return state.Deneb.Validators, state.Deneb.Balances, nil
+	case spec.DataVersionElectra:
+		return state.Electra.Validators, state.Electra.Balances, nil
+	case spec.DataVersionFulu:
+		return state.Fulu.Validators, state.Fulu.Balances, nil
+	case spec.DataVersionGloas:
+		return state.Gloas.Validators, state.Gloas.Balances, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported version: %s", state.Version)
+	}
+}
+
+// GetStateEpoch returns the current epoch of a state.
+func GetStateEpoch(state *spec.VersionedBeaconState, clConfig *beaconconfig.Config) (phase0.Epoch, error) {
+	var slot phase0.Slot
+
+	switch state.Version {
+	case spec.DataVersionPhase0:
+		slot = state.Phase0.Slot
+	case spec.DataVersionAltair:
+		slot = state.Altair.Slot
+	case spec.DataVersionBellatrix:
+		slot = state.Bellatrix.Slot
+	case spec.DataVersionCapella:
+		slot = state.Capella.Slot
+	case spec.DataVersionDeneb:
+		slot = state.Deneb.Slot
+	case spec.DataVersionElectra:
+		slot = state.Electra.Slot
+	case spec.DataVersionFulu:
+		slot = state.Fulu.Slot
+	case spec.DataVersionGloas:
+		slot = state.Gloas.Slot
+	default:
+		return 0, fmt.Errorf("unsupported version: %s", state.Version)
+	}
+
+	return getCurrentEpoch(clConfig, slot), nil
+}
+
+// getStateData returns the fork specific state of a versioned state.
+func getStateData(state *spec.VersionedBeaconState) (any, error) {
+	switch state.Version {
+	case spec.DataVersionPhase0:
+		return state.Phase0, nil
+	case spec.DataVersionAltair:
+		return state.Altair, nil
+	case spec.DataVersionBellatrix:
+		return state.Bellatrix, nil
+	case spec.DataVersionCapella:
+		return state.Capella, nil
+	case spec.DataVersionDeneb:
+		return state.Deneb, nil
+	case spec.DataVersionElectra:
+		return state.Electra, nil
+	case spec.DataVersionFulu:
+		return state.Fulu, nil
+	case spec.DataVersionGloas:
+		return state.Gloas, nil
+	default:
+		return nil, fmt.Errorf("unsupported version: %s", state.Version)
+	}
+}
+
+// SerializeState encodes a beacon state of any supported fork as SSZ or JSON.
+func SerializeState(state *spec.VersionedBeaconState, clConfig *beaconconfig.Config, contentType http.ContentType) ([]byte, error) {
+	return serializeState(beaconutils.GetDynSSZ(clConfig), state, contentType)
+}
+
+func serializeState(dynSsz *dynssz.DynSsz, state *spec.VersionedBeaconState, contentType http.ContentType) ([]byte, error) {
+	stateData, err := getStateData(state)
+	if err != nil {
+		return nil, err
+	}
+
+	switch contentType {
+	case http.ContentTypeSSZ:
+		return dynSsz.MarshalSSZ(stateData)
+	case http.ContentTypeJSON:
+		marshaler, ok := stateData.(json.Marshaler)
+		if !ok {
+			return nil, fmt.Errorf("unsupported version: %s", state.Version)
+		}
+
+		return marshaler.MarshalJSON()
+	default:
+		return nil, fmt.Errorf("unsupported content type: %s", contentType)
+	}
+}
+
+// DiffStates returns the sorted JSON names of the top level fields that
+// differ between two beacon states of the same fork.
+func DiffStates(a, b *spec.VersionedBeaconState) ([]string, error) {
+	if a.Version != b.Version {
+		return nil, fmt.Errorf("state versions differ: %s != %s", a.Version, b.Version)
+	}
+
+	aFields, err := getStateFields(a)
+	if err != nil {
+		return nil, err
+	}
+
+	bFields, err := getStateFields(b)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := []string{}
+
+	for name, aValue := range aFields {
+		if bValue, ok := bFields[name]; !ok || !bytes.Equal(aValue, bValue) {
+			diffs = append(diffs, name)
+		}
+	}
+
+	for name := range bFields {
+		if _, ok := aFields[name]; !ok {
+			diffs = append(diffs, name)
+		}
+	}
+
+	sort.Strings(diffs)
+
+	return diffs, nil
+}
+
+// getStateFields returns the JSON encoded top level fields of a beacon state.
+func getStateFields(state *spec.VersionedBeaconState) (map[string]json.RawMessage, error) {
+	stateData, err := getStateData(state)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonData, err := json.Marshal(stateData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s beacon state: %w", state.Version, err)
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(jsonData, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode %s beacon state: %w", state.Version, err)
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,83 @@
+//go:build ignore
+
+// generate writes the golden genesis states of all forks for the inputs in
+// this directory. It only uses the builder API of the original per-fork
+// builders, so the goldens are generated once from the baseline with
+// make golden-states, which runs:
+//
+//	git worktree add /tmp/baseline e2d302a
+//	cp beaconchain/testdata/golden/generate.go /tmp/baseline/gen.go
+//	cd /tmp/baseline && go run gen.go <repo>/beaconchain/testdata/golden
+//
+// The goldens must not be regenerated from the current builders, otherwise
+// TestGenesisBuilder_Golden compares the builders with themselves. Intentional
+// state changes since the baseline, like the electra churn fields, are applied
+// on top with go test ./beaconchain -run TestGenesisBuilder_Golden -update-golden
+// and committed as separate golden updates.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethpandaops/go-eth2-client/http"
+
+	"github.com/ethpandaops/eth-beacon-genesis/beaconchain"
+	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
+	"github.com/ethpandaops/eth-beacon-genesis/eth1"
+	"github.com/ethpandaops/eth-beacon-genesis/validators"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: go run gen.go <golden dir>")
+		os.Exit(2)
+	}
+
+	if err := generate(os.Args[1]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func generate(dir string) error {
+	clConfig, err := beaconconfig.LoadConfig(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	elGenesis, err := eth1.LoadEth1GenesisConfig(filepath.Join(dir, "genesis.json"))
+	if err != nil {
+		return fmt.Errorf("failed to load execution genesis: %w", err)
+	}
+
+	vals, err := validators.GenerateValidatorsByMnemonic(filepath.Join(dir, "mnemonics.yaml"))
+	if err != nil {
+		return fmt.Errorf("failed to generate validators: %w", err)
+	}
+
+	for _, forkConfig := range beaconchain.ForkConfigs {
+		builder := forkConfig.BuilderFn(elGenesis, clConfig)
+		builder.AddValidators(vals)
+
+		state, err := builder.BuildState()
+		if err != nil {
+			return fmt.Errorf("failed to build %s state: %w", forkConfig.Version, err)
+		}
+
+		data, err := builder.Serialize(state, http.ContentTypeSSZ)
+		if err != nil {
+			return fmt.Errorf("failed to serialize %s state: %w", forkConfig.Version, err)
+		}
+
+		path := filepath.Join(dir, forkConfig.Version.String()+".ssz")
+		if err := os.WriteFile(path, data, 0o644); err != nil { //nolint:gosec // golden file
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+
+		fmt.Printf("wrote %s (%d bytes)\n", path, len(data))
+	}
+
+	return nil
+}
@@ -0,0 +1,591 @@
+package beaconchain
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/ethpandaops/go-eth2-client/spec"
+	"github.com/ethpandaops/go-eth2-client/spec/altair"
+	"github.com/ethpandaops/go-eth2-client/spec/bellatrix"
+	"github.com/ethpandaops/go-eth2-client/spec/capella"
+	"github.com/ethpandaops/go-eth2-client/spec/deneb"
+	"github.com/ethpandaops/go-eth2-client/spec/electra"
+	"github.com/ethpandaops/go-eth2-client/spec/fulu"
+	"github.com/ethpandaops/go-eth2-client/spec/gloas"
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+	"github.com/holiman/uint256"
+
+	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
+	"github.com/ethpandaops/eth-beacon-genesis/beaconutils"
+)
+
+// g2PointAtInfinity is the signature of the pending deposits created by the
+// electra upgrade (bls.G2_POINT_AT_INFINITY).
+var g2PointAtInfinity = phase0.BLSSignature{0xc0}
+
+// UpgradeState applies the fork upgrades of the spec (upgrade_to_altair …
+// upgrade_to_gloas) to state until it reaches the target fork. The upgrades
+// take over the lists of the previous state, so state must not be used after
+// the upgrade.
+func UpgradeState(state *spec.VersionedBeaconState, target spec.DataVersion, clConfig *beaconconfig.Config) (*spec.VersionedBeaconState, error) {
+	if target < state.Version {
+		return nil, fmt.Errorf("cannot upgrade %s state to %s", state.Version, target)
+	}
+
+	if GetForkConfig(target) == nil {
+		return nil, fmt.Errorf("unsupported version: %s", target)
+	}
+
+	for version := state.Version + 1; version <= target; version++ {
+		forkConfig := GetForkConfig(version)
+		if _, found := clConfig.GetBytes(forkConfig.VersionField); !found {
+			return nil, fmt.Errorf("%s not found in consensus config", forkConfig.VersionField)
+		}
+	}
+
+	for state.Version < target {
+		upgraded, err := upgradeStateStep(state, clConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upgrade state to %s: %w", state.Version+1, err)
+		}
+
+		state = upgraded
+	}
+
+	return state, nil
+}
+
+func upgradeStateStep(state *spec.VersionedBeaconState, clConfig *beaconconfig.Config) (*spec.VersionedBeaconState, error) {
+	switch state.Version {
+	case spec.DataVersionPhase0:
+		post, err := upgradeToAltair(state.Phase0, clConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		return &spec.VersionedBeaconState{Version: spec.DataVersionAltair, Altair: post}, nil
+	case spec.DataVersionAltair:
+		return &spec.VersionedBeaconState{Version: spec.DataVersionBellatrix, Bellatrix: upgradeToBellatrix(state.Altair, clConfig)}, nil
+	case spec.DataVersionBellatrix:
+		return &spec.VersionedBeaconState{Version: spec.DataVersionCapella, Capella: upgradeToCapella(state.Bellatrix, clConfig)}, nil
+	case spec.DataVersionCapella:
+		return &spec.VersionedBeaconState{Version: spec.DataVersionDeneb, Deneb: upgradeToDeneb(state.Capella, clConfig)}, nil
+	case spec.DataVersionDeneb:
+		return &spec.VersionedBeaconState{Version: spec.DataVersionElectra, Electra: upgradeToElectra(state.Deneb, clConfig)}, nil
+	case spec.DataVersionElectra:
+		post, err := upgradeToFulu(state.Electra, clConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		return &spec.VersionedBeaconState{Version: spec.DataVersionFulu, Fulu: post}, nil
+	case spec.DataVersionFulu:
+		post, err := upgradeToGloas(state.Fulu, clConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		return &spec.VersionedBeaconState{Version: spec.DataVersionGloas, Gloas: post}, nil
+	default:
+		return nil, fmt.Errorf("unsupported version: %s", state.Version)
+	}
+}
+
+// getCurrentEpoch returns the epoch of slot (get_current_epoch).
+func getCurrentEpoch(clConfig *beaconconfig.Config, slot phase0.Slot) phase0.Epoch {
+	return phase0.Epoch(uint64(slot) / clConfig.GetUintDefault("SLOTS_PER_EPOCH", 32))
+}
+
+// upgradeFork returns the fork of a state upgraded to version at epoch.
+func upgradeFork(pre *phase0.Fork, version spec.DataVersion, epoch phase0.Epoch, clConfig *beaconconfig.Config) *phase0.Fork {
+	forkVersion, _ := clConfig.GetBytes(GetForkConfig(version).VersionField)
+
+	return &phase0.Fork{
+		PreviousVersion: pre.CurrentVersion,
+		CurrentVersion:  phase0.Version(forkVersion),
+		Epoch:           epoch,
+	}
+}
+
+func upgradeToAltair(pre *phase0.BeaconState, clConfig *beaconconfig.Config) (*altair.BeaconState, error) {
+	// translate_participation needs the committees of the previous epoch,
+	// which is not supported
+	if len(pre.PreviousEpochAttestations) > 0 {
+		return nil, fmt.Errorf("translating %d pending attestations is not supported", len(pre.PreviousEpochAttestations))
+	}
+
+	epoch := getCurrentEpoch(clConfig, pre.Slot)
+
+	post := &altair.BeaconState{
+		GenesisTime:                 pre.GenesisTime,
+		GenesisValidatorsRoot:       pre.GenesisValidatorsRoot,
+		Slot:                        pre.Slot,
+		Fork:                        upgradeFork(pre.Fork, spec.DataVersionAltair, epoch, clConfig),
+		LatestBlockHeader:           pre.LatestBlockHeader,
+		BlockRoots:                  pre.BlockRoots,
+		StateRoots:                  pre.StateRoots,
+		HistoricalRoots:             pre.HistoricalRoots,
+		ETH1Data:                    pre.ETH1Data,
+		ETH1DataVotes:               pre.ETH1DataVotes,
+		ETH1DepositIndex:            pre.ETH1DepositIndex,
+		Validators:                  pre.Validators,
+		Balances:                    pre.Balances,
+		RANDAOMixes:                 pre.RANDAOMixes,
+		Slashings:                   pre.Slashings,
+		PreviousEpochParticipation:  make([]altair.ParticipationFlags, len(pre.Validators)),
+		CurrentEpochParticipation:   make([]altair.ParticipationFlags, len(pre.Validators)),
+		JustificationBits:           pre.JustificationBits,
+		PreviousJustifiedCheckpoint: pre.PreviousJustifiedCheckpoint,
+		CurrentJustifiedCheckpoint:  pre.CurrentJustifiedCheckpoint,
+		FinalizedCheckpoint:         pre.FinalizedCheckpoint,
+		InactivityScores:            make([]uint64, len(pre.Validators)),
+	}
+
+	// the current and next sync committee are both the next sync committee
+	// of the upgraded state, sampled with the altair rules
+	syncCommittee, err := beaconutils.GetNextSyncCommittee(clConfig, post.Validators, post.RANDAOMixes, epoch, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next sync committee: %w", err)
+	}
+
+	post.CurrentSyncCommittee = syncCommittee
+	post.NextSyncCommittee = syncCommittee
+
+	return post, nil
+}
+
+func upgradeToBellatrix(pre *altair.BeaconState, clConfig *beaconconfig.Config) *bellatrix.BeaconState {
+	epoch := getCurrentEpoch(clConfig, pre.Slot)
+
+	return &bellatrix.BeaconState{
+		GenesisTime:                  pre.GenesisTime,
+		GenesisValidatorsRoot:        pre.GenesisValidatorsRoot,
+		Slot:                         pre.Slot,
+		Fork:                         upgradeFork(pre.Fork, spec.DataVersionBellatrix, epoch, clConfig),
+		LatestBlockHeader:            pre.LatestBlockHeader,
+		BlockRoots:                   pre.BlockRoots,
+		StateRoots:                   pre.StateRoots,
+		HistoricalRoots:              pre.HistoricalRoots,
+		ETH1Data:                     pre.ETH1Data,
+		ETH1DataVotes:                pre.ETH1DataVotes,
+		ETH1DepositIndex:             pre.ETH1DepositIndex,
+		Validators:                   pre.Validators,
+		Balances:                     pre.Balances,
+		RANDAOMixes:                  pre.RANDAOMixes,
+		Slashings:                    pre.Slashings,
+		PreviousEpochParticipation:   pre.PreviousEpochParticipation,
+		CurrentEpochParticipation:    pre.CurrentEpochParticipation,
+		JustificationBits:            pre.JustificationBits,
+		PreviousJustifiedCheckpoint:  pre.PreviousJustifiedCheckpoint,
+		CurrentJustifiedCheckpoint:   pre.CurrentJustifiedCheckpoint,
+		FinalizedCheckpoint:          pre.FinalizedCheckpoint,
+		InactivityScores:             pre.InactivityScores,
+		CurrentSyncCommittee:         pre.CurrentSyncCommittee,
+		NextSyncCommittee:            pre.NextSyncCommittee,
+		LatestExecutionPayloadHeader: &bellatrix.ExecutionPayloadHeader{},
+	}
+}
+
+func upgradeToCapella(pre *bellatrix.BeaconState, clConfig *beaconconfig.Config) *capella.BeaconState {
+	epoch := getCurrentEpoch(clConfig, pre.Slot)
+	header := pre.LatestExecutionPayloadHeader
+
+	return &capella.BeaconState{
+		GenesisTime:                 pre.GenesisTime,
+		GenesisValidatorsRoot:       pre.GenesisValidatorsRoot,
+		Slot:                        pre.Slot,
+		Fork:                        upgradeFork(pre.Fork, spec.DataVersionCapella, epoch, clConfig),
+		LatestBlockHeader:           pre.LatestBlockHeader,
+		BlockRoots:                  pre.BlockRoots,
+		StateRoots:                  pre.StateRoots,
+		HistoricalRoots:             pre.HistoricalRoots,
+		ETH1Data:                    pre.ETH1Data,
+		ETH1DataVotes:               pre.ETH1DataVotes,
+		ETH1DepositIndex:            pre.ETH1DepositIndex,
+		Validators:                  pre.Validators,
+		Balances:                    pre.Balances,
+		RANDAOMixes:                 pre.RANDAOMixes,
+		Slashings:                   pre.Slashings,
+		PreviousEpochParticipation:  pre.PreviousEpochParticipation,
+		CurrentEpochParticipation:   pre.CurrentEpochParticipation,
+		JustificationBits:           pre.JustificationBits,
+		PreviousJustifiedCheckpoint: pre.PreviousJustifiedCheckpoint,
+		CurrentJustifiedCheckpoint:  pre.CurrentJustifiedCheckpoint,
+		FinalizedCheckpoint:         pre.FinalizedCheckpoint,
+		InactivityScores:            pre.InactivityScores,
+		CurrentSyncCommittee:        pre.CurrentSyncCommittee,
+		NextSyncCommittee:           pre.NextSyncCommittee,
+		LatestExecutionPayloadHeader: &capella.ExecutionPayloadHeader{
+			ParentHash:       header.ParentHash,
+			FeeRecipient:     header.FeeRecipient,
+			StateRoot:        header.StateRoot,
+			ReceiptsRoot:     header.ReceiptsRoot,
+			LogsBloom:        header.LogsBloom,
+			PrevRandao:       header.PrevRandao,
+			BlockNumber:      header.BlockNumber,
+			GasLimit:         header.GasLimit,
+			GasUsed:          header.GasUsed,
+			Timestamp:        header.Timestamp,
+			ExtraData:        header.ExtraData,
+			BaseFeePerGasLE:  header.BaseFeePerGasLE,
+			BlockHash:        header.BlockHash,
+			TransactionsRoot: header.TransactionsRoot,
+			WithdrawalsRoot:  phase0.Root{},
+		},
+		NextWithdrawalIndex:          0,
+		NextWithdrawalValidatorIndex: 0,
+		HistoricalSummaries:          []*capella.HistoricalSummary{},
+	}
+}
+
+func upgradeToDeneb(pre *capella.BeaconState, clConfig *beaconconfig.Config) *deneb.BeaconState {
+	epoch := getCurrentEpoch(clConfig, pre.Slot)
+	header := pre.LatestExecutionPayloadHeader
+
+	// the base fee is little endian before deneb
+	baseFeeBytes := header.BaseFeePerGasLE
+	slices.Reverse(baseFeeBytes[:])
+
+	return &deneb.BeaconState{
+		GenesisTime:                 pre.GenesisTime,
+		GenesisValidatorsRoot:       pre.GenesisValidatorsRoot,
+		Slot:                        pre.Slot,
+		Fork:                        upgradeFork(pre.Fork, spec.DataVersionDeneb, epoch, clConfig),
+		LatestBlockHeader:           pre.LatestBlockHeader,
+		BlockRoots:                  pre.BlockRoots,
+		StateRoots:                  pre.StateRoots,
+		HistoricalRoots:             pre.HistoricalRoots,
+		ETH1Data:                    pre.ETH1Data,
+		ETH1DataVotes:               pre.ETH1DataVotes,
+		ETH1DepositIndex:            pre.ETH1DepositIndex,
+		Validators:                  pre.Validators,
+		Balances:                    pre.Balances,
+		RANDAOMixes:                 pre.RANDAOMixes,
+		Slashings:                   pre.Slashings,
+		PreviousEpochParticipation:  pre.PreviousEpochParticipation,
+		CurrentEpochParticipation:   pre.CurrentEpochParticipation,
+		JustificationBits:           pre.JustificationBits,
+		PreviousJustifiedCheckpoint: pre.PreviousJustifiedCheckpoint,
+		CurrentJustifiedCheckpoint:  pre.CurrentJustifiedCheckpoint,
+		FinalizedCheckpoint:         pre.FinalizedCheckpoint,
+		InactivityScores:            pre.InactivityScores,
+		CurrentSyncCommittee:        pre.CurrentSyncCommittee,
+		NextSyncCommittee:           pre.NextSyncCommittee,
+		LatestExecutionPayloadHeader: &deneb.ExecutionPayloadHeader{
+			ParentHash:       header.ParentHash,
+			FeeRecipient:     header.FeeRecipient,
+			StateRoot:        header.StateRoot,
+			ReceiptsRoot:     header.ReceiptsRoot,
+			LogsBloom:        header.LogsBloom,
+			PrevRandao:       header.PrevRandao,
+			BlockNumber:      header.BlockNumber,
+			GasLimit:         header.GasLimit,
+			GasUsed:          header.GasUsed,
+			Timestamp:        header.Timestamp,
+			ExtraData:        header.ExtraData,
+			BaseFeePerGas:    new(uint256.Int).SetBytes(baseFeeBytes[:]),
+			BlockHash:        header.BlockHash,
+			TransactionsRoot: header.TransactionsRoot,
+			WithdrawalsRoot:  header.WithdrawalsRoot,
+			BlobGasUsed:      0,
+			ExcessBlobGas:    0,
+		},
+		NextWithdrawalIndex:          pre.NextWithdrawalIndex,
+		NextWithdrawalValidatorIndex: pre.NextWithdrawalValidatorIndex,
+		HistoricalSummaries:          pre.HistoricalSummaries,
+	}
+}
+
+func upgradeToElectra(pre *deneb.BeaconState, clConfig *beaconconfig.Config) *electra.BeaconState {
+	epoch := getCurrentEpoch(clConfig, pre.Slot)
+	farFutureEpoch := phase0.Epoch(clConfig.GetUintDefault("FAR_FUTURE_EPOCH", 18446744073709551615))
+
+	// the upgrade changes validators and balances, copy them to keep the
+	// previous state intact
+	validators := make([]*phase0.Validator, len(pre.Validators))
+	for i, validator := range pre.Validators {
+		validatorCopy := *validator
+		validators[i] = &validatorCopy
+	}
+
+	balances := slices.Clone(pre.Balances)
+
+	// the churn is computed before the pending validators are moved to the
+	// pending deposits, which doesn't change the active balance
+	churn := getElectraChurnFields(clConfig, validators, epoch)
+
+	post := &electra.BeaconState{
+		GenesisTime:                   pre.GenesisTime,
+		GenesisValidatorsRoot:         pre.GenesisValidatorsRoot,
+		Slot:                          pre.Slot,
+		Fork:                          upgradeFork(pre.Fork, spec.DataVersionElectra, epoch, clConfig),
+		LatestBlockHeader:             pre.LatestBlockHeader,
+		BlockRoots:                    pre.BlockRoots,
+		StateRoots:                    pre.StateRoots,
+		HistoricalRoots:               pre.HistoricalRoots,
+		ETH1Data:                      pre.ETH1Data,
+		ETH1DataVotes:                 pre.ETH1DataVotes,
+		ETH1DepositIndex:              pre.ETH1DepositIndex,
+		Validators:                    validators,
+		Balances:                      balances,
+		RANDAOMixes:                   pre.RANDAOMixes,
+		Slashings:                     pre.Slashings,
+		PreviousEpochParticipation:    pre.PreviousEpochParticipation,
+		CurrentEpochParticipation:     pre.CurrentEpochParticipation,
+		JustificationBits:             pre.JustificationBits,
+		PreviousJustifiedCheckpoint:   pre.PreviousJustifiedCheckpoint,
+		CurrentJustifiedCheckpoint:    pre.CurrentJustifiedCheckpoint,
+		FinalizedCheckpoint:           pre.FinalizedCheckpoint,
+		InactivityScores:              pre.InactivityScores,
+		CurrentSyncCommittee:          pre.CurrentSyncCommittee,
+		NextSyncCommittee:             pre.NextSyncCommittee,
+		LatestExecutionPayloadHeader:  pre.LatestExecutionPayloadHeader,
+		NextWithdrawalIndex:           pre.NextWithdrawalIndex,
+		NextWithdrawalValidatorIndex:  pre.NextWithdrawalValidatorIndex,
+		HistoricalSummaries:           pre.HistoricalSummaries,
+		DepositRequestsStartIndex:     churn.depositRequestsStartIndex,
+		DepositBalanceToConsume:       churn.depositBalanceToConsume,
+		ExitBalanceToConsume:          churn.exitBalanceToConsume,
+		EarliestExitEpoch:             churn.earliestExitEpoch,
+		ConsolidationBalanceToConsume: churn.consolidationBalanceToConsume,
+		EarliestConsolidationEpoch:    churn.earliestConsolidationEpoch,
+		PendingDeposits:               []*electra.PendingDeposit{},
+		PendingPartialWithdrawals:     []*electra.PendingPartialWithdrawal{},
+		PendingConsolidations:         []*electra.PendingConsolidation{},
+	}
+
+	// validators that are not yet active go through the pending deposits
+	preActivation := []int{}
+
+	for index, validator := range post.Validators {
+		if validator.ActivationEpoch == farFutureEpoch {
+			preActivation = append(preActivation, index)
+		}
+	}
+
+	slices.SortStableFunc(preActivation, func(a, b int) int {
+		return cmp.Compare(post.Validators[a].ActivationEligibilityEpoch, post.Validators[b].ActivationEligibilityEpoch)
+	})
+
+	for _, index := range preActivation {
+		validator := post.Validators[index]
+
+		post.PendingDeposits = append(post.PendingDeposits, &electra.PendingDeposit{
+			Pubkey:                validator.PublicKey,
+			WithdrawalCredentials: validator.WithdrawalCredentials,
+			Amount:                post.Balances[index],
+			Signature:             g2PointAtInfinity,
+			Slot:                  0,
+		})
+
+		post.Balances[index] = 0
+		validator.EffectiveBalance = 0
+		validator.ActivationEligibilityEpoch = farFutureEpoch
+	}
+
+	// early adopters of compounding credentials go through the activation churn
+	minActivationBalance := phase0.Gwei(clConfig.GetUintDefault("MIN_ACTIVATION_BALANCE", 32_000_000_000))
+
+	for index, validator := range post.Validators {
+		if len(validator.WithdrawalCredentials) == 0 || validator.WithdrawalCredentials[0] != 0x02 {
+			continue
+		}
+
+		// queue_excess_active_balance
+		if balance := post.Balances[index]; balance > minActivationBalance {
+			post.Balances[index] = minActivationBalance
+
+			post.PendingDeposits = append(post.PendingDeposits, &electra.PendingDeposit{
+				Pubkey:                validator.PublicKey,
+				WithdrawalCredentials: validator.WithdrawalCredentials,
+				Amount:                balance - minActivationBalance,
+				Signature:             g2PointAtInfinity,
+				Slot:                  0,
+			})
+		}
+	}
+
+	return post
+}
+
+func upgradeToFulu(pre *electra.BeaconState, clConfig *beaconconfig.Config) (*fulu.BeaconState, error) {
+	epoch := getCurrentEpoch(clConfig, pre.Slot)
+
+	proposerLookahead, err := beaconutils.GetProposerLookahead(clConfig, pre.Validators, pre.RANDAOMixes, epoch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate proposer lookahead: %w", err)
+	}
+
+	return &fulu.BeaconState{
+		GenesisTime:                   pre.GenesisTime,
+		GenesisValidatorsRoot:         pre.GenesisValidatorsRoot,
+		Slot:                          pre.Slot,
+		Fork:                          upgradeFork(pre.Fork, spec.DataVersionFulu, epoch, clConfig),
+		LatestBlockHeader:             pre.LatestBlockHeader,
+		BlockRoots:                    pre.BlockRoots,
+		StateRoots:                    pre.StateRoots,
+		HistoricalRoots:               pre.HistoricalRoots,
+		ETH1Data:                      pre.ETH1Data,
+		ETH1DataVotes:                 pre.ETH1DataVotes,
+		ETH1DepositIndex:              pre.ETH1DepositIndex,
+		Validators:                    pre.Validators,
+		Balances:                      pre.Balances,
+		RANDAOMixes:                   pre.RANDAOMixes,
+		Slashings:                     pre.Slashings,
+		PreviousEpochParticipation:    pre.PreviousEpochParticipation,
+		CurrentEpochParticipation:     pre.CurrentEpochParticipation,
+		JustificationBits:             pre.JustificationBits,
+		PreviousJustifiedCheckpoint:   pre.PreviousJustifiedCheckpoint,
+		CurrentJustifiedCheckpoint:    pre.CurrentJustifiedCheckpoint,
+		FinalizedCheckpoint:           pre.FinalizedCheckpoint,
+		InactivityScores:              pre.InactivityScores,
+		CurrentSyncCommittee:          pre.CurrentSyncCommittee,
+		NextSyncCommittee:             pre.NextSyncCommittee,
+		LatestExecutionPayloadHeader:  pre.LatestExecutionPayloadHeader,
+		NextWithdrawalIndex:           pre.NextWithdrawalIndex,
+		NextWithdrawalValidatorIndex:  pre.NextWithdrawalValidatorIndex,
+		HistoricalSummaries:           pre.HistoricalSummaries,
+		DepositRequestsStartIndex:     pre.DepositRequestsStartIndex,
+		DepositBalanceToConsume:       pre.DepositBalanceToConsume,
+		ExitBalanceToConsume:          pre.ExitBalanceToConsume,
+		EarliestExitEpoch:             pre.EarliestExitEpoch,
+		ConsolidationBalanceToConsume: pre.ConsolidationBalanceToConsume,
+		EarliestConsolidationEpoch:    pre.EarliestConsolidationEpoch,
+		PendingDeposits:               pre.PendingDeposits,
+		PendingPartialWithdrawals:     pre.PendingPartialWithdrawals,
+		PendingConsolidations:         pre.PendingConsolidations,
+		ProposerLookahead:             proposerLookahead,
+	}, nil
+}
+
+func upgradeToGloas(pre *fulu.BeaconState, clConfig *beaconconfig.Config) (*gloas.BeaconState, error) {
+	epoch := getCurrentEpoch(clConfig, pre.Slot)
+	slotsPerEpoch := clConfig.GetUintDefault("SLOTS_PER_EPOCH", 32)
+	blocksPerHistoricalRoot := clConfig.GetUintDefault("SLOTS_PER_HISTORICAL_ROOT", 8192)
+
+	ptcWindow, err := beaconutils.GetPTCWindow(clConfig, pre.Validators, pre.RANDAOMixes, epoch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate PTC window: %w", err)
+	}
+
+	builderPendingPayments := make([]*gloas.BuilderPendingPayment, slotsPerEpoch*2)
+	for i := range builderPendingPayments {
+		builderPendingPayments[i] = &gloas.BuilderPendingPayment{
+			Withdrawal: &gloas.BuilderPendingWithdrawal{},
+		}
+	}
+
+	blockHash := pre.LatestExecutionPayloadHeader.BlockHash
+
+	post := &gloas.BeaconState{
+		GenesisTime:                   pre.GenesisTime,
+		GenesisValidatorsRoot:         pre.GenesisValidatorsRoot,
+		Slot:                          pre.Slot,
+		Fork:                          upgradeFork(pre.Fork, spec.DataVersionGloas, epoch, clConfig),
+		LatestBlockHeader:             pre.LatestBlockHeader,
+		BlockRoots:                    pre.BlockRoots,
+		StateRoots:                    pre.StateRoots,
+		HistoricalRoots:               pre.HistoricalRoots,
+		ETH1Data:                      pre.ETH1Data,
+		ETH1DataVotes:                 pre.ETH1DataVotes,
+		ETH1DepositIndex:              pre.ETH1DepositIndex,
+		Validators:                    pre.Validators,
+		Balances:                      pre.Balances,
+		RANDAOMixes:                   pre.RANDAOMixes,
+		Slashings:                     pre.Slashings,
+		PreviousEpochParticipation:    pre.PreviousEpochParticipation,
+		CurrentEpochParticipation:     pre.CurrentEpochParticipation,
+		JustificationBits:             pre.JustificationBits,
+		PreviousJustifiedCheckpoint:   pre.PreviousJustifiedCheckpoint,
+		CurrentJustifiedCheckpoint:    pre.CurrentJustifiedCheckpoint,
+		FinalizedCheckpoint:           pre.FinalizedCheckpoint,
+		InactivityScores:              pre.InactivityScores,
+		CurrentSyncCommittee:          pre.CurrentSyncCommittee,
+		NextSyncCommittee:             pre.NextSyncCommittee,
+		NextWithdrawalIndex:           pre.NextWithdrawalIndex,
+		NextWithdrawalValidatorIndex:  pre.NextWithdrawalValidatorIndex,
+		HistoricalSummaries:           pre.HistoricalSummaries,
+		DepositRequestsStartIndex:     pre.DepositRequestsStartIndex,
+		DepositBalanceToConsume:       pre.DepositBalanceToConsume,
+		ExitBalanceToConsume:          pre.ExitBalanceToConsume,
+		EarliestExitEpoch:             pre.EarliestExitEpoch,
+		ConsolidationBalanceToConsume: pre.ConsolidationBalanceToConsume,
+		EarliestConsolidationEpoch:    pre.EarliestConsolidationEpoch,
+		PendingDeposits:               pre.PendingDeposits,
+		PendingPartialWithdrawals:     pre.PendingPartialWithdrawals,
+		PendingConsolidations:         pre.PendingConsolidations,
+		ProposerLookahead:             pre.ProposerLookahead,
+		LatestExecutionPayloadBid: &gloas.ExecutionPayloadBid{
+			BlockHash: blockHash,
+		},
+		Builders:                     []*gloas.Builder{},
+		ExecutionPayloadAvailability: beaconutils.MakeAllOnesBitvector(blocksPerHistoricalRoot),
+		BuilderPendingPayments:       builderPendingPayments,
+		LatestBlockHash:              blockHash,
+		PayloadExpectedWithdrawals:   []*capella.Withdrawal{},
+		PTCWindow:                    ptcWindow,
+	}
+
+	onboardBuildersFromPendingDeposits(post, clConfig)
+
+	return post, nil
+}
+
+// onboardBuildersFromPendingDeposits moves the pending deposits with builder
+// withdrawal credentials to the builder registry
+// (onboard_builders_from_pending_deposits). Deposits for validators, including
+// validators that are created by an earlier pending deposit, stay pending.
+func onboardBuildersFromPendingDeposits(state *gloas.BeaconState, clConfig *beaconconfig.Config) {
+	farFutureEpoch := phase0.Epoch(clConfig.GetUintDefault("FAR_FUTURE_EPOCH", 18446744073709551615))
+	slotsPerEpoch := clConfig.GetUintDefault("SLOTS_PER_EPOCH", 32)
+
+	validatorPubkeys := make(map[phase0.BLSPubKey]bool, len(state.Validators))
+	for _, validator := range state.Validators {
+		validatorPubkeys[validator.PublicKey] = true
+	}
+
+	builderIndices := make(map[phase0.BLSPubKey]int, len(state.Builders))
+	pendingDeposits := make([]*electra.PendingDeposit, 0, len(state.PendingDeposits))
+
+	for _, deposit := range state.PendingDeposits {
+		if validatorPubkeys[deposit.Pubkey] {
+			pendingDeposits = append(pendingDeposits, deposit)
+			continue
+		}
+
+		isBuilderDeposit := len(deposit.WithdrawalCredentials) == 32 && deposit.WithdrawalCredentials[0] == 0x03
+
+		if builderIndex, isBuilder := builderIndices[deposit.Pubkey]; isBuilder {
+			state.Builders[builderIndex].Balance += deposit.Amount
+			continue
+		}
+
+		if !isBuilderDeposit {
+			if beaconutils.IsValidDepositSignature(clConfig, deposit.Pubkey, deposit.WithdrawalCredentials, deposit.Amount, deposit.Signature) {
+				validatorPubkeys[deposit.Pubkey] = true
+			}
+
+			pendingDeposits = append(pendingDeposits, deposit)
+
+			continue
+		}
+
+		// builder deposits with an invalid proof of possession are dropped
+		if !beaconutils.IsValidDepositSignature(clConfig, deposit.Pubkey, deposit.WithdrawalCredentials, deposit.Amount, deposit.Signature) {
+			continue
+		}
+
+		builderIndices[deposit.Pubkey] = len(state.Builders)
+		state.Builders = append(state.Builders, &gloas.Builder{
+			PublicKey:         deposit.Pubkey,
+			Version:           deposit.WithdrawalCredentials[0],
+			ExecutionAddress:  bellatrix.ExecutionAddress(deposit.WithdrawalCredentials[12:]),
+			Balance:           deposit.Amount,
+			DepositEpoch:      phase0.Epoch(uint64(deposit.Slot) / slotsPerEpoch),
+			WithdrawableEpoch: farFutureEpoch,
+		})
+	}
+
+	state.PendingDeposits = pendingDeposits
+}
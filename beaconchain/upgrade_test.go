@@ -0,0 +1,394 @@
+package beaconchain
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ethpandaops/go-eth2-client/http"
+	"github.com/ethpandaops/go-eth2-client/spec"
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+
+	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
+	"github.com/ethpandaops/eth-beacon-genesis/validators"
+)
+
+func newTestUpgradeChainBuilder(t *testing.T, version spec.DataVersion) *upgradeChainBuilder {
+	t.Helper()
+
+	return &upgradeChainBuilder{
+		genesisBuilder: newGenesisBuilder(createTestELGenesis(), createTestGenesisConfig(t), getGenesisFork(version)),
+	}
+}
+
+func TestUpgradeChainBuilder_Phase0MatchesDirect(t *testing.T) {
+	clConfig := createTestGenesisConfig(t)
+	vals := createTestGenesisValidators(t)
+
+	direct := NewPhase0Builder(createTestELGenesis(), clConfig)
+	direct.AddValidators(vals)
+
+	chain := newTestUpgradeChainBuilder(t, spec.DataVersionPhase0)
+	chain.AddValidators(vals)
+
+	directState, err := direct.BuildState()
+	if err != nil {
+		t.Fatalf("failed to build direct state: %v", err)
+	}
+
+	chainState, err := chain.BuildState()
+	if err != nil {
+		t.Fatalf("failed to build upgrade chain state: %v", err)
+	}
+
+	directData, err := direct.Serialize(directState, http.ContentTypeSSZ)
+	if err != nil {
+		t.Fatalf("failed to serialize direct state: %v", err)
+	}
+
+	chainData, err := chain.Serialize(chainState, http.ContentTypeSSZ)
+	if err != nil {
+		t.Fatalf("failed to serialize upgrade chain state: %v", err)
+	}
+
+	if !bytes.Equal(directData, chainData) {
+		t.Fatalf("phase0 upgrade chain state differs from direct state")
+	}
+}
+
+func TestUpgradeChainBuilder_AllForks(t *testing.T) {
+	vals := createTestGenesisValidators(t)
+
+	builders := []*validators.Builder{
+		{
+			PublicKey: vals[0].PublicKey,
+			Version:   validators.BuilderWithdrawalPrefix,
+			Source:    "builders",
+		},
+	}
+
+	// fields that both constructions take from the genesis block
+	sharedFields := []string{"genesis_time", "latest_block_header", "eth1_data", "randao_mixes", "slashings"}
+
+	for _, forkConfig := range ForkConfigs {
+		t.Run(forkConfig.Version.String(), func(t *testing.T) {
+			clConfig := createTestGenesisConfig(t)
+
+			direct := forkConfig.BuilderFn(createTestELGenesis(), clConfig)
+			chain := newTestUpgradeChainBuilder(t, forkConfig.Version)
+
+			direct.AddValidators(vals)
+			chain.AddValidators(vals)
+
+			if registry, ok := direct.(BuilderRegistry); ok {
+				registry.AddBuilders(builders)
+				chain.AddBuilders(builders)
+			}
+
+			directState, err := direct.BuildState()
+			if err != nil {
+				t.Fatalf("failed to build direct state: %v", err)
+			}
+
+			chainState, err := chain.BuildState()
+			if err != nil {
+				t.Fatalf("failed to build upgrade chain state: %v", err)
+			}
+
+			if chainState.Version != forkConfig.Version {
+				t.Fatalf("expected %s state, got %s", forkConfig.Version, chainState.Version)
+			}
+
+			if _, err := chain.Serialize(chainState, http.ContentTypeSSZ); err != nil {
+				t.Fatalf("failed to serialize upgrade chain state: %v", err)
+			}
+
+			diffs, err := DiffStates(directState, chainState)
+			if err != nil {
+				t.Fatalf("failed to compare states: %v", err)
+			}
+
+			for _, field := range sharedFields {
+				if slices.Contains(diffs, field) {
+					t.Fatalf("expected %s to match the direct state, differing fields: %v", field, diffs)
+				}
+			}
+
+			if forkConfig.Version >= spec.DataVersionGloas {
+				if len(chainState.Gloas.Builders) != len(directState.Gloas.Builders) {
+					t.Fatalf("expected %d builders, got %d", len(directState.Gloas.Builders), len(chainState.Gloas.Builders))
+				}
+
+				if chainState.Gloas.LatestBlockHash != directState.Gloas.LatestBlockHash {
+					t.Fatalf("latest block hash differs from direct state")
+				}
+			}
+		})
+	}
+}
+
+func TestUpgradeState_Electra(t *testing.T) {
+	clConfig := createTestGenesisConfig(t)
+	vals := createTestGenesisValidators(t)
+
+	builder := NewDenebBuilder(createTestELGenesis(), clConfig)
+	builder.AddValidators(vals)
+
+	denebState, err := builder.BuildState()
+	if err != nil {
+		t.Fatalf("failed to build deneb state: %v", err)
+	}
+
+	denebBalances := slices.Clone(denebState.Deneb.Balances)
+
+	state, err := UpgradeState(denebState, spec.DataVersionElectra, clConfig)
+	if err != nil {
+		t.Fatalf("failed to upgrade state: %v", err)
+	}
+
+	if state.Version != spec.DataVersionElectra {
+		t.Fatalf("expected electra state, got %s", state.Version)
+	}
+
+	electraState := state.Electra
+
+	expectedFork := &phase0.Fork{
+		PreviousVersion: phase0.Version{0x50, 0x00, 0x00, 0x38},
+		CurrentVersion:  phase0.Version{0x60, 0x00, 0x00, 0x38},
+	}
+
+	if *electraState.Fork != *expectedFork {
+		t.Fatalf("unexpected fork: %v", electraState.Fork)
+	}
+
+	if electraState.DepositRequestsStartIndex != 18446744073709551615 {
+		t.Fatalf("expected unset deposit requests start index, got %d", electraState.DepositRequestsStartIndex)
+	}
+
+	// compute_activation_exit_epoch(0) = 1 + MAX_SEED_LOOKAHEAD (4), the
+	// earliest exit epoch is one epoch later
+	if electraState.EarliestExitEpoch != 6 {
+		t.Fatalf("expected earliest exit epoch 6, got %d", electraState.EarliestExitEpoch)
+	}
+
+	if electraState.EarliestConsolidationEpoch != 5 {
+		t.Fatalf("expected earliest consolidation epoch 5, got %d", electraState.EarliestConsolidationEpoch)
+	}
+
+	// 70 active validators with 32 ETH stay below the minimum churn of 128 ETH
+	if electraState.ExitBalanceToConsume != 128_000_000_000 {
+		t.Fatalf("expected exit balance to consume 128 ETH, got %d", electraState.ExitBalanceToConsume)
+	}
+
+	if electraState.ConsolidationBalanceToConsume != 0 {
+		t.Fatalf("expected consolidation balance to consume 0, got %d", electraState.ConsolidationBalanceToConsume)
+	}
+
+	// the 4 compounding validators with 64 ETH get their excess queued
+	if len(electraState.PendingDeposits) != 4 {
+		t.Fatalf("expected 4 pending deposits, got %d", len(electraState.PendingDeposits))
+	}
+
+	for i, deposit := range electraState.PendingDeposits {
+		if deposit.Pubkey != vals[64+i].PublicKey {
+			t.Fatalf("pending deposit %d has pubkey %s, expected %s", i, deposit.Pubkey.String(), vals[64+i].PublicKey.String())
+		}
+
+		if deposit.Amount != 32_000_000_000 {
+			t.Fatalf("pending deposit %d has amount %d, expected 32 ETH", i, deposit.Amount)
+		}
+
+		if electraState.Balances[64+i] != 32_000_000_000 {
+			t.Fatalf("validator %d has balance %d, expected 32 ETH", 64+i, electraState.Balances[64+i])
+		}
+	}
+
+	if !slices.Equal(denebState.Deneb.Balances, denebBalances) {
+		t.Fatalf("upgrade modified the balances of the deneb state")
+	}
+}
+
+func TestUpgradeState_Errors(t *testing.T) {
+	clConfig := createTestGenesisConfig(t)
+
+	builder := NewCapellaBuilder(createTestELGenesis(), clConfig)
+	builder.AddValidators(createTestGenesisValidators(t))
+
+	state, err := builder.BuildState()
+	if err != nil {
+		t.Fatalf("failed to build capella state: %v", err)
+	}
+
+	if _, err := UpgradeState(state, spec.DataVersionBellatrix, clConfig); err == nil {
+		t.Fatalf("expected error when downgrading a capella state")
+	}
+
+	if _, err := UpgradeState(state, spec.DataVersion(100), clConfig); err == nil {
+		t.Fatalf("expected error for an unsupported target version")
+	}
+}
+
+func TestDiffStates(t *testing.T) {
+	clConfig := createTestGenesisConfig(t)
+	vals := createTestGenesisValidators(t)
+
+	builder := NewAltairBuilder(createTestELGenesis(), clConfig)
+	builder.AddValidators(vals)
+
+	a, err := builder.BuildState()
+	if err != nil {
+		t.Fatalf("failed to build state: %v", err)
+	}
+
+	b, err := builder.BuildState()
+	if err != nil {
+		t.Fatalf("failed to build state: %v", err)
+	}
+
+	diffs, err := DiffStates(a, b)
+	if err != nil {
+		t.Fatalf("failed to compare states: %v", err)
+	}
+
+	if len(diffs) != 0 {
+		t.Fatalf("expected no differences, got %v", diffs)
+	}
+
+	b.Altair.GenesisTime++
+	b.Altair.Slot = 1
+
+	diffs, err = DiffStates(a, b)
+	if err != nil {
+		t.Fatalf("failed to compare states: %v", err)
+	}
+
+	if !slices.Equal(diffs, []string{"genesis_time", "slot"}) {
+		t.Fatalf("expected genesis_time and slot to differ, got %v", diffs)
+	}
+
+	if _, err := DiffStates(a, &spec.VersionedBeaconState{Version: spec.DataVersionBellatrix}); err == nil {
+		t.Fatalf("expected error for states of different forks")
+	}
+}
+
+func TestNewGenesisBuilderWithMode(t *testing.T) {
+	clConfig := createTestGenesisConfig(t)
+
+	builder, err := NewGenesisBuilderWithMode(createTestELGenesis(), clConfig, ConstructionUpgradeChain)
+	if err != nil {
+		t.Fatalf("failed to create upgrade chain builder: %v", err)
+	}
+
+	if _, ok := builder.(*upgradeChainBuilder); !ok {
+		t.Fatalf("expected upgrade chain builder, got %T", builder)
+	}
+
+	if _, err := NewGenesisBuilderWithMode(createTestELGenesis(), clConfig, "invalid"); err == nil || !strings.Contains(err.Error(), "unsupported construction mode") {
+		t.Fatalf("expected unsupported construction mode error, got %v", err)
+	}
+}
+
+// TestUpgradeState_Lookaheads checks that the proposer lookahead and PTC window
+// computed by the fulu and gloas upgrades match the direct genesis builders,
+// as a genesis state has the genesis block hash as randao mix of all epochs.
+func TestUpgradeState_Lookaheads(t *testing.T) {
+	clConfig := createTestGenesisConfig(t)
+	vals := createTestGenesisValidators(t)[:68] // without builder credentials
+
+	buildState := func(builder BeaconGenesisBuilder) *spec.VersionedBeaconState {
+		builder.AddValidators(vals)
+
+		state, err := builder.BuildState()
+		if err != nil {
+			t.Fatalf("failed to build state: %v", err)
+		}
+
+		return state
+	}
+
+	electraState := buildState(NewElectraBuilder(createTestELGenesis(), clConfig))
+	fuluState := buildState(NewFuluBuilder(createTestELGenesis(), clConfig))
+	gloasState := buildState(NewGloasBuilder(createTestELGenesis(), clConfig))
+
+	upgradedFulu, err := UpgradeState(electraState, spec.DataVersionFulu, clConfig)
+	if err != nil {
+		t.Fatalf("failed to upgrade state to fulu: %v", err)
+	}
+
+	if !slices.Equal(upgradedFulu.Fulu.ProposerLookahead, fuluState.Fulu.ProposerLookahead) {
+		t.Fatalf("proposer lookahead differs from direct fulu genesis")
+	}
+
+	upgradedGloas, err := UpgradeState(upgradedFulu, spec.DataVersionGloas, clConfig)
+	if err != nil {
+		t.Fatalf("failed to upgrade state to gloas: %v", err)
+	}
+
+	if len(upgradedGloas.Gloas.PTCWindow) != len(gloasState.Gloas.PTCWindow) {
+		t.Fatalf("expected PTC window of %d slots, got %d", len(gloasState.Gloas.PTCWindow), len(upgradedGloas.Gloas.PTCWindow))
+	}
+
+	for i := range gloasState.Gloas.PTCWindow {
+		if !slices.Equal(upgradedGloas.Gloas.PTCWindow[i], gloasState.Gloas.PTCWindow[i]) {
+			t.Fatalf("PTC window slot %d differs from direct gloas genesis", i)
+		}
+	}
+
+	// the upgraded state round trips through the serializers
+	data, err := SerializeState(upgradedGloas, clConfig, http.ContentTypeSSZ)
+	if err != nil {
+		t.Fatalf("failed to serialize upgraded state: %v", err)
+	}
+
+	decoded, err := DecodeStateSSZ(data, clConfig)
+	if err != nil {
+		t.Fatalf("failed to decode upgraded state: %v", err)
+	}
+
+	if decoded.Version != spec.DataVersionGloas {
+		t.Fatalf("expected gloas state, got %s", decoded.Version)
+	}
+}
+
+func TestUpgradeState_MissingForkVersion(t *testing.T) {
+	clConfig := createTestGenesisConfig(t)
+
+	builder := NewElectraBuilder(createTestELGenesis(), clConfig)
+	builder.AddValidators(createTestGenesisValidators(t))
+
+	state, err := builder.BuildState()
+	if err != nil {
+		t.Fatalf("failed to build electra state: %v", err)
+	}
+
+	configPath := writeTestFile(t, t.TempDir(), "config.yaml", `
+PRESET_BASE: minimal
+ELECTRA_FORK_VERSION: 0x60000038
+`)
+
+	electraConfig, err := beaconconfig.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	_, err = UpgradeState(state, spec.DataVersionFulu, electraConfig)
+	if err == nil || !strings.Contains(err.Error(), "FULU_FORK_VERSION not found") {
+		t.Fatalf("expected missing fork version error, got %v", err)
+	}
+}
+
+func TestGetForkVersionByName(t *testing.T) {
+	version, err := GetForkVersionByName("Fulu")
+	if err != nil {
+		t.Fatalf("failed to get fork version: %v", err)
+	}
+
+	if version != spec.DataVersionFulu {
+		t.Fatalf("expected fulu, got %s", version)
+	}
+
+	if _, err := GetForkVersionByName("verkle"); err == nil {
+		t.Fatalf("expected error for an unknown fork")
+	}
+}
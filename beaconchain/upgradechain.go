@@ -0,0 +1,179 @@
+package beaconchain
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethpandaops/go-eth2-client/spec"
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
+	"github.com/ethpandaops/eth-beacon-genesis/beaconutils"
+	"github.com/ethpandaops/eth-beacon-genesis/validators"
+)
+
+// upgradeChainBuilder builds the genesis state by applying the fork upgrades
+// of the spec to a phase0 genesis state, which is the path clients take at
+// fork boundaries. The fields the upgrades leave empty, but a genesis state
+// takes from the genesis block (latest block header, execution payload header
+// or bid) and the genesis builders are set like the direct builders do.
+type upgradeChainBuilder struct {
+	*genesisBuilder
+	builders []*validators.Builder
+}
+
+func NewUpgradeChainBuilder(elGenesis *core.Genesis, clConfig *beaconconfig.Config) BeaconGenesisBuilder {
+	fork := getGenesisFork(GetGenesisForkVersion(clConfig))
+	if fork == nil {
+		return nil
+	}
+
+	return &upgradeChainBuilder{
+		genesisBuilder: newGenesisBuilder(elGenesis, clConfig, fork),
+	}
+}
+
+func (b *upgradeChainBuilder) AddBuilders(builders []*validators.Builder) {
+	b.builders = append(b.builders, builders...)
+}
+
+func (b *upgradeChainBuilder) GetGenesisRegistry() ([]*validators.Validator, []*validators.Builder) {
+	if b.fork.version < spec.DataVersionGloas {
+		return b.validators, b.builders
+	}
+
+	return getGenesisRegistry(b.validators, b.builders)
+}
+
+func (b *upgradeChainBuilder) BuildState() (*spec.VersionedBeaconState, error) {
+	genesisVals, genesisBuilders := b.GetGenesisRegistry()
+
+	return b.buildState(genesisVals, func(g *genesisData, base *phase0.BeaconState) (*spec.VersionedBeaconState, error) {
+		// base has the latest block header of the genesis fork, which is
+		// restored after the upgrades
+		latestBlockHeader := base.LatestBlockHeader
+
+		if err := b.convertToPhase0Genesis(g, base); err != nil {
+			return nil, err
+		}
+
+		state, err := UpgradeState(&spec.VersionedBeaconState{
+			Version: spec.DataVersionPhase0,
+			Phase0:  base,
+		}, b.fork.version, b.clConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := setGenesisBlockFields(g, state, latestBlockHeader, genesisBuilders); err != nil {
+			return nil, err
+		}
+
+		logrus.Infof("applied fork upgrades from phase0 to %s", b.fork.version.String())
+
+		if state.Version >= spec.DataVersionGloas {
+			logrus.Infof("genesis validators: %d, builders: %d", len(state.Gloas.Validators), len(state.Gloas.Builders))
+		}
+
+		return state, nil
+	})
+}
+
+// convertToPhase0Genesis turns the shared genesis state fields into a phase0
+// genesis state. Phase0 caps all effective balances at MAX_EFFECTIVE_BALANCE,
+// which also changes the genesis validators root of compounding validators.
+func (b *upgradeChainBuilder) convertToPhase0Genesis(g *genesisData, base *phase0.BeaconState) error {
+	blockBody, err := phase0BlockBody(g)
+	if err != nil {
+		return err
+	}
+
+	bodyRoot, err := b.dynSsz.HashTreeRoot(blockBody)
+	if err != nil {
+		return fmt.Errorf("failed to compute genesis block body root: %w", err)
+	}
+
+	maxEffectiveBalance := phase0.Gwei(b.clConfig.GetUintDefault("MAX_EFFECTIVE_BALANCE", 32_000_000_000))
+	phase0Validators := make([]*phase0.Validator, len(base.Validators))
+
+	for i, validator := range base.Validators {
+		phase0Validator := *validator
+		phase0Validator.EffectiveBalance = min(phase0Validator.EffectiveBalance, maxEffectiveBalance)
+		phase0Validators[i] = &phase0Validator
+	}
+
+	validatorsRoot, err := beaconutils.GetValidatorsRoot(b.clConfig, phase0Validators)
+	if err != nil {
+		return fmt.Errorf("failed to compute genesis validators root: %w", err)
+	}
+
+	base.Fork = GetStateForkConfig(spec.DataVersionPhase0, b.clConfig)
+	base.LatestBlockHeader = &phase0.BeaconBlockHeader{
+		BodyRoot: bodyRoot,
+	}
+	base.Validators = phase0Validators
+	base.GenesisValidatorsRoot = validatorsRoot
+
+	return nil
+}
+
+// setGenesisBlockFields sets the fields of an upgraded genesis state that are
+// derived from the genesis block, and the genesis builders of gloas states.
+func setGenesisBlockFields(g *genesisData, state *spec.VersionedBeaconState, latestBlockHeader *phase0.BeaconBlockHeader, builders []*validators.Builder) error {
+	switch state.Version {
+	case spec.DataVersionPhase0:
+		state.Phase0.LatestBlockHeader = latestBlockHeader
+	case spec.DataVersionAltair:
+		state.Altair.LatestBlockHeader = latestBlockHeader
+	case spec.DataVersionBellatrix:
+		execHeader, err := bellatrixExecutionHeader(g)
+		if err != nil {
+			return err
+		}
+
+		state.Bellatrix.LatestBlockHeader = latestBlockHeader
+		state.Bellatrix.LatestExecutionPayloadHeader = execHeader
+	case spec.DataVersionCapella:
+		execHeader, err := capellaExecutionHeader(g)
+		if err != nil {
+			return err
+		}
+
+		state.Capella.LatestBlockHeader = latestBlockHeader
+		state.Capella.LatestExecutionPayloadHeader = execHeader
+	case spec.DataVersionDeneb, spec.DataVersionElectra, spec.DataVersionFulu:
+		execHeader, err := denebExecutionHeader(g)
+		if err != nil {
+			return err
+		}
+
+		switch state.Version {
+		case spec.DataVersionDeneb:
+			state.Deneb.LatestBlockHeader = latestBlockHeader
+			state.Deneb.LatestExecutionPayloadHeader = execHeader
+		case spec.DataVersionElectra:
+			state.Electra.LatestBlockHeader = latestBlockHeader
+			state.Electra.LatestExecutionPayloadHeader = execHeader
+		default:
+			state.Fulu.LatestBlockHeader = latestBlockHeader
+			state.Fulu.LatestExecutionPayloadHeader = execHeader
+		}
+	case spec.DataVersionGloas:
+		_, executionRequestsRoot, err := emptyExecutionRequests(g)
+		if err != nil {
+			return err
+		}
+
+		state.Gloas.LatestBlockHeader = latestBlockHeader
+		state.Gloas.LatestExecutionPayloadBid.ParentBlockHash = g.blockHash
+		state.Gloas.LatestExecutionPayloadBid.BlockHash = phase0.Hash32{}
+		state.Gloas.LatestExecutionPayloadBid.ExecutionRequestsRoot = executionRequestsRoot
+		state.Gloas.LatestBlockHash = g.blockHash
+		state.Gloas.Builders = append(beaconutils.GetGenesisBuilders(g.clConfig, builders), state.Gloas.Builders...)
+	default:
+		return fmt.Errorf("unsupported version: %s", state.Version)
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"math/big"
 	"os"
 	"strconv"
 	"strings"
@@ -35,6 +36,13 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("parsing yaml: %w", err)
 	}
 
+	// integers beyond uint64 (like TERMINAL_TOTAL_DIFFICULTY) are decoded as
+	// float, their exact value is taken from the yaml nodes
+	nodes := make(map[string]yaml.Node)
+	if err := yaml.Unmarshal(data, &nodes); err != nil {
+		return nil, fmt.Errorf("parsing yaml: %w", err)
+	}
+
 	for key, val := range values {
 		switch value := val.(type) {
 		case int:
@@ -48,6 +56,11 @@ func LoadConfig(path string) (*Config, error) {
 			}
 		case uint64:
 			config.values[key] = value
+		case float64:
+			node := nodes[key]
+			if bigVal, ok := new(big.Int).SetString(node.Value, 10); ok {
+				config.values[key] = bigVal
+			}
 		case string:
 			if strings.HasPrefix(value, "0x") {
 				bytes, err := hex.DecodeString(strings.ReplaceAll(value, "0x", ""))
@@ -143,6 +156,26 @@ func (c *Config) GetUintDefault(key string, defaultVal uint64) uint64 {
 	return value
 }
 
+// GetBigInt returns an integer value that may exceed uint64, like
+// TERMINAL_TOTAL_DIFFICULTY.
+func (c *Config) GetBigInt(key string) (*big.Int, bool) {
+	value, ok := c.Get(key)
+	if !ok {
+		return nil, false
+	}
+
+	switch val := value.(type) {
+	case *big.Int:
+		return new(big.Int).Set(val), true
+	case uint64:
+		return new(big.Int).SetUint64(val), true
+	case string:
+		return new(big.Int).SetString(val, 10)
+	}
+
+	return nil, false
+}
+
 func (c *Config) GetBytes(key string) ([]byte, bool) {
 	value, ok := c.Get(key)
 	if !ok {
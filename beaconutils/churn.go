@@ -0,0 +1,52 @@
+package beaconutils
+
+import (
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+
+	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
+)
+
+// GetTotalActiveBalance returns the sum of the effective balances of the
+// validators active at epoch, but at least EFFECTIVE_BALANCE_INCREMENT
+// (get_total_active_balance).
+func GetTotalActiveBalance(cfg *beaconconfig.Config, validators []*phase0.Validator, epoch phase0.Epoch) phase0.Gwei {
+	total := phase0.Gwei(0)
+
+	for _, index := range GetActiveValidatorIndices(validators, epoch) {
+		total += validators[index].EffectiveBalance
+	}
+
+	return max(total, phase0.Gwei(cfg.GetUintDefault("EFFECTIVE_BALANCE_INCREMENT", 1_000_000_000)))
+}
+
+// GetBalanceChurnLimit returns the electra balance churn limit for a total
+// active balance (get_balance_churn_limit).
+func GetBalanceChurnLimit(cfg *beaconconfig.Config, totalActiveBalance phase0.Gwei) phase0.Gwei {
+	minChurnLimit := phase0.Gwei(cfg.GetUintDefault("MIN_PER_EPOCH_CHURN_LIMIT_ELECTRA", 128_000_000_000))
+	churnLimitQuotient := phase0.Gwei(cfg.GetUintDefault("CHURN_LIMIT_QUOTIENT", 65536))
+	effectiveBalanceIncrement := phase0.Gwei(cfg.GetUintDefault("EFFECTIVE_BALANCE_INCREMENT", 1_000_000_000))
+
+	churn := max(minChurnLimit, totalActiveBalance/churnLimitQuotient)
+
+	return churn - churn%effectiveBalanceIncrement
+}
+
+// GetActivationExitChurnLimit returns the churn limit for activations and
+// exits (get_activation_exit_churn_limit).
+func GetActivationExitChurnLimit(cfg *beaconconfig.Config, totalActiveBalance phase0.Gwei) phase0.Gwei {
+	maxChurnLimit := phase0.Gwei(cfg.GetUintDefault("MAX_PER_EPOCH_ACTIVATION_EXIT_CHURN_LIMIT", 256_000_000_000))
+
+	return min(maxChurnLimit, GetBalanceChurnLimit(cfg, totalActiveBalance))
+}
+
+// GetConsolidationChurnLimit returns the churn limit for consolidations
+// (get_consolidation_churn_limit).
+func GetConsolidationChurnLimit(cfg *beaconconfig.Config, totalActiveBalance phase0.Gwei) phase0.Gwei {
+	return GetBalanceChurnLimit(cfg, totalActiveBalance) - GetActivationExitChurnLimit(cfg, totalActiveBalance)
+}
+
+// ComputeActivationExitEpoch returns the epoch at which activations and exits
+// initiated at epoch take effect (compute_activation_exit_epoch).
+func ComputeActivationExitEpoch(cfg *beaconconfig.Config, epoch phase0.Epoch) phase0.Epoch {
+	return epoch + 1 + phase0.Epoch(cfg.GetUintDefault("MAX_SEED_LOOKAHEAD", 4))
+}
@@ -0,0 +1,95 @@
+package beaconutils
+
+import (
+	"testing"
+
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+)
+
+func createChurnTestValidators(count int, effectiveBalance phase0.Gwei) []*phase0.Validator {
+	vals := make([]*phase0.Validator, count)
+	for i := range vals {
+		vals[i] = &phase0.Validator{
+			EffectiveBalance: effectiveBalance,
+			ActivationEpoch:  0,
+			ExitEpoch:        phase0.Epoch(18446744073709551615),
+		}
+	}
+
+	return vals
+}
+
+func TestGetTotalActiveBalance(t *testing.T) {
+	cfg := createTestConfig(t, "mainnet", map[string]interface{}{})
+
+	vals := createChurnTestValidators(4, 32_000_000_000)
+	vals[1].ActivationEpoch = 2
+	vals[2].ExitEpoch = 0
+
+	if total := GetTotalActiveBalance(cfg, vals, 0); total != 64_000_000_000 {
+		t.Fatalf("expected total active balance 64 ETH, got %d", total)
+	}
+
+	// no active validators, the total is at least one increment
+	if total := GetTotalActiveBalance(cfg, vals[2:3], 0); total != 1_000_000_000 {
+		t.Fatalf("expected total active balance 1 ETH, got %d", total)
+	}
+}
+
+func TestChurnLimits(t *testing.T) {
+	cfg := createTestConfig(t, "mainnet", map[string]interface{}{})
+
+	tests := []struct {
+		name               string
+		totalActiveBalance phase0.Gwei
+		balanceChurn       phase0.Gwei
+		activationExit     phase0.Gwei
+		consolidation      phase0.Gwei
+	}{
+		{
+			name:               "minimum churn",
+			totalActiveBalance: 64 * 32_000_000_000,
+			balanceChurn:       128_000_000_000,
+			activationExit:     128_000_000_000,
+			consolidation:      0,
+		},
+		{
+			name:               "above minimum churn",
+			totalActiveBalance: 10_000_000_000_000_000,
+			balanceChurn:       152_000_000_000,
+			activationExit:     152_000_000_000,
+			consolidation:      0,
+		},
+		{
+			name:               "above activation exit cap",
+			totalActiveBalance: 34_000_000_000_000_000,
+			balanceChurn:       518_000_000_000,
+			activationExit:     256_000_000_000,
+			consolidation:      262_000_000_000,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if churn := GetBalanceChurnLimit(cfg, test.totalActiveBalance); churn != test.balanceChurn {
+				t.Fatalf("expected balance churn limit %d, got %d", test.balanceChurn, churn)
+			}
+
+			if churn := GetActivationExitChurnLimit(cfg, test.totalActiveBalance); churn != test.activationExit {
+				t.Fatalf("expected activation exit churn limit %d, got %d", test.activationExit, churn)
+			}
+
+			if churn := GetConsolidationChurnLimit(cfg, test.totalActiveBalance); churn != test.consolidation {
+				t.Fatalf("expected consolidation churn limit %d, got %d", test.consolidation, churn)
+			}
+		})
+	}
+}
+
+func TestComputeActivationExitEpoch(t *testing.T) {
+	cfg := createTestConfig(t, "mainnet", map[string]interface{}{})
+
+	if epoch := ComputeActivationExitEpoch(cfg, 10); epoch != 15 {
+		t.Fatalf("expected activation exit epoch 15, got %d", epoch)
+	}
+}
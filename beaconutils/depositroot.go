@@ -1,6 +1,8 @@
 package beaconutils
 
 import (
+	"crypto/sha256"
+
 	"github.com/ethpandaops/go-eth2-client/spec/phase0"
 	"github.com/pk910/dynamic-ssz/sszutils"
 
@@ -10,12 +12,59 @@ import (
 func ComputeDepositRoot(cfg *beaconconfig.Config) (phase0.Root, error) {
 	// Compute the SSZ hash-tree-root of the empty deposit tree,
 	// since that is what we put as eth1_data.deposit_root in the CL genesis state.
+	return ComputeDepositTreeRoot(cfg, nil)
+}
+
+// ComputeDepositTreeRoot returns the root of the deposit tree with the given
+// deposits, which is the SSZ hash tree root of the deposit data list and
+// equals get_deposit_root of the deposit contract.
+func ComputeDepositTreeRoot(cfg *beaconconfig.Config, deposits []*phase0.DepositData) (phase0.Root, error) {
 	maxDeposits := cfg.GetUintDefault("MAX_DEPOSITS_PER_PAYLOAD", 1<<cfg.GetUintDefault("DEPOSIT_CONTRACT_TREE_DEPTH", 32))
 
-	depositRoot, _ := HashWithFastSSZHasher(func(hh sszutils.HashWalker) error {
-		hh.MerkleizeWithMixin(0, 0, maxDeposits)
+	depositRoot, err := HashWithFastSSZHasher(func(hh sszutils.HashWalker) error {
+		for _, deposit := range deposits {
+			if err := deposit.HashTreeRootWith(hh); err != nil {
+				return err
+			}
+		}
+
+		hh.MerkleizeWithMixin(0, uint64(len(deposits)), maxDeposits)
+
 		return nil
 	})
+	if err != nil {
+		return phase0.Root{}, err
+	}
 
 	return phase0.Root(depositRoot), nil
 }
+
+// ComputeDepositContractBranch returns the branch of the incremental merkle
+// tree of the deposit contract after the given deposits. Together with the
+// deposit count it is the deposit tree state kept in the contract storage.
+func ComputeDepositContractBranch(cfg *beaconconfig.Config, deposits []*phase0.DepositData) ([]phase0.Root, error) {
+	depth := cfg.GetUintDefault("DEPOSIT_CONTRACT_TREE_DEPTH", 32)
+	branch := make([]phase0.Root, depth)
+
+	for i, deposit := range deposits {
+		node, err := deposit.HashTreeRoot()
+		if err != nil {
+			return nil, err
+		}
+
+		// same as the deposit function of the contract
+		size := uint64(i + 1)
+
+		for height := uint64(0); height < depth; height++ {
+			if size&1 == 1 {
+				branch[height] = node
+				break
+			}
+
+			node = sha256.Sum256(append(branch[height][:], node[:]...))
+			size /= 2
+		}
+	}
+
+	return branch, nil
+}
@@ -0,0 +1,126 @@
+package beaconutils
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
+	"github.com/ethpandaops/eth-beacon-genesis/validators"
+)
+
+// GetGenesisDeposits returns the deposit data of the genesis validators, one
+// deposit of the validator balance per validator, in validator order. All
+// validators need a valid deposit signature, as invalid deposits would not
+// create a validator in initialize_beacon_state_from_eth1.
+func GetGenesisDeposits(cfg *beaconconfig.Config, vals []*validators.Validator) ([]*phase0.DepositData, error) {
+	balances := GetGenesisBalances(cfg, vals)
+	deposits := make([]*phase0.DepositData, len(vals))
+
+	// check for missing signatures before verifying any of them
+	for i, val := range vals {
+		if val.DepositSignature == nil {
+			return nil, fmt.Errorf("validator %s has no deposit signature", val.PublicKey.String())
+		}
+
+		deposits[i] = &phase0.DepositData{
+			PublicKey:             val.PublicKey,
+			WithdrawalCredentials: val.WithdrawalCredentials,
+			Amount:                balances[i],
+			Signature:             *val.DepositSignature,
+		}
+	}
+
+	var g errgroup.Group
+
+	g.SetLimit(runtime.NumCPU())
+
+	for i, val := range vals {
+		g.Go(func() error {
+			deposit := deposits[i]
+			if !IsValidDepositSignature(cfg, deposit.PublicKey, deposit.WithdrawalCredentials, deposit.Amount, deposit.Signature) {
+				return fmt.Errorf("invalid deposit signature for validator %s (%s key %d)", val.PublicKey.String(), val.Source, val.SourceKeyIndex)
+			}
+
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return deposits, nil
+}
+
+// SignGenesisDeposits signs the deposits of the genesis validators without a
+// deposit signature with their signing keys, which are matched by source and
+// key index. Validators without a signing key are left unsigned.
+func SignGenesisDeposits(cfg *beaconconfig.Config, vals []*validators.Validator, keys []*validators.SigningKey) (int, error) {
+	type keyID struct {
+		source   string
+		keyIndex uint64
+	}
+
+	keyMap := make(map[keyID]*validators.SigningKey, len(keys))
+	for _, key := range keys {
+		keyMap[keyID{key.Source, key.KeyIndex}] = key
+	}
+
+	balances := GetGenesisBalances(cfg, vals)
+	signed := make([]bool, len(vals))
+	signingKeys := make([]*validators.SigningKey, len(vals))
+
+	// match the keys before signing any deposit
+	for i, val := range vals {
+		key := keyMap[keyID{val.Source, val.SourceKeyIndex}]
+		if val.DepositSignature != nil || key == nil {
+			continue
+		}
+
+		if key.PublicKey != val.PublicKey {
+			return 0, fmt.Errorf("signing key %s of %s key %d does not match validator %s", key.PublicKey.String(), key.Source, key.KeyIndex, val.PublicKey.String())
+		}
+
+		signingKeys[i] = key
+	}
+
+	var g errgroup.Group
+
+	g.SetLimit(runtime.NumCPU())
+
+	for i, val := range vals {
+		key := signingKeys[i]
+		if key == nil {
+			continue
+		}
+
+		g.Go(func() error {
+			signature, err := SignDeposit(cfg, key.SecretKey, val.PublicKey, val.WithdrawalCredentials, balances[i])
+			if err != nil {
+				return fmt.Errorf("failed to sign deposit of validator %s: %w", val.PublicKey.String(), err)
+			}
+
+			val.DepositSignature = &signature
+			signed[i] = true
+
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	for _, isSigned := range signed {
+		if isSigned {
+			count++
+		}
+	}
+
+	return count, nil
+}
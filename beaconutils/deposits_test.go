@@ -0,0 +1,268 @@
+package beaconutils
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+	blsu "github.com/protolambda/bls12-381-util"
+
+	"github.com/ethpandaops/eth-beacon-genesis/validators"
+)
+
+func createTestSigningKey(t *testing.T, seed byte, source string, keyIndex uint64) *validators.SigningKey {
+	t.Helper()
+
+	secretKey := [32]byte{31: seed}
+
+	var blsSecretKey blsu.SecretKey
+	if err := blsSecretKey.Deserialize(&secretKey); err != nil {
+		t.Fatalf("failed to deserialize secret key: %v", err)
+	}
+
+	blsPubkey, err := blsu.SkToPk(&blsSecretKey)
+	if err != nil {
+		t.Fatalf("failed to derive pubkey: %v", err)
+	}
+
+	return &validators.SigningKey{
+		Source:    source,
+		KeyIndex:  keyIndex,
+		PublicKey: phase0.BLSPubKey(blsPubkey.Serialize()),
+		SecretKey: secretKey[:],
+	}
+}
+
+func TestComputeDepositTreeRoot(t *testing.T) {
+	cfg := createTestConfig(t, "mainnet", map[string]interface{}{})
+
+	emptyRoot, err := ComputeDepositRoot(cfg)
+	if err != nil {
+		t.Fatalf("failed to compute empty deposit root: %v", err)
+	}
+
+	root, err := ComputeDepositTreeRoot(cfg, nil)
+	if err != nil {
+		t.Fatalf("failed to compute deposit tree root: %v", err)
+	}
+
+	if root != emptyRoot {
+		t.Fatalf("expected empty deposit tree root 0x%x, got 0x%x", emptyRoot, root)
+	}
+
+	deposits := []*phase0.DepositData{
+		{PublicKey: phase0.BLSPubKey{0x01}, WithdrawalCredentials: make([]byte, 32), Amount: 32_000_000_000, Signature: phase0.BLSSignature{0x02}},
+		{PublicKey: phase0.BLSPubKey{0x03}, WithdrawalCredentials: make([]byte, 32), Amount: 64_000_000_000, Signature: phase0.BLSSignature{0x04}},
+		{PublicKey: phase0.BLSPubKey{0x05}, WithdrawalCredentials: make([]byte, 32), Amount: 1_000_000_000, Signature: phase0.BLSSignature{0x06}},
+	}
+
+	root, err = ComputeDepositTreeRoot(cfg, deposits)
+	if err != nil {
+		t.Fatalf("failed to compute deposit tree root: %v", err)
+	}
+
+	// get_deposit_root of the deposit contract: a depth 32 merkle tree of the
+	// deposit data roots with the deposit count mixed in
+	leaves := make([][32]byte, len(deposits))
+
+	for i, deposit := range deposits {
+		leaves[i], err = deposit.HashTreeRoot()
+		if err != nil {
+			t.Fatalf("failed to compute deposit data root: %v", err)
+		}
+	}
+
+	zeroHash := [32]byte{}
+
+	for depth := 0; depth < 32; depth++ {
+		if len(leaves)%2 == 1 {
+			leaves = append(leaves, zeroHash)
+		}
+
+		parents := make([][32]byte, len(leaves)/2)
+		for i := range parents {
+			parents[i] = sha256.Sum256(append(leaves[2*i][:], leaves[2*i+1][:]...))
+		}
+
+		leaves = parents
+		zeroHash = sha256.Sum256(append(zeroHash[:], zeroHash[:]...))
+	}
+
+	count := make([]byte, 32)
+	binary.LittleEndian.PutUint64(count, uint64(len(deposits)))
+
+	expectedRoot := sha256.Sum256(append(leaves[0][:], count...))
+	if root != phase0.Root(expectedRoot) {
+		t.Fatalf("expected deposit tree root 0x%x, got 0x%x", expectedRoot, root)
+	}
+}
+
+func TestGetGenesisDeposits(t *testing.T) {
+	cfg := createTestConfig(t, "mainnet", map[string]interface{}{
+		"GENESIS_FORK_VERSION": []byte{0x10, 0x00, 0x00, 0x38},
+	})
+
+	keys := []*validators.SigningKey{
+		createTestSigningKey(t, 1, "mnemonic-0", 0),
+		createTestSigningKey(t, 2, "mnemonic-0", 1),
+		createTestSigningKey(t, 3, "additional-validators", 0),
+	}
+
+	balance := uint64(64_000_000_000)
+	vals := make([]*validators.Validator, len(keys))
+
+	for i, key := range keys {
+		vals[i] = &validators.Validator{
+			PublicKey:             key.PublicKey,
+			WithdrawalCredentials: append([]byte{0x02}, make([]byte, 31)...),
+			Source:                key.Source,
+			SourceKeyIndex:        key.KeyIndex,
+		}
+	}
+
+	vals[1].Balance = &balance
+
+	// only the mnemonic keys are known
+	signed, err := SignGenesisDeposits(cfg, vals, keys[:2])
+	if err != nil {
+		t.Fatalf("failed to sign genesis deposits: %v", err)
+	}
+
+	if signed != 2 {
+		t.Fatalf("expected 2 signed deposits, got %d", signed)
+	}
+
+	_, err = GetGenesisDeposits(cfg, vals)
+	if err == nil || !strings.Contains(err.Error(), "has no deposit signature") {
+		t.Fatalf("expected missing deposit signature error, got %v", err)
+	}
+
+	// signature of the third validator from a deposit data file
+	signature, err := SignDeposit(cfg, keys[2].SecretKey, keys[2].PublicKey, vals[2].WithdrawalCredentials, 32_000_000_000)
+	if err != nil {
+		t.Fatalf("failed to sign deposit: %v", err)
+	}
+
+	vals[2].DepositSignature = &signature
+
+	deposits, err := GetGenesisDeposits(cfg, vals)
+	if err != nil {
+		t.Fatalf("failed to get genesis deposits: %v", err)
+	}
+
+	if len(deposits) != len(vals) {
+		t.Fatalf("expected %d deposits, got %d", len(vals), len(deposits))
+	}
+
+	if deposits[0].Amount != 32_000_000_000 || deposits[1].Amount != 64_000_000_000 {
+		t.Fatalf("expected deposit amounts of the genesis balances, got %d and %d", deposits[0].Amount, deposits[1].Amount)
+	}
+
+	if deposits[2].Signature != signature {
+		t.Fatalf("expected the given deposit signature to be kept")
+	}
+
+	// signatures are not replaced
+	signed, err = SignGenesisDeposits(cfg, vals, keys)
+	if err != nil {
+		t.Fatalf("failed to sign genesis deposits: %v", err)
+	}
+
+	if signed != 0 {
+		t.Fatalf("expected no signed deposits, got %d", signed)
+	}
+
+	// a signature over a different amount is rejected
+	vals[1].Balance = nil
+
+	_, err = GetGenesisDeposits(cfg, vals)
+	if err == nil || !strings.Contains(err.Error(), "invalid deposit signature") {
+		t.Fatalf("expected invalid deposit signature error, got %v", err)
+	}
+}
+
+func TestSignGenesisDeposits_KeyMismatch(t *testing.T) {
+	cfg := createTestConfig(t, "mainnet", map[string]interface{}{})
+
+	key := createTestSigningKey(t, 1, "mnemonic-0", 0)
+	mismatchKey := createTestSigningKey(t, 2, "mnemonic-0", 1)
+	otherKey := createTestSigningKey(t, 3, "mnemonic-0", 1)
+
+	vals := []*validators.Validator{
+		{
+			PublicKey:             key.PublicKey,
+			WithdrawalCredentials: make([]byte, 32),
+			Source:                "mnemonic-0",
+		},
+		{
+			PublicKey:             otherKey.PublicKey,
+			WithdrawalCredentials: make([]byte, 32),
+			Source:                "mnemonic-0",
+			SourceKeyIndex:        1,
+		},
+	}
+
+	_, err := SignGenesisDeposits(cfg, vals, []*validators.SigningKey{key, mismatchKey})
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("expected key mismatch error, got %v", err)
+	}
+
+	// the keys are matched before any deposit is signed
+	if vals[0].DepositSignature != nil {
+		t.Fatalf("expected no deposit to be signed after a key mismatch")
+	}
+}
+
+func TestComputeDepositContractBranch(t *testing.T) {
+	cfg := createTestConfig(t, "mainnet", map[string]interface{}{})
+
+	zeroHashes := make([][32]byte, 32)
+	for i := 1; i < len(zeroHashes); i++ {
+		zeroHashes[i] = sha256.Sum256(append(zeroHashes[i-1][:], zeroHashes[i-1][:]...))
+	}
+
+	deposits := []*phase0.DepositData{}
+
+	for i := 0; i <= 6; i++ {
+		branch, err := ComputeDepositContractBranch(cfg, deposits)
+		if err != nil {
+			t.Fatalf("failed to compute deposit contract branch: %v", err)
+		}
+
+		// get_deposit_root of the deposit contract
+		node := [32]byte{}
+		size := len(deposits)
+
+		for height := 0; height < 32; height++ {
+			if size&1 == 1 {
+				node = sha256.Sum256(append(branch[height][:], node[:]...))
+			} else {
+				node = sha256.Sum256(append(node[:], zeroHashes[height][:]...))
+			}
+
+			size /= 2
+		}
+
+		count := make([]byte, 32)
+		binary.LittleEndian.PutUint64(count, uint64(len(deposits)))
+
+		contractRoot := sha256.Sum256(append(node[:], count...))
+
+		root, err := ComputeDepositTreeRoot(cfg, deposits)
+		if err != nil {
+			t.Fatalf("failed to compute deposit tree root: %v", err)
+		}
+
+		if root != phase0.Root(contractRoot) {
+			t.Fatalf("deposit contract root 0x%x differs from deposit tree root 0x%x after %d deposits", contractRoot, root, len(deposits))
+		}
+
+		deposits = append(deposits, &phase0.DepositData{
+			PublicKey:             phase0.BLSPubKey{byte(i)},
+			WithdrawalCredentials: make([]byte, 32),
+			Amount:                phase0.Gwei(32_000_000_000 + i),
+		})
+	}
+}
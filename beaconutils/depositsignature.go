@@ -0,0 +1,102 @@
+package beaconutils
+
+import (
+	"fmt"
+
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+	blsu "github.com/protolambda/bls12-381-util"
+
+	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
+)
+
+// ComputeDomain returns the signature domain for a domain type, fork version
+// and genesis validators root (compute_domain).
+func ComputeDomain(domainType phase0.DomainType, forkVersion phase0.Version, genesisValidatorsRoot phase0.Root) (phase0.Domain, error) {
+	forkData := &phase0.ForkData{
+		CurrentVersion:        forkVersion,
+		GenesisValidatorsRoot: genesisValidatorsRoot,
+	}
+
+	forkDataRoot, err := forkData.HashTreeRoot()
+	if err != nil {
+		return phase0.Domain{}, err
+	}
+
+	var domain phase0.Domain
+
+	copy(domain[:4], domainType[:])
+	copy(domain[4:], forkDataRoot[:28])
+
+	return domain, nil
+}
+
+// IsValidDepositSignature checks the proof of possession of a deposit, which
+// is signed with the genesis fork version (is_valid_deposit_signature).
+func IsValidDepositSignature(cfg *beaconconfig.Config, pubkey phase0.BLSPubKey, withdrawalCredentials []byte, amount phase0.Gwei, signature phase0.BLSSignature) bool {
+	signingRoot, err := depositSigningRoot(cfg, pubkey, withdrawalCredentials, amount)
+	if err != nil {
+		return false
+	}
+
+	var blsPubkey blsu.Pubkey
+	if err := blsPubkey.Deserialize((*[48]byte)(pubkey[:])); err != nil {
+		return false
+	}
+
+	var blsSignature blsu.Signature
+	if err := blsSignature.Deserialize((*[96]byte)(signature[:])); err != nil {
+		return false
+	}
+
+	return blsu.Verify(&blsPubkey, signingRoot[:], &blsSignature)
+}
+
+// SignDeposit returns the proof of possession of a deposit, signed with the
+// secret key of pubkey.
+func SignDeposit(cfg *beaconconfig.Config, secretKey []byte, pubkey phase0.BLSPubKey, withdrawalCredentials []byte, amount phase0.Gwei) (phase0.BLSSignature, error) {
+	if len(secretKey) != 32 {
+		return phase0.BLSSignature{}, fmt.Errorf("invalid secret key length %d", len(secretKey))
+	}
+
+	var blsSecretKey blsu.SecretKey
+	if err := blsSecretKey.Deserialize((*[32]byte)(secretKey)); err != nil {
+		return phase0.BLSSignature{}, fmt.Errorf("failed to decode secret key: %w", err)
+	}
+
+	signingRoot, err := depositSigningRoot(cfg, pubkey, withdrawalCredentials, amount)
+	if err != nil {
+		return phase0.BLSSignature{}, err
+	}
+
+	return phase0.BLSSignature(blsu.Sign(&blsSecretKey, signingRoot[:]).Serialize()), nil
+}
+
+// depositSigningRoot returns the signing root of a deposit message in the
+// deposit domain of the genesis fork version.
+func depositSigningRoot(cfg *beaconconfig.Config, pubkey phase0.BLSPubKey, withdrawalCredentials []byte, amount phase0.Gwei) (phase0.Root, error) {
+	depositMessage := &phase0.DepositMessage{
+		PublicKey:             pubkey,
+		WithdrawalCredentials: withdrawalCredentials,
+		Amount:                amount,
+	}
+
+	messageRoot, err := depositMessage.HashTreeRoot()
+	if err != nil {
+		return phase0.Root{}, fmt.Errorf("failed to compute deposit message root: %w", err)
+	}
+
+	domainDeposit := cfg.GetBytesDefault("DOMAIN_DEPOSIT", []byte{0x03, 0x00, 0x00, 0x00})
+	genesisForkVersion := cfg.GetBytesDefault("GENESIS_FORK_VERSION", []byte{0x00, 0x00, 0x00, 0x00})
+
+	domain, err := ComputeDomain(phase0.DomainType(domainDeposit), phase0.Version(genesisForkVersion), phase0.Root{})
+	if err != nil {
+		return phase0.Root{}, fmt.Errorf("failed to compute deposit domain: %w", err)
+	}
+
+	signingData := &phase0.SigningData{
+		ObjectRoot: messageRoot,
+		Domain:     domain,
+	}
+
+	return signingData.HashTreeRoot()
+}
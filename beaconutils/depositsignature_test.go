@@ -0,0 +1,86 @@
+package beaconutils
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+	blsu "github.com/protolambda/bls12-381-util"
+)
+
+func TestComputeDomain(t *testing.T) {
+	// mainnet deposit domain
+	domain, err := ComputeDomain(phase0.DomainType{0x03, 0x00, 0x00, 0x00}, phase0.Version{}, phase0.Root{})
+	if err != nil {
+		t.Fatalf("failed to compute domain: %v", err)
+	}
+
+	expected := "03000000f5a5fd42d16a20302798ef6ed309979b43003d2320d9f0e8ea9831a9"
+	if hex.EncodeToString(domain[:]) != expected {
+		t.Fatalf("expected domain 0x%s, got 0x%x", expected, domain[:])
+	}
+}
+
+func TestIsValidDepositSignature(t *testing.T) {
+	cfg := createTestConfig(t, "mainnet", map[string]interface{}{
+		"GENESIS_FORK_VERSION": []byte{0x10, 0x00, 0x00, 0x38},
+	})
+
+	var secretKey blsu.SecretKey
+	if err := secretKey.Deserialize(&[32]byte{31: 0x2a}); err != nil {
+		t.Fatalf("failed to deserialize secret key: %v", err)
+	}
+
+	blsPubkey, err := blsu.SkToPk(&secretKey)
+	if err != nil {
+		t.Fatalf("failed to derive pubkey: %v", err)
+	}
+
+	pubkey := phase0.BLSPubKey(blsPubkey.Serialize())
+	withdrawalCredentials := make([]byte, 32)
+	withdrawalCredentials[0] = 0x03
+	amount := phase0.Gwei(32_000_000_000)
+
+	sign := func(forkVersion phase0.Version) phase0.BLSSignature {
+		messageRoot, err := (&phase0.DepositMessage{
+			PublicKey:             pubkey,
+			WithdrawalCredentials: withdrawalCredentials,
+			Amount:                amount,
+		}).HashTreeRoot()
+		if err != nil {
+			t.Fatalf("failed to compute deposit message root: %v", err)
+		}
+
+		domain, err := ComputeDomain(phase0.DomainType{0x03, 0x00, 0x00, 0x00}, forkVersion, phase0.Root{})
+		if err != nil {
+			t.Fatalf("failed to compute domain: %v", err)
+		}
+
+		signingRoot, err := (&phase0.SigningData{ObjectRoot: messageRoot, Domain: domain}).HashTreeRoot()
+		if err != nil {
+			t.Fatalf("failed to compute signing root: %v", err)
+		}
+
+		return phase0.BLSSignature(blsu.Sign(&secretKey, signingRoot[:]).Serialize())
+	}
+
+	signature := sign(phase0.Version{0x10, 0x00, 0x00, 0x38})
+
+	if !IsValidDepositSignature(cfg, pubkey, withdrawalCredentials, amount, signature) {
+		t.Fatalf("expected valid deposit signature")
+	}
+
+	if IsValidDepositSignature(cfg, pubkey, withdrawalCredentials, amount+1, signature) {
+		t.Fatalf("expected invalid signature for a different amount")
+	}
+
+	// deposits are signed with the genesis fork version, not the current one
+	if IsValidDepositSignature(cfg, pubkey, withdrawalCredentials, amount, sign(phase0.Version{0x20, 0x00, 0x00, 0x38})) {
+		t.Fatalf("expected invalid signature for a different fork version")
+	}
+
+	// the point at infinity used for deposits created by the electra upgrade
+	if IsValidDepositSignature(cfg, pubkey, withdrawalCredentials, amount, phase0.BLSSignature{0xc0}) {
+		t.Fatalf("expected invalid signature for the point at infinity")
+	}
+}
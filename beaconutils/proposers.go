@@ -16,38 +16,61 @@ func GetGenesisProposers(clConfig *beaconconfig.Config, validators []*phase0.Val
 	totalSlots := slotsPerEpoch * 2 // First 2 epochs
 
 	// Get active validator indices
-	activeIndices := []phase0.ValidatorIndex{}
-
-	for i, validator := range validators {
-		if validator.ActivationEpoch == 0 && validator.ExitEpoch > phase0.Epoch(0) { // Active at genesis
-			activeIndices = append(activeIndices, phase0.ValidatorIndex(i)) //nolint:gosec // no overflow
-		}
-	}
+	activeIndices := GetActiveValidatorIndices(validators, 0)
 
 	if len(activeIndices) == 0 {
 		return nil, fmt.Errorf("no active validators at genesis")
 	}
 
+	seedFn := genesisSeedFunc(genesisBlockHash)
+
 	// Calculate proposers for each slot
 	proposers := make([]phase0.ValidatorIndex, totalSlots)
 
 	for slot := uint64(0); slot < totalSlots; slot++ {
-		proposers[slot] = computeProposerIndex(clConfig, validators, activeIndices, phase0.Slot(slot), genesisBlockHash)
+		proposers[slot] = computeProposerIndex(clConfig, validators, activeIndices, phase0.Slot(slot), seedFn)
+	}
+
+	return proposers, nil
+}
+
+// GetProposerLookahead returns the proposer indices of the current and the
+// next MIN_SEED_LOOKAHEAD epochs of a state (initialize_proposer_lookahead).
+func GetProposerLookahead(clConfig *beaconconfig.Config, validators []*phase0.Validator, randaoMixes []phase0.Root, currentEpoch phase0.Epoch) ([]phase0.ValidatorIndex, error) {
+	slotsPerEpoch := clConfig.GetUintDefault("SLOTS_PER_EPOCH", 32)
+	minSeedLookahead := clConfig.GetUintDefault("MIN_SEED_LOOKAHEAD", 1)
+	seedFn := stateSeedFunc(clConfig, randaoMixes)
+
+	proposers := make([]phase0.ValidatorIndex, 0, (minSeedLookahead+1)*slotsPerEpoch)
+
+	for i := uint64(0); i <= minSeedLookahead; i++ {
+		epoch := currentEpoch + phase0.Epoch(i)
+
+		activeIndices := GetActiveValidatorIndices(validators, epoch)
+		if len(activeIndices) == 0 {
+			return nil, fmt.Errorf("no active validators at epoch %d", epoch)
+		}
+
+		startSlot := uint64(epoch) * slotsPerEpoch
+
+		for slot := startSlot; slot < startSlot+slotsPerEpoch; slot++ {
+			proposers = append(proposers, computeProposerIndex(clConfig, validators, activeIndices, phase0.Slot(slot), seedFn))
+		}
 	}
 
 	return proposers, nil
 }
 
 // computeProposerIndex calculates the proposer for a given slot
-func computeProposerIndex(clConfig *beaconconfig.Config, validators []*phase0.Validator, activeIndices []phase0.ValidatorIndex, slot phase0.Slot, genesisBlockHash phase0.Hash32) phase0.ValidatorIndex {
+func computeProposerIndex(clConfig *beaconconfig.Config, validators []*phase0.Validator, activeIndices []phase0.ValidatorIndex, slot phase0.Slot, seedFn seedFunc) phase0.ValidatorIndex {
 	slotsPerEpoch := clConfig.GetUintDefault("SLOTS_PER_EPOCH", 32)
 	epoch := phase0.Epoch(uint64(slot) / slotsPerEpoch)
 
 	// Get domain from config
 	domainBeaconProposer := clConfig.GetBytesDefault("DOMAIN_BEACON_PROPOSER", []byte{0x00, 0x00, 0x00, 0x00})
 
-	// Get seed for proposer selection using existing seed computation
-	seed := computeGenesisSeed(genesisBlockHash, epoch, phase0.DomainType(domainBeaconProposer))
+	// Get seed for proposer selection
+	seed := seedFn(epoch, phase0.DomainType(domainBeaconProposer))
 
 	// Create slot-specific seed
 	seedData := make([]byte, 40)
@@ -15,24 +15,26 @@ import (
 // entries are zero-filled (empty previous epoch), and the remaining entries contain PTC members
 // selected via balance-weighted selection from each slot's beacon committees.
 func GetGenesisPTCWindow(clConfig *beaconconfig.Config, validators []*phase0.Validator, genesisBlockHash phase0.Hash32) ([][]phase0.ValidatorIndex, error) {
+	if len(GetActiveValidatorIndices(validators, 0)) == 0 {
+		return nil, fmt.Errorf("no active validators at genesis")
+	}
+
+	return computePTCWindow(clConfig, validators, 0, genesisSeedFunc(genesisBlockHash))
+}
+
+// GetPTCWindow computes the PTC window of a state at currentEpoch (initialize_ptc_window),
+// using the seeds from the randao mixes of the state. The layout matches GetGenesisPTCWindow.
+func GetPTCWindow(clConfig *beaconconfig.Config, validators []*phase0.Validator, randaoMixes []phase0.Root, currentEpoch phase0.Epoch) ([][]phase0.ValidatorIndex, error) {
+	return computePTCWindow(clConfig, validators, currentEpoch, stateSeedFunc(clConfig, randaoMixes))
+}
+
+func computePTCWindow(clConfig *beaconconfig.Config, validators []*phase0.Validator, currentEpoch phase0.Epoch, seedFn seedFunc) ([][]phase0.ValidatorIndex, error) {
 	slotsPerEpoch := clConfig.GetUintDefault("SLOTS_PER_EPOCH", 32)
 	minSeedLookahead := clConfig.GetUintDefault("MIN_SEED_LOOKAHEAD", 1)
 	ptcSize := clConfig.GetUintDefault("PTC_SIZE", 512)
 
 	totalSlots := (2 + minSeedLookahead) * slotsPerEpoch
 
-	activeIndices := make([]phase0.ValidatorIndex, 0, len(validators))
-
-	for i, validator := range validators {
-		if validator.ActivationEpoch == 0 && validator.ExitEpoch > phase0.Epoch(0) {
-			activeIndices = append(activeIndices, phase0.ValidatorIndex(i)) //nolint:gosec,nolintlint // index within validator slice bounds
-		}
-	}
-
-	if len(activeIndices) == 0 {
-		return nil, fmt.Errorf("no active validators at genesis")
-	}
-
 	ptcWindow := make([][]phase0.ValidatorIndex, totalSlots)
 
 	// First SLOTS_PER_EPOCH entries are empty (previous epoch placeholder)
@@ -48,21 +50,27 @@ func GetGenesisPTCWindow(clConfig *beaconconfig.Config, validators []*phase0.Val
 	domainPTCAttester := clConfig.GetBytesDefault("DOMAIN_PTC_ATTESTER", []byte{0x0c, 0x00, 0x00, 0x00})
 	domainBeaconAttester := clConfig.GetBytesDefault("DOMAIN_BEACON_ATTESTER", []byte{0x01, 0x00, 0x00, 0x00})
 	maxEffectiveBalance := clConfig.GetUintDefault("MAX_EFFECTIVE_BALANCE_ELECTRA", 2_048_000_000_000)
-	committeesPerSlot := getCommitteeCountPerSlot(clConfig, uint64(len(activeIndices)))
 
 	// Compute PTC for current epoch and lookahead epochs
 	for e := uint64(0); e <= minSeedLookahead; e++ {
-		epoch := phase0.Epoch(e)
+		epoch := currentEpoch + phase0.Epoch(e)
+
+		activeIndices := GetActiveValidatorIndices(validators, epoch)
+		if len(activeIndices) == 0 {
+			return nil, fmt.Errorf("no active validators at epoch %d", epoch)
+		}
+
+		committeesPerSlot := getCommitteeCountPerSlot(clConfig, uint64(len(activeIndices)))
 
 		// Seed for beacon committees (determines which validators are assigned to which slot)
-		attesterSeed := computeGenesisSeed(genesisBlockHash, epoch, phase0.DomainType(domainBeaconAttester))
+		attesterSeed := seedFn(epoch, phase0.DomainType(domainBeaconAttester))
 
 		// Seed for PTC selection
-		ptcEpochSeed := computeGenesisSeed(genesisBlockHash, epoch, phase0.DomainType(domainPTCAttester))
+		ptcEpochSeed := seedFn(epoch, phase0.DomainType(domainPTCAttester))
 
 		for s := uint64(0); s < slotsPerEpoch; s++ {
-			slot := e*slotsPerEpoch + s
-			windowIndex := slotsPerEpoch + slot
+			slot := uint64(epoch)*slotsPerEpoch + s
+			windowIndex := slotsPerEpoch + e*slotsPerEpoch + s
 
 			// Get concatenated committee indices for this slot
 			slotCandidates := getSlotCommitteeIndices(
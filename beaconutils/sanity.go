@@ -0,0 +1,204 @@
+package beaconutils
+
+import (
+	"fmt"
+
+	"github.com/ethpandaops/go-eth2-client/spec"
+	"github.com/ethpandaops/go-eth2-client/spec/altair"
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+
+	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
+)
+
+// Severities of genesis sanity issues.
+const (
+	// SanityWarning marks issues that make the genesis unusual or invalid by
+	// spec, but still bootable.
+	SanityWarning = "warning"
+
+	// SanityError marks issues that prevent the chain from starting.
+	SanityError = "error"
+)
+
+// maxReportedIndices limits the validator indices listed per sanity issue.
+const maxReportedIndices = 10
+
+// GenesisSanityInput holds the parts of a genesis state checked by
+// CheckGenesisSanity. SyncCommittee is nil for phase0 states.
+type GenesisSanityInput struct {
+	Version       spec.DataVersion
+	GenesisTime   uint64
+	Validators    []*phase0.Validator
+	Balances      []phase0.Gwei
+	SyncCommittee *altair.SyncCommittee
+}
+
+// SanityIssue is a single finding of CheckGenesisSanity.
+type SanityIssue struct {
+	Check    string `yaml:"check" json:"check"`
+	Severity string `yaml:"severity" json:"severity"`
+	Message  string `yaml:"message" json:"message"`
+}
+
+// ShadowForkBlock identifies the execution block a shadow fork genesis is
+// built on. RPC is the host of the RPC without credentials, File the path or
+// URL of the block file.
+type ShadowForkBlock struct {
+	RPC      string `yaml:"rpc,omitempty" json:"rpc,omitempty"`
+	Selector string `yaml:"selector,omitempty" json:"selector,omitempty"`
+	File     string `yaml:"file,omitempty" json:"file,omitempty"`
+	Number   uint64 `yaml:"number" json:"number"`
+	Hash     string `yaml:"hash" json:"hash"`
+}
+
+// SanityReport is the result of CheckGenesisSanity.
+type SanityReport struct {
+	Version              string           `yaml:"version" json:"version"`
+	GenesisTime          uint64           `yaml:"genesis_time" json:"genesis_time"`
+	Validators           uint64           `yaml:"validators" json:"validators"`
+	ActiveValidators     uint64           `yaml:"active_validators" json:"active_validators"`
+	TotalBalance         uint64           `yaml:"total_balance" json:"total_balance"`
+	ActiveBalance        uint64           `yaml:"active_balance" json:"active_balance"`
+	SyncCommitteeMembers uint64           `yaml:"sync_committee_members,omitempty" json:"sync_committee_members,omitempty"`
+	ShadowFork           *ShadowForkBlock `yaml:"shadow_fork,omitempty" json:"shadow_fork,omitempty"`
+	Issues               []*SanityIssue   `yaml:"issues" json:"issues"`
+}
+
+func (r *SanityReport) addIssue(check, severity, format string, args ...any) {
+	r.Issues = append(r.Issues, &SanityIssue{
+		Check:    check,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Failures returns the issues that fail the check: errors, and warnings too
+// if strict is set.
+func (r *SanityReport) Failures(strict bool) []*SanityIssue {
+	failures := []*SanityIssue{}
+
+	for _, issue := range r.Issues {
+		if issue.Severity == SanityError || strict {
+			failures = append(failures, issue)
+		}
+	}
+
+	return failures
+}
+
+// CheckGenesisSanity runs the is_valid_genesis_state checks of the spec
+// (MIN_GENESIS_TIME and MIN_GENESIS_ACTIVE_VALIDATOR_COUNT) and additional
+// sanity checks on a genesis state. The spec checks are reported as warnings,
+// as devnets commonly start with fewer validators than the config requires.
+func CheckGenesisSanity(cfg *beaconconfig.Config, input *GenesisSanityInput) *SanityReport {
+	report := &SanityReport{
+		Version:     input.Version.String(),
+		GenesisTime: input.GenesisTime,
+		Validators:  uint64(len(input.Validators)),
+		Issues:      []*SanityIssue{},
+	}
+
+	activationThreshold := phase0.Gwei(cfg.GetUintDefault("MAX_EFFECTIVE_BALANCE", 32_000_000_000))
+	activePubkeys := make(map[phase0.BLSPubKey]bool, len(input.Validators))
+	belowThreshold := []int{}
+
+	for idx, val := range input.Validators {
+		if idx < len(input.Balances) {
+			report.TotalBalance += uint64(input.Balances[idx])
+		}
+
+		if val.ActivationEpoch == 0 && val.ExitEpoch > 0 {
+			report.ActiveValidators++
+			report.ActiveBalance += uint64(val.EffectiveBalance)
+			activePubkeys[val.PublicKey] = true
+		} else if val.ExitEpoch > 0 && val.EffectiveBalance < activationThreshold {
+			belowThreshold = append(belowThreshold, idx)
+		}
+	}
+
+	if len(input.Balances) != len(input.Validators) {
+		report.addIssue("balances", SanityError, "state has %d balances for %d validators", len(input.Balances), len(input.Validators))
+	}
+
+	// is_valid_genesis_state
+	if minGenesisTime := cfg.GetUintDefault("MIN_GENESIS_TIME", 0); input.GenesisTime < minGenesisTime {
+		report.addIssue("min_genesis_time", SanityWarning, "genesis time %d is before MIN_GENESIS_TIME %d", input.GenesisTime, minGenesisTime)
+	}
+
+	if minActive := cfg.GetUintDefault("MIN_GENESIS_ACTIVE_VALIDATOR_COUNT", 0); report.ActiveValidators < minActive {
+		report.addIssue("min_genesis_active_validator_count", SanityWarning, "%d active validators, MIN_GENESIS_ACTIVE_VALIDATOR_COUNT is %d", report.ActiveValidators, minActive)
+	}
+
+	// Fulu and later states precompute the proposer lookahead, which already
+	// fails without active validators while building the state
+	if report.ActiveValidators == 0 {
+		severity := SanityError
+		if input.Version >= spec.DataVersionFulu {
+			severity = SanityWarning
+		}
+
+		report.addIssue("active_validators", severity, "no active validators at genesis")
+	}
+
+	if len(belowThreshold) > 0 {
+		report.addIssue("activation_threshold", SanityWarning, "%d validators are pending because their effective balance is below %d gwei (indices %s)",
+			len(belowThreshold), activationThreshold, formatIndices(belowThreshold))
+	}
+
+	if input.SyncCommittee != nil && report.ActiveValidators > 0 {
+		checkSyncCommittee(cfg, input.SyncCommittee, activePubkeys, report)
+	}
+
+	return report
+}
+
+// checkSyncCommittee checks that the genesis sync committee only contains
+// active validators and is not dominated by a few of them. Sync committee
+// members are sampled by effective balance, so validators with a much higher
+// balance than the others can fill a large share of the committee.
+func checkSyncCommittee(cfg *beaconconfig.Config, syncCommittee *altair.SyncCommittee, activePubkeys map[phase0.BLSPubKey]bool, report *SanityReport) {
+	members := make(map[phase0.BLSPubKey]bool, len(syncCommittee.Pubkeys))
+	inactive := 0
+
+	for _, pubkey := range syncCommittee.Pubkeys {
+		if !activePubkeys[pubkey] {
+			inactive++
+		}
+
+		members[pubkey] = true
+	}
+
+	report.SyncCommitteeMembers = uint64(len(members))
+
+	if inactive > 0 {
+		report.addIssue("sync_committee", SanityError, "%d sync committee seats are held by validators that are not active", inactive)
+	}
+
+	syncCommitteeSize := cfg.GetUintDefault("SYNC_COMMITTEE_SIZE", 512)
+
+	expected := min(report.ActiveValidators, syncCommitteeSize)
+	if report.SyncCommitteeMembers*2 < expected {
+		report.addIssue("sync_committee_diversity", SanityWarning, "sync committee has %d distinct members out of %d seats and %d active validators",
+			report.SyncCommitteeMembers, syncCommitteeSize, report.ActiveValidators)
+	}
+}
+
+func formatIndices(indices []int) string {
+	str := ""
+
+	for i, idx := range indices {
+		if i == maxReportedIndices {
+			str += fmt.Sprintf(", ... %d more", len(indices)-i)
+
+			break
+		}
+
+		if i > 0 {
+			str += ", "
+		}
+
+		str += fmt.Sprintf("%d", idx)
+	}
+
+	return str
+}
@@ -0,0 +1,166 @@
+package beaconutils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethpandaops/go-eth2-client/spec"
+	"github.com/ethpandaops/go-eth2-client/spec/altair"
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+)
+
+func makeSanityValidators(count int, activationEpoch phase0.Epoch, effectiveBalance phase0.Gwei) ([]*phase0.Validator, []phase0.Gwei) {
+	vals := make([]*phase0.Validator, count)
+	balances := make([]phase0.Gwei, count)
+
+	for i := range vals {
+		vals[i] = &phase0.Validator{
+			PublicKey:        phase0.BLSPubKey{byte(i), byte(i >> 8)},
+			EffectiveBalance: effectiveBalance,
+			ActivationEpoch:  activationEpoch,
+			ExitEpoch:        phase0.Epoch(18446744073709551615),
+		}
+		balances[i] = effectiveBalance
+	}
+
+	return vals, balances
+}
+
+func findSanityIssue(report *SanityReport, check string) *SanityIssue {
+	for _, issue := range report.Issues {
+		if issue.Check == check {
+			return issue
+		}
+	}
+
+	return nil
+}
+
+func TestCheckGenesisSanity_Valid(t *testing.T) {
+	cfg := createTestConfig(t, "minimal", map[string]interface{}{
+		"MIN_GENESIS_TIME":                   uint64(1000),
+		"MIN_GENESIS_ACTIVE_VALIDATOR_COUNT": uint64(64),
+		"SYNC_COMMITTEE_SIZE":                uint64(32),
+	})
+
+	vals, balances := makeSanityValidators(64, 0, 32_000_000_000)
+
+	syncCommittee := &altair.SyncCommittee{Pubkeys: make([]phase0.BLSPubKey, 32)}
+	for i := range syncCommittee.Pubkeys {
+		syncCommittee.Pubkeys[i] = vals[i].PublicKey
+	}
+
+	report := CheckGenesisSanity(cfg, &GenesisSanityInput{
+		Version:       spec.DataVersionDeneb,
+		GenesisTime:   1000,
+		Validators:    vals,
+		Balances:      balances,
+		SyncCommittee: syncCommittee,
+	})
+
+	if len(report.Issues) != 0 {
+		t.Fatalf("expected no issues, got %d (first: %s)", len(report.Issues), report.Issues[0].Message)
+	}
+
+	if report.ActiveValidators != 64 || report.ActiveBalance != 64*32_000_000_000 || report.TotalBalance != 64*32_000_000_000 {
+		t.Fatalf("unexpected totals: %d active, %d active balance, %d total balance", report.ActiveValidators, report.ActiveBalance, report.TotalBalance)
+	}
+
+	if report.SyncCommitteeMembers != 32 {
+		t.Fatalf("expected 32 sync committee members, got %d", report.SyncCommitteeMembers)
+	}
+}
+
+func TestCheckGenesisSanity_Issues(t *testing.T) {
+	cfg := createTestConfig(t, "minimal", map[string]interface{}{
+		"MIN_GENESIS_TIME":                   uint64(2000),
+		"MIN_GENESIS_ACTIVE_VALIDATOR_COUNT": uint64(64),
+		"SYNC_COMMITTEE_SIZE":                uint64(32),
+	})
+
+	vals, balances := makeSanityValidators(16, 0, 32_000_000_000)
+	pending, pendingBalances := makeSanityValidators(12, phase0.Epoch(18446744073709551615), 16_000_000_000)
+
+	for i, val := range pending {
+		val.PublicKey[2] = 0xff
+		val.PublicKey[3] = byte(i)
+	}
+
+	vals = append(vals, pending...)
+	balances = append(balances, pendingBalances...)
+
+	// all seats held by 2 validators, one of them pending
+	syncCommittee := &altair.SyncCommittee{Pubkeys: make([]phase0.BLSPubKey, 32)}
+	for i := range syncCommittee.Pubkeys {
+		syncCommittee.Pubkeys[i] = vals[0].PublicKey
+	}
+
+	syncCommittee.Pubkeys[31] = pending[0].PublicKey
+
+	report := CheckGenesisSanity(cfg, &GenesisSanityInput{
+		Version:       spec.DataVersionDeneb,
+		GenesisTime:   1000,
+		Validators:    vals,
+		Balances:      balances,
+		SyncCommittee: syncCommittee,
+	})
+
+	tests := []struct {
+		check    string
+		severity string
+		message  string
+	}{
+		{"min_genesis_time", SanityWarning, "genesis time 1000 is before MIN_GENESIS_TIME 2000"},
+		{"min_genesis_active_validator_count", SanityWarning, "16 active validators, MIN_GENESIS_ACTIVE_VALIDATOR_COUNT is 64"},
+		{"activation_threshold", SanityWarning, "12 validators are pending"},
+		{"activation_threshold", SanityWarning, "indices 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, ... 2 more"},
+		{"sync_committee", SanityError, "1 sync committee seats"},
+		{"sync_committee_diversity", SanityWarning, "2 distinct members"},
+	}
+
+	for _, test := range tests {
+		issue := findSanityIssue(report, test.check)
+		if issue == nil {
+			t.Fatalf("expected issue %s", test.check)
+		}
+
+		if issue.Severity != test.severity || !strings.Contains(issue.Message, test.message) {
+			t.Fatalf("unexpected %s issue: %s: %s", test.check, issue.Severity, issue.Message)
+		}
+	}
+
+	if failures := report.Failures(false); len(failures) != 1 {
+		t.Fatalf("expected 1 failure, got %d", len(failures))
+	}
+
+	if failures := report.Failures(true); len(failures) != len(report.Issues) {
+		t.Fatalf("expected all %d issues to fail in strict mode, got %d", len(report.Issues), len(failures))
+	}
+}
+
+func TestCheckGenesisSanity_NoActiveValidators(t *testing.T) {
+	cfg := createTestConfig(t, "minimal", map[string]interface{}{})
+	vals, balances := makeSanityValidators(4, phase0.Epoch(18446744073709551615), 32_000_000_000)
+
+	tests := []struct {
+		version  spec.DataVersion
+		severity string
+	}{
+		{spec.DataVersionPhase0, SanityError},
+		{spec.DataVersionElectra, SanityError},
+		{spec.DataVersionFulu, SanityWarning},
+	}
+
+	for _, test := range tests {
+		report := CheckGenesisSanity(cfg, &GenesisSanityInput{
+			Version:    test.version,
+			Validators: vals,
+			Balances:   balances,
+		})
+
+		issue := findSanityIssue(report, "active_validators")
+		if issue == nil || issue.Severity != test.severity {
+			t.Fatalf("expected active_validators %s for %s, got %v", test.severity, test.version, issue)
+		}
+	}
+}
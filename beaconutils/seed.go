@@ -0,0 +1,56 @@
+package beaconutils
+
+import (
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+
+	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
+)
+
+// GetActiveValidatorIndices returns the indices of the validators that are
+// active at epoch (get_active_validator_indices).
+func GetActiveValidatorIndices(validators []*phase0.Validator, epoch phase0.Epoch) []phase0.ValidatorIndex {
+	activeIndices := make([]phase0.ValidatorIndex, 0, len(validators))
+
+	for index, validator := range validators {
+		if validator.ActivationEpoch <= epoch && epoch < validator.ExitEpoch {
+			activeIndices = append(activeIndices, phase0.ValidatorIndex(index)) //nolint:gosec // no overflow
+		}
+	}
+
+	return activeIndices
+}
+
+// GetSeed returns the seed for epoch and domainType from the randao mixes of a
+// state (get_seed). Unlike the genesis helpers, it picks the randao mix of the
+// epoch MIN_SEED_LOOKAHEAD + 1 epochs back, so it works for states of any epoch.
+func GetSeed(cfg *beaconconfig.Config, randaoMixes []phase0.Root, epoch phase0.Epoch, domainType phase0.DomainType) phase0.Root {
+	epochsPerHistoricalVector := cfg.GetUintDefault("EPOCHS_PER_HISTORICAL_VECTOR", 65536)
+	minSeedLookahead := cfg.GetUintDefault("MIN_SEED_LOOKAHEAD", 1)
+
+	mixIndex := (uint64(epoch) + epochsPerHistoricalVector - minSeedLookahead - 1) % epochsPerHistoricalVector
+
+	var mix phase0.Hash32
+	if mixIndex < uint64(len(randaoMixes)) {
+		mix = phase0.Hash32(randaoMixes[mixIndex])
+	}
+
+	return computeGenesisSeed(mix, epoch, domainType)
+}
+
+// seedFunc returns the seed of an epoch for a domain type.
+type seedFunc func(epoch phase0.Epoch, domainType phase0.DomainType) phase0.Root
+
+// genesisSeedFunc returns the seeds of a genesis state, which has the genesis
+// block hash as randao mix for all epochs.
+func genesisSeedFunc(randaoMix phase0.Hash32) seedFunc {
+	return func(epoch phase0.Epoch, domainType phase0.DomainType) phase0.Root {
+		return computeGenesisSeed(randaoMix, epoch, domainType)
+	}
+}
+
+// stateSeedFunc returns the seeds of a state with the given randao mixes.
+func stateSeedFunc(cfg *beaconconfig.Config, randaoMixes []phase0.Root) seedFunc {
+	return func(epoch phase0.Epoch, domainType phase0.DomainType) phase0.Root {
+		return GetSeed(cfg, randaoMixes, epoch, domainType)
+	}
+}
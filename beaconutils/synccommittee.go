@@ -13,20 +13,37 @@ import (
 )
 
 func GetGenesisSyncCommittee(cfg *beaconconfig.Config, validators []*phase0.Validator, randaoMix phase0.Hash32) (*altair.SyncCommittee, error) {
-	activeIndices := make([]phase0.ValidatorIndex, 0, len(validators))
-
-	for index, validator := range validators {
-		if validator.ActivationEpoch == 0 && validator.ExitEpoch > phase0.Epoch(0) {
-			activeIndices = append(activeIndices, phase0.ValidatorIndex(index)) //nolint:gosec // no overflow
-		}
+	electraActive := false
+	if electraActivationEpoch, ok := cfg.GetUint("ELECTRA_FORK_EPOCH"); ok && electraActivationEpoch == 0 {
+		electraActive = true
 	}
 
+	seed := computeGenesisSeed(randaoMix, 0, syncCommitteeDomain(cfg))
+
+	return computeSyncCommittee(cfg, validators, GetActiveValidatorIndices(validators, 0), seed, electraActive)
+}
+
+// GetNextSyncCommittee returns the sync committee of the period after the
+// current epoch of a state (get_next_sync_committee). electraActive selects
+// the 16 bit balance sampling of electra and later forks.
+func GetNextSyncCommittee(cfg *beaconconfig.Config, validators []*phase0.Validator, randaoMixes []phase0.Root, currentEpoch phase0.Epoch, electraActive bool) (*altair.SyncCommittee, error) {
+	epoch := currentEpoch + 1
+	seed := GetSeed(cfg, randaoMixes, epoch, syncCommitteeDomain(cfg))
+
+	return computeSyncCommittee(cfg, validators, GetActiveValidatorIndices(validators, epoch), seed, electraActive)
+}
+
+func syncCommitteeDomain(cfg *beaconconfig.Config) phase0.DomainType {
+	return phase0.DomainType(cfg.GetBytesDefault("DOMAIN_SYNC_COMMITTEE", []byte{0x07, 0x00, 0x00, 0x00}))
+}
+
+func computeSyncCommittee(cfg *beaconconfig.Config, validators []*phase0.Validator, activeIndices []phase0.ValidatorIndex, periodSeed phase0.Root, electraActive bool) (*altair.SyncCommittee, error) {
 	var committeeIndices []phase0.ValidatorIndex
 
-	if electraActivationEpoch, ok := cfg.GetUint("ELECTRA_FORK_EPOCH"); ok && electraActivationEpoch == 0 {
-		committeeIndices = computeGenesisSyncCommitteeIndicesElectra(cfg, activeIndices, validators, randaoMix)
+	if electraActive {
+		committeeIndices = computeSyncCommitteeIndicesElectra(cfg, activeIndices, validators, periodSeed)
 	} else {
-		committeeIndices = computeGenesisSyncCommitteeIndices(cfg, activeIndices, validators, randaoMix)
+		committeeIndices = computeSyncCommitteeIndices(cfg, activeIndices, validators, periodSeed)
 	}
 
 	syncCommittee := &altair.SyncCommittee{
@@ -61,13 +78,11 @@ func GetGenesisSyncCommittee(cfg *beaconconfig.Config, validators []*phase0.Vali
 // for the next sync committee, given a state at a sync committee period boundary.
 //
 // Note: Committee can contain duplicate indices for small validator sets (< SYNC_COMMITTEE_SIZE + 128)
-func computeGenesisSyncCommitteeIndices(cfg *beaconconfig.Config, active []phase0.ValidatorIndex, validators []*phase0.Validator, randaoMix phase0.Hash32) []phase0.ValidatorIndex {
+func computeSyncCommitteeIndices(cfg *beaconconfig.Config, active []phase0.ValidatorIndex, validators []*phase0.Validator, periodSeed phase0.Root) []phase0.ValidatorIndex {
 	syncCommitteeSize := cfg.GetUintDefault("SYNC_COMMITTEE_SIZE", 512)
 	shuffleRoundCount := cfg.GetUintDefault("SHUFFLE_ROUND_COUNT", 90)
 	maxEffectiveBalance := cfg.GetUintDefault("MAX_EFFECTIVE_BALANCE", 32000000000)
-	domainSyncCommittee := cfg.GetBytesDefault("DOMAIN_SYNC_COMMITTEE", []byte{0x07, 0x00, 0x00, 0x00})
 	syncCommitteeIndices := make([]phase0.ValidatorIndex, 0, syncCommitteeSize)
-	periodSeed := computeGenesisSeed(randaoMix, 0, phase0.DomainType(domainSyncCommittee))
 
 	if len(active) == 0 {
 		return syncCommitteeIndices
@@ -110,13 +125,11 @@ func computeGenesisSyncCommitteeIndices(cfg *beaconconfig.Config, active []phase
 	return syncCommitteeIndices
 }
 
-func computeGenesisSyncCommitteeIndicesElectra(cfg *beaconconfig.Config, active []phase0.ValidatorIndex, validators []*phase0.Validator, randaoMix phase0.Hash32) []phase0.ValidatorIndex {
+func computeSyncCommitteeIndicesElectra(cfg *beaconconfig.Config, active []phase0.ValidatorIndex, validators []*phase0.Validator, periodSeed phase0.Root) []phase0.ValidatorIndex {
 	syncCommitteeSize := cfg.GetUintDefault("SYNC_COMMITTEE_SIZE", 512)
 	shuffleRoundCount := cfg.GetUintDefault("SHUFFLE_ROUND_COUNT", 90)
 	maxEffectiveBalance := cfg.GetUintDefault("MAX_EFFECTIVE_BALANCE", 32000000000)
-	domainSyncCommittee := cfg.GetBytesDefault("DOMAIN_SYNC_COMMITTEE", []byte{0x07, 0x00, 0x00, 0x00})
 	syncCommitteeIndices := make([]phase0.ValidatorIndex, 0, syncCommitteeSize)
-	periodSeed := computeGenesisSeed(randaoMix, 0, phase0.DomainType(domainSyncCommittee))
 
 	if len(active) == 0 {
 		return syncCommitteeIndices
@@ -1,7 +1,6 @@
 package beaconutils
 
 import (
-	"github.com/ethpandaops/go-eth2-client/spec/bellatrix"
 	"github.com/ethpandaops/go-eth2-client/spec/gloas"
 	"github.com/ethpandaops/go-eth2-client/spec/phase0"
 	"github.com/pk910/dynamic-ssz/sszutils"
@@ -10,21 +9,6 @@ import (
 	"github.com/ethpandaops/eth-beacon-genesis/validators"
 )
 
-func SeparateBuildersFromValidators(vals []*validators.Validator) (builders, validatorList []*validators.Validator) {
-	builders = make([]*validators.Validator, 0, len(vals))
-	validatorList = make([]*validators.Validator, 0, len(vals))
-
-	for _, val := range vals {
-		if val.WithdrawalCredentials[0] == 0x03 {
-			builders = append(builders, val)
-		} else {
-			validatorList = append(validatorList, val)
-		}
-	}
-
-	return builders, validatorList
-}
-
 func GetGenesisValidators(cfg *beaconconfig.Config, vals []*validators.Validator) ([]*phase0.Validator, phase0.Root) {
 	// Process activations
 	maxEffectiveBalance := phase0.Gwei(cfg.GetUintDefault("MAX_EFFECTIVE_BALANCE", 32_000_000_000))
@@ -90,9 +74,19 @@ func GetGenesisValidators(cfg *beaconconfig.Config, vals []*validators.Validator
 		clValidators = append(clValidators, validator)
 	}
 
+	validatorsRoot, err := GetValidatorsRoot(cfg, clValidators)
+	if err != nil {
+		return nil, phase0.Root{}
+	}
+
+	return clValidators, validatorsRoot
+}
+
+// GetValidatorsRoot returns the hash tree root of a validator registry.
+func GetValidatorsRoot(cfg *beaconconfig.Config, clValidators []*phase0.Validator) (phase0.Root, error) {
 	maxValidators := cfg.GetUintDefault("VALIDATOR_REGISTRY_LIMIT", 1099511627776)
 
-	validatorsRoot, err := HashWithFastSSZHasher(func(hh sszutils.HashWalker) error {
+	return HashWithFastSSZHasher(func(hh sszutils.HashWalker) error {
 		for _, elem := range clValidators {
 			if err := elem.HashTreeRootWith(hh); err != nil {
 				return err
@@ -103,11 +97,6 @@ func GetGenesisValidators(cfg *beaconconfig.Config, vals []*validators.Validator
 
 		return nil
 	})
-	if err != nil {
-		return nil, phase0.Root{}
-	}
-
-	return clValidators, validatorsRoot
 }
 
 func GetGenesisBalances(cfg *beaconconfig.Config, vals []*validators.Validator) []phase0.Gwei {
@@ -125,34 +114,34 @@ func GetGenesisBalances(cfg *beaconconfig.Config, vals []*validators.Validator)
 	return balances
 }
 
-func GetGenesisBuilders(cfg *beaconconfig.Config, vals []*validators.Validator) []*gloas.Builder {
-	builders := make([]*gloas.Builder, 0, len(vals))
+// GetGenesisBuilders returns the genesis builder registry. Builders without an
+// explicit balance get MAX_EFFECTIVE_BALANCE, builders without an explicit
+// withdrawable epoch get FAR_FUTURE_EPOCH.
+func GetGenesisBuilders(cfg *beaconconfig.Config, builders []*validators.Builder) []*gloas.Builder {
+	clBuilders := make([]*gloas.Builder, 0, len(builders))
 	defaultBalance := phase0.Gwei(cfg.GetUintDefault("MAX_EFFECTIVE_BALANCE", 32_000_000_000))
-
-	for _, val := range vals {
-		var executionAddress bellatrix.ExecutionAddress
-		copy(executionAddress[:], val.WithdrawalCredentials[12:32])
-
-		builder := &gloas.Builder{
-			PublicKey:         val.PublicKey,
-			Version:           val.WithdrawalCredentials[0],
-			ExecutionAddress:  executionAddress,
-			DepositEpoch:      0,
-			WithdrawableEpoch: phase0.Epoch(cfg.GetUintDefault("FAR_FUTURE_EPOCH", 18446744073709551615)),
+	farFutureEpoch := phase0.Epoch(cfg.GetUintDefault("FAR_FUTURE_EPOCH", 18446744073709551615))
+
+	for _, builder := range builders {
+		clBuilder := &gloas.Builder{
+			PublicKey:         builder.PublicKey,
+			Version:           builder.Version,
+			ExecutionAddress:  builder.ExecutionAddress,
+			Balance:           defaultBalance,
+			DepositEpoch:      phase0.Epoch(builder.DepositEpoch),
+			WithdrawableEpoch: farFutureEpoch,
 		}
 
-		if val.Balance != nil {
-			builder.Balance = phase0.Gwei(*val.Balance)
-		} else {
-			builder.Balance = defaultBalance
+		if builder.Balance != nil {
+			clBuilder.Balance = phase0.Gwei(*builder.Balance)
 		}
 
-		if val.Status == validators.ValidatorStatusExited {
-			builder.WithdrawableEpoch = phase0.Epoch(0)
+		if builder.WithdrawableEpoch != nil {
+			clBuilder.WithdrawableEpoch = phase0.Epoch(*builder.WithdrawableEpoch)
 		}
 
-		builders = append(builders, builder)
+		clBuilders = append(clBuilders, clBuilder)
 	}
 
-	return builders
+	return clBuilders
 }
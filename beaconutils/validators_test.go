@@ -196,6 +196,45 @@ func TestGetGenesisBalances(t *testing.T) {
 	}
 }
 
+func TestGetGenesisBuilders(t *testing.T) {
+	cfg := createTestConfig(t, "minimal", map[string]interface{}{
+		"MAX_EFFECTIVE_BALANCE": uint64(32_000_000_000),
+		"FAR_FUTURE_EPOCH":      uint64(18446744073709551615),
+	})
+
+	builders := []*validators.Builder{
+		{
+			PublicKey:        phase0.BLSPubKey(makeBytes(48, 1)),
+			ExecutionAddress: [20]byte{0x11},
+			Version:          0x03,
+		},
+		{
+			PublicKey:         phase0.BLSPubKey(makeBytes(48, 2)),
+			ExecutionAddress:  [20]byte{0x22},
+			Balance:           ptr(uint64(100_000_000_000)),
+			Version:           0x04,
+			DepositEpoch:      5,
+			WithdrawableEpoch: ptr(uint64(10)),
+		},
+	}
+
+	clBuilders := GetGenesisBuilders(cfg, builders)
+
+	if len(clBuilders) != 2 {
+		t.Fatalf("wrong number of builders: got %v, want 2", len(clBuilders))
+	}
+
+	if clBuilders[0].Balance != 32_000_000_000 || clBuilders[0].WithdrawableEpoch != 18446744073709551615 ||
+		clBuilders[0].DepositEpoch != 0 || clBuilders[0].Version != 0x03 || clBuilders[0].ExecutionAddress[0] != 0x11 {
+		t.Errorf("unexpected default builder: %+v", clBuilders[0])
+	}
+
+	if clBuilders[1].Balance != 100_000_000_000 || clBuilders[1].WithdrawableEpoch != 10 ||
+		clBuilders[1].DepositEpoch != 5 || clBuilders[1].Version != 0x04 || clBuilders[1].ExecutionAddress[0] != 0x22 {
+		t.Errorf("unexpected explicit builder: %+v", clBuilders[1])
+	}
+}
+
 // Helper function to create pointer to uint64
 func ptr(v uint64) *uint64 {
 	return &v
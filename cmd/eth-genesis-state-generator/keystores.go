@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v3"
+
+	"github.com/ethpandaops/eth-beacon-genesis/keystores"
+	"github.com/ethpandaops/eth-beacon-genesis/validators"
+)
+
+var (
+	keystoresOutputDirFlag = &cli.StringFlag{
+		Name:     "output-dir",
+		Usage:    "Directory to write the keystores to (must not exist or be empty)",
+		Required: true,
+	}
+	keystoresSourceFlag = &cli.StringFlag{
+		Name:  "source",
+		Usage: "Only write keystores for the mnemonic source with this name (mnemonic-<n> for unnamed sources)",
+	}
+	keystoresRangeFlag = &cli.StringFlag{
+		Name:  "range",
+		Usage: "Only write keystores for key indices in this inclusive range (<from>-<to>)",
+	}
+	keystoresNodeAssignmentFlag = &cli.StringFlag{
+		Name:  "node-assignment",
+		Usage: "Path to a node assignment file written by beaconchain --node-assignment-output (requires --node)",
+	}
+	keystoresNodeFlag = &cli.StringFlag{
+		Name:  "node",
+		Usage: "Only write keystores for the validators assigned to this node in the node assignment",
+	}
+	keystoresPasswordFlag = &cli.StringFlag{
+		Name:  "keystore-password",
+		Usage: "Password for all keystores (a random password is generated per keystore if not set)",
+	}
+	keystoresInsecureFlag = &cli.BoolFlag{
+		Name:  "insecure",
+		Usage: "Use a very low PBKDF2 iteration count to speed up generation (for throw-away devnets only)",
+	}
+
+	web3SignerTypeFlag = &cli.StringFlag{
+		Name:  "type",
+		Usage: "Web3Signer key config type: file-raw (unencrypted private key) or file-keystore",
+		Value: keystores.Web3SignerFileRaw,
+	}
+	web3SignerBasePathFlag = &cli.StringFlag{
+		Name:  "base-path",
+		Usage: "Path under which Web3Signer sees the output directory, used for the keystore paths of file-keystore configs (defaults to the output directory)",
+	}
+
+	keystoresCommand = &cli.Command{
+		Name:  "keystores",
+		Usage: "Generate EIP-2335 keystores for the validators defined in a mnemonics file",
+		Flags: []cli.Flag{
+			mnemonicsFileFlag, keystoresOutputDirFlag, keystoresSourceFlag, keystoresRangeFlag,
+			keystoresNodeAssignmentFlag, keystoresNodeFlag, keystoresPasswordFlag, keystoresInsecureFlag,
+			quietFlag,
+		},
+		Action:    runKeystores,
+		UsageText: "eth-beacon-genesis keystores --mnemonics mnemonics.yaml --output-dir keys [options]",
+	}
+	web3SignerCommand = &cli.Command{
+		Name:  "web3signer",
+		Usage: "Generate Web3Signer key configs for the validators defined in a mnemonics file, grouped by source",
+		Flags: []cli.Flag{
+			mnemonicsFileFlag, keystoresOutputDirFlag, keystoresSourceFlag, keystoresRangeFlag,
+			keystoresNodeAssignmentFlag, keystoresNodeFlag, web3SignerTypeFlag, web3SignerBasePathFlag,
+			keystoresPasswordFlag, keystoresInsecureFlag, quietFlag,
+		},
+		Action:    runWeb3Signer,
+		UsageText: "eth-beacon-genesis web3signer --mnemonics mnemonics.yaml --output-dir web3signer-keys [options]",
+	}
+)
+
+func runKeystores(_ context.Context, cmd *cli.Command) error {
+	outputDir := cmd.String(keystoresOutputDirFlag.Name)
+
+	if cmd.Bool(quietFlag.Name) {
+		logrus.SetLevel(logrus.PanicLevel)
+	}
+
+	keys, err := deriveSelectedKeys(cmd)
+	if err != nil {
+		return err
+	}
+
+	opts := &keystores.Options{
+		Password: cmd.String(keystoresPasswordFlag.Name),
+	}
+
+	if cmd.Bool(keystoresInsecureFlag.Name) {
+		opts.PBKDF2Rounds = keystores.InsecurePBKDF2Rounds
+	}
+
+	if err := keystores.WriteKeystores(outputDir, keys, opts); err != nil {
+		return fmt.Errorf("failed to write keystores: %w", err)
+	}
+
+	logrus.Infof("wrote keystores to: %s", outputDir)
+
+	return nil
+}
+
+func runWeb3Signer(_ context.Context, cmd *cli.Command) error {
+	outputDir := cmd.String(keystoresOutputDirFlag.Name)
+
+	if cmd.Bool(quietFlag.Name) {
+		logrus.SetLevel(logrus.PanicLevel)
+	}
+
+	keys, err := deriveSelectedKeys(cmd)
+	if err != nil {
+		return err
+	}
+
+	opts := &keystores.Web3SignerOptions{
+		Type:     cmd.String(web3SignerTypeFlag.Name),
+		BasePath: cmd.String(web3SignerBasePathFlag.Name),
+		Password: cmd.String(keystoresPasswordFlag.Name),
+	}
+
+	if cmd.Bool(keystoresInsecureFlag.Name) {
+		opts.PBKDF2Rounds = keystores.InsecurePBKDF2Rounds
+	}
+
+	if err := keystores.WriteWeb3SignerConfigs(outputDir, keys, opts); err != nil {
+		return fmt.Errorf("failed to write web3signer key configs: %w", err)
+	}
+
+	logrus.Infof("wrote web3signer key configs to: %s", outputDir)
+
+	return nil
+}
+
+// deriveSelectedKeys derives the signing keys from the mnemonics file that
+// match the --source, --range and --node-assignment/--node flags.
+func deriveSelectedKeys(cmd *cli.Command) ([]*validators.SigningKey, error) {
+	mnemonicsFile := cmd.String(mnemonicsFileFlag.Name)
+	source := cmd.String(keystoresSourceFlag.Name)
+	keyRange := cmd.String(keystoresRangeFlag.Name)
+
+	if mnemonicsFile == "" {
+		return nil, fmt.Errorf("--%s is required", mnemonicsFileFlag.Name)
+	}
+
+	rangeFrom, rangeTo := uint64(0), ^uint64(0)
+
+	if keyRange != "" {
+		var err error
+
+		rangeFrom, rangeTo, err = parseKeyRange(keyRange)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	nodeSelector, err := loadNodeSelector(cmd.String(keystoresNodeAssignmentFlag.Name), cmd.String(keystoresNodeFlag.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := validators.DeriveSigningKeys(mnemonicsFile, func(keySource string, keyIndex uint64) bool {
+		if source != "" && keySource != source {
+			return false
+		}
+
+		if nodeSelector != nil && !nodeSelector(keySource, keyIndex) {
+			return false
+		}
+
+		return keyIndex >= rangeFrom && keyIndex <= rangeTo
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive keys from mnemonics file: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no keys selected")
+	}
+
+	logrus.Infof("derived %d signing keys", len(keys))
+
+	return keys, nil
+}
+
+// loadNodeSelector returns a selector for the keys assigned to node in the
+// node assignment file, or nil if no node assignment is used.
+func loadNodeSelector(assignmentFile, node string) (validators.KeySelector, error) {
+	if assignmentFile == "" && node == "" {
+		return nil, nil
+	}
+
+	if assignmentFile == "" || node == "" {
+		return nil, fmt.Errorf("--%s and --%s must be used together", keystoresNodeAssignmentFlag.Name, keystoresNodeFlag.Name)
+	}
+
+	assignments, err := validators.LoadAssignmentFile(assignmentFile)
+	if err != nil {
+		return nil, err
+	}
+
+	assignment, err := validators.FindNodeAssignment(assignments, node)
+	if err != nil {
+		return nil, err
+	}
+
+	return assignment.KeySelector(), nil
+}
+
+// parseKeyRange parses an inclusive key index range in the form <from>-<to>.
+func parseKeyRange(value string) (from, to uint64, err error) {
+	fromStr, toStr, found := strings.Cut(value, "-")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid range %q: expected <from>-<to>", value)
+	}
+
+	from, err = strconv.ParseUint(strings.TrimSpace(fromStr), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: %w", value, err)
+	}
+
+	to, err = strconv.ParseUint(strings.TrimSpace(toStr), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: %w", value, err)
+	}
+
+	if to < from {
+		return 0, 0, fmt.Errorf("invalid range %q: end is before start", value)
+	}
+
+	return from, to, nil
+}
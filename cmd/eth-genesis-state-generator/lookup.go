@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+	"github.com/urfave/cli/v3"
+
+	"github.com/ethpandaops/eth-beacon-genesis/beaconchain"
+	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
+	"github.com/ethpandaops/eth-beacon-genesis/validators"
+)
+
+var (
+	lookupMappingFlag = &cli.StringFlag{
+		Name:     "mapping",
+		Usage:    "Path to the validator mapping written by beaconchain --validators-mapping-output (yaml format)",
+		Required: true,
+	}
+	lookupIndexFlag = &cli.Uint64Flag{
+		Name:  "index",
+		Usage: "State validator index to resolve to its source and key index",
+	}
+	lookupSourceFlag = &cli.StringFlag{
+		Name:  "source",
+		Usage: "Source of the key to resolve to its state validator index (requires --key-index)",
+	}
+	lookupKeyIndexFlag = &cli.Uint64Flag{
+		Name:  "key-index",
+		Usage: "Key index within --source to resolve to its state validator index",
+	}
+	lookupPubkeyFlag = &cli.StringFlag{
+		Name:  "pubkey",
+		Usage: "Validator pubkey to resolve (requires --genesis-state and --config)",
+	}
+	lookupGenesisStateFlag = &cli.StringFlag{
+		Name:  "genesis-state",
+		Usage: "Path to the genesis state (SSZ, or JSON with .json extension) to resolve pubkeys",
+	}
+	lookupConfigFlag = &cli.StringFlag{
+		Name:  "config",
+		Usage: "Path to consensus genesis config (config.yaml), required with --genesis-state",
+	}
+
+	lookupCommand = &cli.Command{
+		Name:  "lookup",
+		Usage: "Resolve validators between state indices, source key indices and pubkeys using the validator mapping",
+		Flags: []cli.Flag{
+			lookupMappingFlag, lookupIndexFlag, lookupSourceFlag, lookupKeyIndexFlag,
+			lookupPubkeyFlag, lookupGenesisStateFlag, lookupConfigFlag,
+		},
+		Action:    runLookup,
+		UsageText: "eth-beacon-genesis lookup --mapping mapping.yaml (--index <n> | --source <name> --key-index <n> | --pubkey <0x..> --genesis-state genesis.ssz --config config.yaml)",
+	}
+)
+
+func runLookup(_ context.Context, cmd *cli.Command) error {
+	byIndex := cmd.IsSet(lookupIndexFlag.Name)
+	byKey := cmd.IsSet(lookupSourceFlag.Name) || cmd.IsSet(lookupKeyIndexFlag.Name)
+	byPubkey := cmd.IsSet(lookupPubkeyFlag.Name)
+
+	if countTrue(byIndex, byKey, byPubkey) != 1 {
+		return fmt.Errorf("exactly one of --%s, --%s/--%s or --%s is required",
+			lookupIndexFlag.Name, lookupSourceFlag.Name, lookupKeyIndexFlag.Name, lookupPubkeyFlag.Name)
+	}
+
+	if byKey && (!cmd.IsSet(lookupSourceFlag.Name) || !cmd.IsSet(lookupKeyIndexFlag.Name)) {
+		return fmt.Errorf("--%s and --%s must be used together", lookupSourceFlag.Name, lookupKeyIndexFlag.Name)
+	}
+
+	entries, err := validators.LoadMappingFile(cmd.String(lookupMappingFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	var stateValidators []*phase0.Validator
+
+	if genesisStateFile := cmd.String(lookupGenesisStateFlag.Name); genesisStateFile != "" {
+		stateValidators, err = loadStateValidators(genesisStateFile, cmd.String(lookupConfigFlag.Name))
+		if err != nil {
+			return err
+		}
+	} else if byPubkey {
+		return fmt.Errorf("--%s requires --%s", lookupPubkeyFlag.Name, lookupGenesisStateFlag.Name)
+	}
+
+	var stateIndex uint64
+
+	switch {
+	case byIndex:
+		stateIndex = cmd.Uint64(lookupIndexFlag.Name)
+	case byKey:
+		source := cmd.String(lookupSourceFlag.Name)
+		keyIndex := cmd.Uint64(lookupKeyIndexFlag.Name)
+
+		var found bool
+
+		stateIndex, found = validators.LookupKeyIndex(entries, source, keyIndex)
+		if !found {
+			return fmt.Errorf("key index %d of source %q not found in validator mapping", keyIndex, source)
+		}
+	case byPubkey:
+		pubkey, err := validators.ParsePubkey(cmd.String(lookupPubkeyFlag.Name))
+		if err != nil {
+			return fmt.Errorf("invalid pubkey: %w", err)
+		}
+
+		found := false
+
+		for idx, val := range stateValidators {
+			if val.PublicKey == pubkey {
+				stateIndex = uint64(idx) //nolint:gosec // idx is a slice index, always >= 0
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("pubkey %s not found in genesis state", pubkey.String())
+		}
+	}
+
+	source, keyIndex, found := validators.LookupStateIndex(entries, stateIndex)
+	if !found {
+		return fmt.Errorf("state index %d not found in validator mapping", stateIndex)
+	}
+
+	fmt.Printf("state index: %d\n", stateIndex)
+	fmt.Printf("source:      %s\n", source)
+	fmt.Printf("key index:   %d\n", keyIndex)
+
+	if stateValidators != nil {
+		if stateIndex >= uint64(len(stateValidators)) {
+			return fmt.Errorf("state index %d not found in genesis state (%d validators)", stateIndex, len(stateValidators))
+		}
+
+		fmt.Printf("pubkey:      %s\n", stateValidators[stateIndex].PublicKey.String())
+	}
+
+	return nil
+}
+
+func loadStateValidators(genesisStateFile, configFile string) ([]*phase0.Validator, error) {
+	if configFile == "" {
+		return nil, fmt.Errorf("--%s requires --%s", lookupGenesisStateFlag.Name, lookupConfigFlag.Name)
+	}
+
+	clConfig, err := beaconconfig.LoadConfig(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load consensus config: %w", err)
+	}
+
+	state, err := beaconchain.LoadStateFromFile(genesisStateFile, clConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load genesis state: %w", err)
+	}
+
+	stateValidators, _, err := beaconchain.GetStateValidators(state)
+	if err != nil {
+		return nil, err
+	}
+
+	return stateValidators, nil
+}
+
+func countTrue(values ...bool) int {
+	count := 0
+
+	for _, value := range values {
+		if value {
+			count++
+		}
+	}
+
+	return count
+}
@@ -2,29 +2,48 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethpandaops/go-eth2-client/http"
+	"github.com/ethpandaops/go-eth2-client/spec"
 	"github.com/ethpandaops/go-eth2-client/spec/phase0"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v3"
 
 	"github.com/ethpandaops/eth-beacon-genesis/beaconchain"
 	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
+	"github.com/ethpandaops/eth-beacon-genesis/beaconutils"
 	"github.com/ethpandaops/eth-beacon-genesis/buildinfo"
 	"github.com/ethpandaops/eth-beacon-genesis/eth1"
 	"github.com/ethpandaops/eth-beacon-genesis/validators"
 )
 
+// shadowForkRPCRetryDelay is the pause before retrying a failed request to
+// the shadow fork RPC.
+const shadowForkRPCRetryDelay = 2 * time.Second
+
 var (
 	eth1ConfigFlag = &cli.StringFlag{
 		Name:     "eth1-config",
 		Usage:    "Path to execution genesis config (genesis.json)",
 		Required: true,
 	}
+	eth1ConfigFormatFlag = &cli.StringFlag{
+		Name:  "eth1-config-format",
+		Usage: "Format of --eth1-config: auto, geth, besu or nethermind (chainspec)",
+		Value: eth1.GenesisFormatAuto,
+	}
+	eth1GenesisHeaderFlag = &cli.StringFlag{
+		Name:  "eth1-genesis-header",
+		Usage: "Path to the precomputed execution genesis header (header JSON, eth_getBlockByNumber response or RLP block) to take the state root from instead of computing it from the alloc",
+	}
 	configFlag = &cli.StringFlag{
 		Name:     "config",
 		Usage:    "Path to consensus genesis config (config.yaml)",
@@ -34,9 +53,67 @@ var (
 		Name:  "mnemonics",
 		Usage: "Path to the file containing the mnemonics for genesis validators",
 	}
+	keyCacheDirFlag = &cli.StringFlag{
+		Name:  "key-cache-dir",
+		Usage: "Directory to cache keys derived from mnemonics in, so unchanged ranges are not derived again on the next run",
+	}
 	validatorsFileFlag = &cli.StringFlag{
 		Name:  "additional-validators",
-		Usage: "Path to the file with a list of additional genesis validators validators",
+		Usage: "Path to the file with a list of additional genesis validators (plain text, or .yaml/.json/.csv)",
+	}
+	importStateFlag = &cli.StringFlag{
+		Name:  "import-state",
+		Usage: "Path to a beacon state (SSZ, or JSON with .json extension) to import the validator registry from, e.g. for shadow forks",
+	}
+	importStateConfigFlag = &cli.StringFlag{
+		Name:  "import-state-config",
+		Usage: "Path to the consensus config of the network of --import-state (defaults to --config)",
+	}
+	importStateActiveOnlyFlag = &cli.BoolFlag{
+		Name:  "import-state-active-only",
+		Usage: "Only import validators that are active at the epoch of --import-state",
+	}
+	importStateRangeFlag = &cli.StringFlag{
+		Name:  "import-state-range",
+		Usage: "Inclusive validator index range of --import-state to import in the form <from>-<to>",
+	}
+	importStateCredentialsFlag = &cli.StringSliceFlag{
+		Name:  "import-state-credentials",
+		Usage: "Withdrawal credential type of the validators to import from --import-state (e.g. 0x01), can be repeated",
+	}
+	depositDataFlag = &cli.StringSliceFlag{
+		Name:  "deposit-data",
+		Usage: "Path to a deposit data file (deposit_data-*.json) with signed deposits of genesis validators, can be repeated",
+	}
+	depositTreeFlag = &cli.BoolFlag{
+		Name:  "deposit-tree",
+		Usage: "Build the deposit tree from the signed deposits of the genesis validators (deposits of mnemonic validators are signed)",
+	}
+	preMergeFlag = &cli.BoolFlag{
+		Name:  "pre-merge",
+		Usage: "Build a pre-merge bellatrix genesis state with an empty execution payload header, the merge happens at TERMINAL_TOTAL_DIFFICULTY",
+	}
+	eth1ConfigOutputFlag = &cli.StringFlag{
+		Name:  "eth1-config-output",
+		Usage: "Path to write the execution genesis config to, with the deposit contract storage of the genesis deposits (with --deposit-tree) and the inserted system contracts",
+	}
+	systemContractsFlag = &cli.StringFlag{
+		Name:  "system-contracts",
+		Usage: "How to handle the system contracts of the genesis fork in the execution genesis alloc: check (warn about missing or different contracts), strict (fail on them), insert (missing ones, requires --eth1-config-output) or skip",
+		Value: eth1.SystemContractsCheck,
+	}
+	duplicatePolicyFlag = &cli.StringFlag{
+		Name:  "duplicate-policy",
+		Usage: "How to handle pubkeys repeated within or across validator sources: error, keep-first or keep-last",
+		Value: validators.DuplicatePolicyError,
+	}
+	duplicateReportFlag = &cli.StringFlag{
+		Name:  "duplicate-report",
+		Usage: "Path to write a YAML report of all repeated pubkeys with their sources and key indices to",
+	}
+	buildersFileFlag = &cli.StringFlag{
+		Name:  "builders",
+		Usage: "Path to the YAML file with the genesis builders (explicit pubkeys or mnemonic ranges, gloas genesis only)",
 	}
 	shadowForkBlockFlag = &cli.StringFlag{
 		Name:  "shadow-fork-block",
@@ -46,6 +123,25 @@ var (
 		Name:  "shadow-fork-rpc",
 		Usage: "Execution RPC URL to fetch the block to create a shadow fork from",
 	}
+	shadowForkRPCBlockFlag = &cli.StringFlag{
+		Name:  "shadow-fork-rpc-block",
+		Usage: "Block to fetch from --shadow-fork-rpc: a block number, block hash or tag (latest, safe or finalized)",
+		Value: "latest",
+	}
+	shadowForkRPCTimeoutFlag = &cli.DurationFlag{
+		Name:  "shadow-fork-rpc-timeout",
+		Usage: "Timeout of every request to --shadow-fork-rpc",
+		Value: 30 * time.Second,
+	}
+	shadowForkRPCRetriesFlag = &cli.IntFlag{
+		Name:  "shadow-fork-rpc-retries",
+		Usage: "Number of retries of failed requests to --shadow-fork-rpc",
+		Value: 3,
+	}
+	shadowForkRPCHeaderFlag = &cli.StringSliceFlag{
+		Name:  "shadow-fork-rpc-header",
+		Usage: "HTTP header to send to --shadow-fork-rpc as \"Name: value\" (e.g. an auth token), can be repeated",
+	}
 	stateOutputFlag = &cli.StringFlag{
 		Name:  "state-output",
 		Usage: "Path to the file to write the genesis state to in SSZ format",
@@ -56,17 +152,61 @@ var (
 	}
 	shuffleValidatorsFlag = &cli.BoolFlag{
 		Name:  "shuffle-validators",
-		Usage: "Shuffle the validator set block-wise to add variance to the validator ordering",
+		Usage: "Shuffle the validator set to add variance to the validator ordering (block-wise unless --shuffle-mode is set)",
 	}
 	shuffleSeedFlag = &cli.Uint64Flag{
 		Name:  "shuffle-seed",
-		Usage: "Seed for the block-wise validator shuffle (defaults to the genesis fork version; only used with --shuffle-validators)",
+		Usage: "Seed for the validator shuffle (defaults to the genesis fork version; only used with --shuffle-validators)",
+	}
+	shuffleModeFlag = &cli.StringFlag{
+		Name:  "shuffle-mode",
+		Usage: "Validator shuffle mode: block (reorder contiguous blocks), full (per validator) or round-robin (interleave sources); implies --shuffle-validators",
+		Value: validators.ShuffleModeBlock,
+	}
+	shuffleBlockSizeFlag = &cli.IntFlag{
+		Name:  "shuffle-block-size",
+		Usage: "Explicit block size for the block shuffle mode (defaults to a size scaled with the validator count); implies --shuffle-validators",
 	}
 	validatorsMappingOutputFlag = &cli.StringFlag{
 		Name:  "validators-mapping-output",
-		Usage: "Path to write the validator mapping (state index ranges to source key ranges) in YAML format",
+		Usage: "Path to write the validator mapping to (see --validators-mapping-format)",
+	}
+	buildersMappingOutputFlag = &cli.StringFlag{
+		Name:  "builders-mapping-output",
+		Usage: "Path to write the builder mapping to (builder registry indices, same format as the validator mapping)",
+	}
+	validatorsMappingFormatFlag = &cli.StringFlag{
+		Name:  "validators-mapping-format",
+		Usage: "Format of the validator mapping: yaml (state index ranges), json or csv (one row per validator with pubkey, withdrawal credentials, balance and status)",
+		Value: validators.MappingFormatYAML,
+	}
+	nodePlanFlag = &cli.StringFlag{
+		Name:  "node-plan",
+		Usage: "Path to the node plan (node names with a validator count or weight) to split the validator set across nodes",
+	}
+	nodeAssignmentOutputFlag = &cli.StringFlag{
+		Name:  "node-assignment-output",
+		Usage: "Path to write the node assignment (state index ranges, source ranges and pubkeys per node) in YAML format",
+	}
+
+	constructionFlag = &cli.StringFlag{
+		Name:  "construction",
+		Usage: "How to construct the genesis state: direct (fork specific genesis) or upgrade-chain (phase0 genesis with the fork upgrades applied)",
+		Value: beaconchain.ConstructionDirect,
+	}
+	compareConstructionFlag = &cli.BoolFlag{
+		Name:  "compare-construction",
+		Usage: "Also build the genesis state with the other construction mode and log the state fields that differ",
 	}
 
+	strictFlag = &cli.BoolFlag{
+		Name:  "strict",
+		Usage: "Fail if any genesis sanity check reports a warning (errors always fail)",
+	}
+	sanityReportFlag = &cli.StringFlag{
+		Name:  "sanity-report",
+		Usage: "Path to write the genesis sanity report to (YAML)",
+	}
 	quietFlag = &cli.BoolFlag{
 		Name:    "quiet",
 		Aliases: []string{"q"},
@@ -82,14 +222,23 @@ var (
 				Usage:   "Generate a beaconchain genesis state",
 				Aliases: []string{"bc", "beacon", "devnet"},
 				Flags: []cli.Flag{
-					eth1ConfigFlag, configFlag, mnemonicsFileFlag, validatorsFileFlag,
-					shadowForkBlockFlag, shadowForkRPCFlag, stateOutputFlag, jsonOutputFlag,
-					shuffleValidatorsFlag, shuffleSeedFlag, validatorsMappingOutputFlag,
-					quietFlag,
+					eth1ConfigFlag, eth1ConfigFormatFlag, eth1GenesisHeaderFlag, configFlag, mnemonicsFileFlag, keyCacheDirFlag, validatorsFileFlag, buildersFileFlag,
+					importStateFlag, importStateConfigFlag, importStateActiveOnlyFlag, importStateRangeFlag, importStateCredentialsFlag,
+					depositDataFlag, depositTreeFlag, preMergeFlag, eth1ConfigOutputFlag, systemContractsFlag, duplicatePolicyFlag, duplicateReportFlag,
+					shadowForkBlockFlag, shadowForkRPCFlag, shadowForkRPCBlockFlag, shadowForkRPCTimeoutFlag,
+					shadowForkRPCRetriesFlag, shadowForkRPCHeaderFlag, stateOutputFlag, jsonOutputFlag,
+					shuffleValidatorsFlag, shuffleSeedFlag, shuffleModeFlag, shuffleBlockSizeFlag,
+					validatorsMappingOutputFlag, validatorsMappingFormatFlag, buildersMappingOutputFlag,
+					nodePlanFlag, nodeAssignmentOutputFlag, constructionFlag, compareConstructionFlag,
+					strictFlag, sanityReportFlag, quietFlag,
 				},
 				Action:    runDevnet,
 				UsageText: "eth-beacon-genesis beaconchain [options]",
 			},
+			keystoresCommand,
+			web3SignerCommand,
+			lookupCommand,
+			upgradeStateCommand,
 			{
 				Name:  "version",
 				Usage: "Print the version of the application",
@@ -115,14 +264,35 @@ func runDevnet(ctx context.Context, cmd *cli.Command) error {
 	eth1Config := cmd.String(eth1ConfigFlag.Name)
 	eth2Config := cmd.String(configFlag.Name)
 	mnemonicsFile := cmd.String(mnemonicsFileFlag.Name)
+	keyCacheDir := cmd.String(keyCacheDirFlag.Name)
 	validatorsFile := cmd.String(validatorsFileFlag.Name)
+	buildersFile := cmd.String(buildersFileFlag.Name)
+	importState := cmd.String(importStateFlag.Name)
+	depositDataFiles := cmd.StringSlice(depositDataFlag.Name)
+	depositTree := cmd.Bool(depositTreeFlag.Name)
+	preMerge := cmd.Bool(preMergeFlag.Name)
+	eth1ConfigOutput := cmd.String(eth1ConfigOutputFlag.Name)
+	systemContracts := cmd.String(systemContractsFlag.Name)
+	duplicatePolicy := cmd.String(duplicatePolicyFlag.Name)
+	duplicateReport := cmd.String(duplicateReportFlag.Name)
 	shadowForkBlock := cmd.String(shadowForkBlockFlag.Name)
 	shadowForkRPC := cmd.String(shadowForkRPCFlag.Name)
+	eth1GenesisHeader := cmd.String(eth1GenesisHeaderFlag.Name)
 	stateOutputFile := cmd.String(stateOutputFlag.Name)
 	jsonOutputFile := cmd.String(jsonOutputFlag.Name)
 	shuffleValidators := cmd.Bool(shuffleValidatorsFlag.Name)
 	shuffleSeed := cmd.Uint64(shuffleSeedFlag.Name)
+	shuffleMode := cmd.String(shuffleModeFlag.Name)
+	shuffleBlockSize := cmd.Int(shuffleBlockSizeFlag.Name)
 	validatorsMappingOutput := cmd.String(validatorsMappingOutputFlag.Name)
+	validatorsMappingFormat := cmd.String(validatorsMappingFormatFlag.Name)
+	buildersMappingOutput := cmd.String(buildersMappingOutputFlag.Name)
+	nodePlanFile := cmd.String(nodePlanFlag.Name)
+	nodeAssignmentOutput := cmd.String(nodeAssignmentOutputFlag.Name)
+	construction := cmd.String(constructionFlag.Name)
+	compareConstruction := cmd.Bool(compareConstructionFlag.Name)
+	strict := cmd.Bool(strictFlag.Name)
+	sanityReport := cmd.String(sanityReportFlag.Name)
 	quiet := cmd.Bool(quietFlag.Name)
 
 	if quiet {
@@ -133,7 +303,7 @@ func runDevnet(ctx context.Context, cmd *cli.Command) error {
 		logrus.Infof("eth-beacon-genesis version: %s", buildinfo.GetBuildVersion())
 	}
 
-	elGenesis, err := eth1.LoadEth1GenesisConfig(eth1Config)
+	elGenesis, err := eth1.LoadEth1GenesisConfigFormat(eth1Config, cmd.String(eth1ConfigFormatFlag.Name))
 	if err != nil {
 		return fmt.Errorf("failed to load execution genesis: %w", err)
 	}
@@ -147,38 +317,147 @@ func runDevnet(ctx context.Context, cmd *cli.Command) error {
 
 	logrus.Infof("loaded consensus config. genesis fork version: 0x%x", clConfig.GetBytesDefault("GENESIS_FORK_VERSION", []byte{}))
 
-	var clValidators []*validators.Validator
+	var (
+		clValidators []*validators.Validator
+		clBuilders   []*validators.Builder
+		duplicates   []*validators.DuplicateConflict
+		keyCache     *validators.KeyCache
+	)
+
+	if keyCacheDir != "" {
+		keyCache, err = validators.NewKeyCache(keyCacheDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	if importState == "" {
+		for _, flag := range []string{importStateConfigFlag.Name, importStateActiveOnlyFlag.Name, importStateRangeFlag.Name, importStateCredentialsFlag.Name} {
+			if cmd.IsSet(flag) {
+				return fmt.Errorf("--%s requires --%s", flag, importStateFlag.Name)
+			}
+		}
+	}
+
+	// imported validators come first, so they keep their registry indices
+	// unless some are filtered out
+	if importState != "" {
+		vals, err2 := importStateValidators(cmd, clConfig)
+		if err2 != nil {
+			return err2
+		}
+
+		clValidators = vals
+	}
 
 	if mnemonicsFile != "" {
-		vals, err2 := validators.GenerateValidatorsByMnemonic(mnemonicsFile)
+		vals, err2 := validators.GenerateValidatorsByMnemonicWithCache(mnemonicsFile, keyCache)
 		if err2 != nil {
 			return fmt.Errorf("failed to load validators from mnemonics file: %w", err2)
 		}
 
-		if len(vals) > 0 {
-			clValidators = vals
-		}
+		clValidators = append(clValidators, vals...)
 	}
 
 	if validatorsFile != "" {
-		vals, err2 := validators.LoadValidatorsFromFile(validatorsFile)
-		if err2 != nil {
+		vals, conflicts, err2 := validators.LoadValidatorsFromFileWithPolicy(validatorsFile, duplicatePolicy)
+		if err2 != nil && !errors.Is(err2, validators.ErrDuplicatePubkey) {
 			return fmt.Errorf("failed to load validators from file: %w", err2)
 		}
 
+		duplicates = append(duplicates, conflicts...)
+
 		if len(vals) > 0 {
 			clValidators = append(clValidators, vals...)
 		}
 	}
 
-	if len(clValidators) == 0 {
+	for _, depositDataFile := range depositDataFiles {
+		vals, err2 := validators.LoadDepositDataFile(depositDataFile)
+		if err2 != nil {
+			return fmt.Errorf("failed to load validators from deposit data file: %w", err2)
+		}
+
+		logrus.Infof("loaded %d deposits from deposit data file: %s", len(vals), depositDataFile)
+
+		clValidators = append(clValidators, vals...)
+	}
+
+	// resolve pubkeys repeated across sources, the error policy is applied
+	// after the report is written so it lists every conflict
+	clValidators, conflicts, err := validators.ResolveDuplicates(clValidators, duplicatePolicy)
+	if err != nil && !errors.Is(err, validators.ErrDuplicatePubkey) {
+		return err
+	}
+
+	duplicates = append(duplicates, conflicts...)
+
+	if duplicateReport != "" {
+		if err := validators.WriteDuplicateReport(duplicateReport, duplicates); err != nil {
+			return err
+		}
+
+		logrus.Infof("wrote duplicate report to: %s", duplicateReport)
+	}
+
+	if len(duplicates) > 0 {
+		if duplicatePolicy == "" || duplicatePolicy == validators.DuplicatePolicyError {
+			return validators.DuplicatesError(duplicates)
+		}
+
+		logrus.Warnf("dropped %d duplicate validators (%s)", countDropped(duplicates), duplicatePolicy)
+	}
+
+	if beaconchain.GetGenesisForkVersion(clConfig) >= spec.DataVersionGloas {
+		if buildersFile != "" {
+			builders, err2 := validators.LoadBuildersFromFile(buildersFile, keyCache)
+			if err2 != nil {
+				return fmt.Errorf("failed to load builders from file: %w", err2)
+			}
+
+			clBuilders = builders
+		}
+	} else if buildersFile != "" {
+		return fmt.Errorf("--%s requires a gloas genesis", buildersFileFlag.Name)
+	}
+
+	if shuffleValidators || cmd.IsSet(shuffleModeFlag.Name) || cmd.IsSet(shuffleBlockSizeFlag.Name) {
+		if !cmd.IsSet(shuffleSeedFlag.Name) {
+			shuffleSeed = validators.SeedFromForkVersion(clConfig.GetBytesDefault("GENESIS_FORK_VERSION", []byte{}))
+		}
+
+		if err := validators.ShuffleValidatorsByMode(clValidators, shuffleMode, shuffleSeed, shuffleBlockSize); err != nil {
+			return fmt.Errorf("failed to shuffle validators: %w", err)
+		}
+
+		logrus.Infof("shuffled validator set (mode: %s, seed: %d)", shuffleMode, shuffleSeed)
+	}
+
+	builder, err := newStateBuilder(elGenesis, clConfig, construction, clValidators, clBuilders)
+	if err != nil {
+		return err
+	}
+
+	// the state builder moves validators with builder credentials to the
+	// builder registry, so they must not take up a validator index in the
+	// mapping, node assignment or deposit tree
+	genesisVals, genesisBuilders := clValidators, clBuilders
+	if registry, ok := builder.(beaconchain.BuilderRegistry); ok {
+		genesisVals, genesisBuilders = registry.GetGenesisRegistry()
+	}
+
+	if validatorBuilders := len(genesisBuilders) - len(clBuilders); validatorBuilders > 0 {
+		logrus.Warnf("adding %d validators with builder (0x03) withdrawal credentials as builders, use --%s to define builders explicitly", validatorBuilders, buildersFileFlag.Name)
+	}
+
+	if len(genesisVals) == 0 {
 		return fmt.Errorf("no validators found")
 	}
 
 	defaultBalance := clConfig.GetUintDefault("MAX_EFFECTIVE_BALANCE", 32_000_000_000)
 	totalBalance := uint64(0)
 
-	for _, val := range clValidators {
+	for _, val := range genesisVals {
 		if val.Balance != nil {
 			totalBalance += *val.Balance
 		} else {
@@ -186,42 +465,83 @@ func runDevnet(ctx context.Context, cmd *cli.Command) error {
 		}
 	}
 
-	// check for duplicate public keys
-	pubkeyMap := make(map[phase0.BLSPubKey]bool)
+	logrus.Infof("loaded %d validators. total balance: %d ETH", len(genesisVals), totalBalance/1_000_000_000)
 
-	for idx, val := range clValidators {
-		if pubkeyMap[val.PublicKey] {
-			return fmt.Errorf("duplicate public key in validator set: %s at index %d", val.PublicKey.String(), idx)
+	if len(genesisBuilders) > 0 {
+		// builders must not reuse validator pubkeys
+		pubkeyMap := make(map[phase0.BLSPubKey]bool, len(genesisVals)+len(genesisBuilders))
+		for _, val := range genesisVals {
+			pubkeyMap[val.PublicKey] = true
 		}
 
-		pubkeyMap[val.PublicKey] = true
-	}
+		builderBalance := uint64(0)
 
-	logrus.Infof("loaded %d validators. total balance: %d ETH", len(clValidators), totalBalance/1_000_000_000)
+		for idx, val := range genesisBuilders {
+			if pubkeyMap[val.PublicKey] {
+				return fmt.Errorf("duplicate public key in builder set: %s at builder index %d", val.PublicKey.String(), idx)
+			}
 
-	if shuffleValidators {
-		if !cmd.IsSet(shuffleSeedFlag.Name) {
-			shuffleSeed = validators.SeedFromForkVersion(clConfig.GetBytesDefault("GENESIS_FORK_VERSION", []byte{}))
+			pubkeyMap[val.PublicKey] = true
+
+			if val.Balance != nil {
+				builderBalance += *val.Balance
+			} else {
+				builderBalance += defaultBalance
+			}
 		}
 
-		validators.ShuffleValidators(clValidators, shuffleSeed)
-		logrus.Infof("shuffled validator set block-wise (seed: %d)", shuffleSeed)
+		logrus.Infof("loaded %d builders. total balance: %d ETH", len(genesisBuilders), builderBalance/1_000_000_000)
 	}
 
-	if validatorsMappingOutput != "" {
-		if err := validators.WriteMappingFile(validatorsMappingOutput, clValidators); err != nil {
-			return fmt.Errorf("failed to write validator mapping: %w", err)
+	if buildersMappingOutput != "" {
+		farFutureEpoch := clConfig.GetUintDefault("FAR_FUTURE_EPOCH", 18446744073709551615)
+		if err := validators.WriteBuilderMappingFileFormat(buildersMappingOutput, validatorsMappingFormat, genesisBuilders, defaultBalance, farFutureEpoch); err != nil {
+			return fmt.Errorf("failed to write builder mapping: %w", err)
 		}
 
-		logrus.Infof("wrote validator mapping to: %s", validatorsMappingOutput)
+		logrus.Infof("wrote builder mapping to: %s", buildersMappingOutput)
 	}
 
-	builder := beaconchain.NewGenesisBuilder(elGenesis, clConfig)
-	builder.AddValidators(clValidators)
+	if nodeAssignmentOutput != "" && nodePlanFile == "" {
+		return fmt.Errorf("--%s requires --%s", nodeAssignmentOutputFlag.Name, nodePlanFlag.Name)
+	}
 
-	if shadowForkBlock != "" || shadowForkRPC != "" {
-		var gensisBlock *types.Block
+	if nodePlanFile != "" {
+		nodePlan, err2 := validators.LoadNodePlan(nodePlanFile)
+		if err2 != nil {
+			return err2
+		}
+
+		assignments, err2 := validators.AssignNodes(genesisVals, nodePlan)
+		if err2 != nil {
+			return fmt.Errorf("failed to assign validators to nodes: %w", err2)
+		}
+
+		for _, assignment := range assignments {
+			if assignment.Validators == 0 {
+				logrus.Warnf("assigned no validators to node %s", assignment.Name)
+				continue
+			}
+
+			logrus.Infof("assigned %d validators to node %s (state indices %d-%d)", assignment.Validators, assignment.Name, *assignment.StateIndexFrom, *assignment.StateIndexTo)
+		}
+
+		if nodeAssignmentOutput != "" {
+			if err := validators.WriteAssignmentFile(nodeAssignmentOutput, assignments); err != nil {
+				return err
+			}
 
+			logrus.Infof("wrote node assignment to: %s", nodeAssignmentOutput)
+		}
+	}
+
+	var genesisBlock *types.Block
+
+	// the shadow fork block is recorded in the sanity report, as the logs may
+	// be silenced with --quiet
+	var shadowForkInfo *beaconutils.ShadowForkBlock
+
+	if shadowForkBlock != "" || shadowForkRPC != "" {
 		if shadowForkBlock != "" {
 			block, err2 := eth1.LoadBlockFromFile(shadowForkBlock)
 			if err2 != nil {
@@ -230,19 +550,118 @@ func runDevnet(ctx context.Context, cmd *cli.Command) error {
 
 			logrus.Infof("loaded shadow fork block from file. hash: %s", block.Hash().String())
 
-			gensisBlock = block
+			genesisBlock = block
+			shadowForkInfo = &beaconutils.ShadowForkBlock{
+				File:   shadowForkBlock,
+				Number: block.NumberU64(),
+				Hash:   block.Hash().String(),
+			}
 		} else {
-			block, err2 := eth1.GetBlockFromRPC(ctx, shadowForkRPC)
+			headers, err2 := eth1.ParseRPCHeaders(cmd.StringSlice(shadowForkRPCHeaderFlag.Name))
+			if err2 != nil {
+				return err2
+			}
+
+			selector := cmd.String(shadowForkRPCBlockFlag.Name)
+			if selector == "" {
+				selector = "latest"
+			}
+
+			block, err2 := eth1.GetBlockFromRPCWithOptions(ctx, shadowForkRPC, &eth1.RPCOptions{
+				Block:      selector,
+				Timeout:    cmd.Duration(shadowForkRPCTimeoutFlag.Name),
+				Retries:    cmd.Int(shadowForkRPCRetriesFlag.Name),
+				RetryDelay: shadowForkRPCRetryDelay,
+				Headers:    headers,
+			})
 			if err2 != nil {
 				return fmt.Errorf("failed to get shadow fork block: %w", err2)
 			}
 
-			logrus.Infof("loaded shadow fork block from RPC. hash: %s", block.Hash().String())
+			logrus.Infof("loaded shadow fork block %s from RPC. number: %d, hash: %s", selector, block.NumberU64(), block.Hash().String())
+
+			genesisBlock = block
+			shadowForkInfo = &beaconutils.ShadowForkBlock{
+				RPC:      eth1.RedactRPCURL(shadowForkRPC),
+				Selector: selector,
+				Number:   block.NumberU64(),
+				Hash:     block.Hash().String(),
+			}
+		}
+	}
+
+	// the alloc of a shadow fork is not part of the execution genesis
+	if shadowForkBlock == "" && shadowForkRPC == "" {
+		if systemContracts == eth1.SystemContractsInsert && eth1ConfigOutput == "" {
+			return fmt.Errorf("--%s %s requires --%s", systemContractsFlag.Name, eth1.SystemContractsInsert, eth1ConfigOutputFlag.Name)
+		}
+
+		if err := checkSystemContracts(elGenesis, clConfig, systemContracts); err != nil {
+			return err
+		}
+	}
+
+	if eth1GenesisHeader != "" {
+		if genesisBlock != nil {
+			return fmt.Errorf("--%s can not be used with a shadow fork", eth1GenesisHeaderFlag.Name)
+		}
+
+		// the deposit contract storage changes the state root of the header
+		if eth1ConfigOutput != "" {
+			return fmt.Errorf("--%s can not be used with --%s", eth1GenesisHeaderFlag.Name, eth1ConfigOutputFlag.Name)
+		}
+
+		header, err2 := eth1.LoadGenesisHeaderFromFile(eth1GenesisHeader)
+		if err2 != nil {
+			return fmt.Errorf("failed to load execution genesis header: %w", err2)
+		}
+
+		genesisBlock, err2 = eth1.GetGenesisBlockWithHeader(elGenesis, header)
+		if err2 != nil {
+			return err2
+		}
+
+		logrus.Infof("loaded execution genesis header. hash: %s, state root: %s", genesisBlock.Hash().String(), genesisBlock.Root().String())
+	}
+
+	if depositTree && mnemonicsFile != "" {
+		signingKeys, err2 := validators.DeriveSigningKeys(mnemonicsFile, unsignedValidatorSelector(genesisVals))
+		if err2 != nil {
+			return fmt.Errorf("failed to derive deposit signing keys: %w", err2)
+		}
+
+		signed, err2 := beaconutils.SignGenesisDeposits(clConfig, genesisVals, signingKeys)
+		if err2 != nil {
+			return err2
+		}
+
+		logrus.Infof("signed %d genesis deposits", signed)
+	}
+
+	if eth1ConfigOutput != "" {
+		if genesisBlock != nil {
+			return fmt.Errorf("--%s can not be used with a shadow fork", eth1ConfigOutputFlag.Name)
+		}
+
+		// the deposit contract storage is part of the execution genesis state,
+		// so it must be set before the genesis block hash is taken
+		if depositTree {
+			if err := prefillDepositContract(elGenesis, clConfig, genesisVals); err != nil {
+				return err
+			}
+		}
 
-			gensisBlock = block
+		if err := eth1.WriteEth1GenesisConfig(eth1ConfigOutput, elGenesis); err != nil {
+			return err
 		}
 
-		builder.SetShadowForkBlock(gensisBlock)
+		logrus.Infof("wrote execution genesis config to: %s (block hash: %s)", eth1ConfigOutput, elGenesis.ToBlock().Hash().String())
+	} else if depositTree && shadowForkBlock == "" && shadowForkRPC == "" {
+		logrus.Warnf("the deposit contract storage in the execution genesis does not match the deposit tree, use --%s to write a matching execution genesis config", eth1ConfigOutputFlag.Name)
+	}
+
+	if err := configureStateBuilder(builder, genesisBlock, depositTree, preMerge); err != nil {
+		return err
 	}
 
 	genesisState, err := builder.BuildState()
@@ -252,6 +671,31 @@ func runDevnet(ctx context.Context, cmd *cli.Command) error {
 
 	logrus.Infof("successfully built genesis state.")
 
+	if validatorsMappingOutput != "" {
+		// the balances are taken from the state, as the builders cap the
+		// effective balances depending on the fork and credentials
+		stateVals, balances, err2 := beaconchain.GetStateValidators(genesisState)
+		if err2 != nil {
+			return fmt.Errorf("failed to get genesis validators: %w", err2)
+		}
+
+		if err := validators.WriteMappingFileFormat(validatorsMappingOutput, validatorsMappingFormat, genesisVals, stateVals, balances); err != nil {
+			return fmt.Errorf("failed to write validator mapping: %w", err)
+		}
+
+		logrus.Infof("wrote validator mapping to: %s", validatorsMappingOutput)
+	}
+
+	if compareConstruction {
+		if err := compareConstructions(genesisState, elGenesis, clConfig, construction, clValidators, clBuilders, genesisBlock, depositTree, preMerge); err != nil {
+			return err
+		}
+	}
+
+	if err := checkGenesisState(genesisState, clConfig, strict, sanityReport, shadowForkInfo); err != nil {
+		return err
+	}
+
 	if stateOutputFile != "" {
 		sszData, err := builder.Serialize(genesisState, http.ContentTypeSSZ)
 		if err != nil {
@@ -291,3 +735,244 @@ func runDevnet(ctx context.Context, cmd *cli.Command) error {
 
 	return nil
 }
+
+// newStateBuilder returns the genesis builder for a construction mode with the
+// validators and builders added.
+func newStateBuilder(elGenesis *core.Genesis, clConfig *beaconconfig.Config, construction string, clValidators []*validators.Validator, clBuilders []*validators.Builder) (beaconchain.BeaconGenesisBuilder, error) {
+	builder, err := beaconchain.NewGenesisBuilderWithMode(elGenesis, clConfig, construction)
+	if err != nil {
+		return nil, err
+	}
+
+	if builder == nil {
+		return nil, fmt.Errorf("unsupported genesis fork version")
+	}
+
+	builder.AddValidators(clValidators)
+
+	if len(clBuilders) > 0 {
+		registry, ok := builder.(beaconchain.BuilderRegistry)
+		if !ok {
+			return nil, fmt.Errorf("genesis fork has no builder registry")
+		}
+
+		registry.AddBuilders(clBuilders)
+	}
+
+	return builder, nil
+}
+
+// configureStateBuilder sets the shadow fork block, deposit tree and pre-merge
+// mode of a genesis builder.
+func configureStateBuilder(builder beaconchain.BeaconGenesisBuilder, genesisBlock *types.Block, depositTree, preMerge bool) error {
+	if genesisBlock != nil {
+		builder.SetShadowForkBlock(genesisBlock)
+	}
+
+	if depositTree {
+		depositTreeBuilder, ok := builder.(beaconchain.DepositTree)
+		if !ok {
+			return fmt.Errorf("genesis builder does not support --%s", depositTreeFlag.Name)
+		}
+
+		depositTreeBuilder.SetDepositTree(true)
+	}
+
+	if preMerge {
+		preMergeBuilder, ok := builder.(beaconchain.PreMerge)
+		if !ok {
+			return fmt.Errorf("genesis builder does not support --%s", preMergeFlag.Name)
+		}
+
+		preMergeBuilder.SetPreMerge(true)
+	}
+
+	return nil
+}
+
+// compareConstructions builds the genesis state with the other construction
+// mode and logs the state fields that differ from genesisState.
+func compareConstructions(genesisState *spec.VersionedBeaconState, elGenesis *core.Genesis, clConfig *beaconconfig.Config, construction string, clValidators []*validators.Validator, clBuilders []*validators.Builder, genesisBlock *types.Block, depositTree, preMerge bool) error {
+	otherConstruction := beaconchain.ConstructionUpgradeChain
+	if construction == beaconchain.ConstructionUpgradeChain {
+		otherConstruction = beaconchain.ConstructionDirect
+	}
+
+	otherBuilder, err := newStateBuilder(elGenesis, clConfig, otherConstruction, clValidators, clBuilders)
+	if err != nil {
+		return err
+	}
+
+	if err := configureStateBuilder(otherBuilder, genesisBlock, depositTree, preMerge); err != nil {
+		return err
+	}
+
+	otherState, err := otherBuilder.BuildState()
+	if err != nil {
+		return fmt.Errorf("failed to build genesis with %s construction: %w", otherConstruction, err)
+	}
+
+	diffs, err := beaconchain.DiffStates(genesisState, otherState)
+	if err != nil {
+		return fmt.Errorf("failed to compare genesis states: %w", err)
+	}
+
+	if len(diffs) == 0 {
+		logrus.Infof("genesis state of %s construction matches %s construction", otherConstruction, construction)
+		return nil
+	}
+
+	for _, field := range diffs {
+		logrus.Warnf("genesis state field %s differs between %s and %s construction", field, construction, otherConstruction)
+	}
+
+	return nil
+}
+
+// checkSystemContracts checks the system contracts of the genesis fork in the
+// execution genesis alloc and inserts the missing ones in insert mode. Issues
+// are logged as warnings, they only fail the command in strict mode.
+func checkSystemContracts(elGenesis *core.Genesis, clConfig *beaconconfig.Config, mode string) error {
+	inserted, issues, err := eth1.HandleSystemContracts(elGenesis, beaconchain.GetGenesisForkVersion(clConfig), mode)
+
+	for _, contract := range inserted {
+		logrus.Infof("inserted %s contract %s into the execution genesis alloc", contract.Name, contract.Address.String())
+	}
+
+	for _, issue := range issues {
+		switch {
+		case mode == eth1.SystemContractsStrict:
+			logrus.Errorf("system contract check failed: %s", issue)
+		case issue.Missing && issue.Contract.Required:
+			logrus.Warnf("system contract check: %s, blocks can not be processed until it is deployed", issue)
+		default:
+			logrus.Warnf("system contract check: %s", issue)
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w (use --%s %s to insert missing contracts)", err, systemContractsFlag.Name, eth1.SystemContractsInsert)
+	}
+
+	return nil
+}
+
+// prefillDepositContract sets the deposit contract storage in the execution
+// genesis alloc to the deposit tree of the genesis deposits.
+func prefillDepositContract(elGenesis *core.Genesis, clConfig *beaconconfig.Config, clValidators []*validators.Validator) error {
+	address, err := eth1.GetDepositContractAddress(elGenesis, clConfig.GetBytesDefault("DEPOSIT_CONTRACT_ADDRESS", nil))
+	if err != nil {
+		return err
+	}
+
+	deposits, err := beaconutils.GetGenesisDeposits(clConfig, clValidators)
+	if err != nil {
+		return fmt.Errorf("failed to get genesis deposits: %w", err)
+	}
+
+	branch, err := beaconutils.ComputeDepositContractBranch(clConfig, deposits)
+	if err != nil {
+		return fmt.Errorf("failed to compute deposit contract branch: %w", err)
+	}
+
+	if err := eth1.SetDepositContractStorage(elGenesis, address, branch, uint64(len(deposits))); err != nil {
+		return err
+	}
+
+	logrus.Infof("set deposit contract storage of %s (%d deposits)", address.String(), len(deposits))
+
+	return nil
+}
+
+// importStateValidators imports the validators of the --import-state beacon
+// state that pass the --import-state-* filters.
+func importStateValidators(cmd *cli.Command, clConfig *beaconconfig.Config) ([]*validators.Validator, error) {
+	statePath := cmd.String(importStateFlag.Name)
+	stateConfig := clConfig
+
+	if stateConfigPath := cmd.String(importStateConfigFlag.Name); stateConfigPath != "" {
+		var err error
+
+		stateConfig, err = beaconconfig.LoadConfig(stateConfigPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load consensus config of the imported state: %w", err)
+		}
+	}
+
+	filter := &validators.StateImportFilter{
+		ActiveOnly: cmd.Bool(importStateActiveOnlyFlag.Name),
+	}
+
+	if indexRange := cmd.String(importStateRangeFlag.Name); indexRange != "" {
+		from, to, err := parseKeyRange(indexRange)
+		if err != nil {
+			return nil, err
+		}
+
+		filter.IndexFrom = from
+		filter.IndexTo = &to
+	}
+
+	for _, value := range cmd.StringSlice(importStateCredentialsFlag.Name) {
+		credType, err := validators.ParseCredentialType(value)
+		if err != nil {
+			return nil, err
+		}
+
+		filter.CredentialTypes = append(filter.CredentialTypes, credType)
+	}
+
+	state, err := beaconchain.LoadStateFromFile(statePath, stateConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load beacon state to import: %w", err)
+	}
+
+	stateVals, balances, err := beaconchain.GetStateValidators(state)
+	if err != nil {
+		return nil, err
+	}
+
+	epoch, err := beaconchain.GetStateEpoch(state, stateConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	vals, err := validators.ImportStateValidators(stateVals, balances, epoch, filepath.Base(statePath), filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import validators from beacon state: %w", err)
+	}
+
+	logrus.Infof("imported %d of %d validators from %s beacon state at epoch %d: %s", len(vals), len(stateVals), state.Version.String(), epoch, statePath)
+
+	return vals, nil
+}
+
+// unsignedValidatorSelector selects the signing keys of the validators without
+// a deposit signature.
+func unsignedValidatorSelector(vals []*validators.Validator) validators.KeySelector {
+	type keyID struct {
+		source   string
+		keyIndex uint64
+	}
+
+	unsigned := make(map[keyID]bool, len(vals))
+	for _, val := range vals {
+		if val.DepositSignature == nil {
+			unsigned[keyID{val.Source, val.SourceKeyIndex}] = true
+		}
+	}
+
+	return func(source string, keyIndex uint64) bool {
+		return unsigned[keyID{source, keyIndex}]
+	}
+}
+
+func countDropped(conflicts []*validators.DuplicateConflict) int {
+	count := 0
+
+	for _, conflict := range conflicts {
+		count += len(conflict.Dropped)
+	}
+
+	return count
+}
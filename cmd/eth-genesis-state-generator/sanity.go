@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ethpandaops/go-eth2-client/spec"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ethpandaops/eth-beacon-genesis/beaconchain"
+	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
+	"github.com/ethpandaops/eth-beacon-genesis/beaconutils"
+)
+
+// checkGenesisState runs the genesis sanity checks, logs every issue and
+// writes the report with the shadow fork block to reportFile if set. It fails
+// on errors, and on warnings too in strict mode.
+func checkGenesisState(state *spec.VersionedBeaconState, clConfig *beaconconfig.Config, strict bool, reportFile string, shadowFork *beaconutils.ShadowForkBlock) error {
+	report, err := beaconchain.CheckGenesisState(state, clConfig)
+	if err != nil {
+		return fmt.Errorf("failed to check genesis state: %w", err)
+	}
+
+	report.ShadowFork = shadowFork
+
+	logrus.Infof("genesis sanity: %d/%d validators active, active balance: %d ETH, total balance: %d ETH",
+		report.ActiveValidators, report.Validators, report.ActiveBalance/1_000_000_000, report.TotalBalance/1_000_000_000)
+
+	for _, issue := range report.Issues {
+		if issue.Severity == beaconutils.SanityError {
+			logrus.Errorf("genesis sanity check %s failed: %s", issue.Check, issue.Message)
+		} else {
+			logrus.Warnf("genesis sanity check %s: %s", issue.Check, issue.Message)
+		}
+	}
+
+	if reportFile != "" {
+		data, err := yaml.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("failed to encode sanity report: %w", err)
+		}
+
+		if err := os.WriteFile(reportFile, data, 0o644); err != nil { //nolint:gosec // no strict permissions needed
+			return fmt.Errorf("failed to write sanity report: %w", err)
+		}
+
+		logrus.Infof("wrote sanity report to: %s", reportFile)
+	}
+
+	if failures := report.Failures(strict); len(failures) > 0 {
+		return fmt.Errorf("genesis state failed %d sanity checks, first: %s: %s", len(failures), failures[0].Check, failures[0].Message)
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ethpandaops/go-eth2-client/http"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v3"
+
+	"github.com/ethpandaops/eth-beacon-genesis/beaconchain"
+	"github.com/ethpandaops/eth-beacon-genesis/beaconconfig"
+)
+
+var (
+	upgradeStateInputFlag = &cli.StringFlag{
+		Name:     "state",
+		Usage:    "Path to the beacon state to upgrade (SSZ, or JSON with .json extension)",
+		Required: true,
+	}
+	upgradeStateTargetForkFlag = &cli.StringFlag{
+		Name:  "target-fork",
+		Usage: "Fork to upgrade the state to (altair, bellatrix, capella, deneb, electra, fulu or gloas), defaults to the fork after the state fork",
+	}
+
+	upgradeStateCommand = &cli.Command{
+		Name:  "upgrade-state",
+		Usage: "Upgrade an existing beacon state to a later fork with the spec fork upgrades",
+		Flags: []cli.Flag{
+			configFlag, upgradeStateInputFlag, upgradeStateTargetForkFlag, stateOutputFlag, jsonOutputFlag, quietFlag,
+		},
+		Action:    runUpgradeState,
+		UsageText: "eth-beacon-genesis upgrade-state --config config.yaml --state genesis.ssz [--target-fork fulu] [options]",
+	}
+)
+
+func runUpgradeState(_ context.Context, cmd *cli.Command) error {
+	stateOutputFile := cmd.String(stateOutputFlag.Name)
+	jsonOutputFile := cmd.String(jsonOutputFlag.Name)
+	quiet := cmd.Bool(quietFlag.Name)
+
+	if quiet {
+		logrus.SetLevel(logrus.PanicLevel)
+	}
+
+	clConfig, err := beaconconfig.LoadConfig(cmd.String(configFlag.Name))
+	if err != nil {
+		return fmt.Errorf("failed to load consensus config: %w", err)
+	}
+
+	state, err := beaconchain.LoadStateFromFile(cmd.String(upgradeStateInputFlag.Name), clConfig)
+	if err != nil {
+		return fmt.Errorf("failed to load beacon state: %w", err)
+	}
+
+	logrus.Infof("loaded %s beacon state", state.Version.String())
+
+	target := state.Version + 1
+
+	if targetFork := cmd.String(upgradeStateTargetForkFlag.Name); targetFork != "" {
+		target, err = beaconchain.GetForkVersionByName(targetFork)
+		if err != nil {
+			return err
+		}
+	}
+
+	if target <= state.Version {
+		return fmt.Errorf("target fork %s is not after the state fork %s", target.String(), state.Version.String())
+	}
+
+	upgradedState, err := beaconchain.UpgradeState(state, target, clConfig)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("upgraded beacon state to %s", upgradedState.Version.String())
+
+	if stateOutputFile != "" {
+		sszData, err := beaconchain.SerializeState(upgradedState, clConfig, http.ContentTypeSSZ)
+		if err != nil {
+			return fmt.Errorf("failed to serialize beacon state: %w", err)
+		}
+
+		if err := os.WriteFile(stateOutputFile, sszData, 0o644); err != nil { //nolint:gosec // no strict permissions needed
+			return fmt.Errorf("failed to write beacon state to SSZ file: %w", err)
+		}
+
+		logrus.Infof("serialized beacon state to SSZ file: %s", stateOutputFile)
+	}
+
+	if jsonOutputFile != "" || stateOutputFile == "" {
+		jsonData, err := beaconchain.SerializeState(upgradedState, clConfig, http.ContentTypeJSON)
+		if err != nil {
+			return fmt.Errorf("failed to serialize beacon state: %w", err)
+		}
+
+		if jsonOutputFile == "" {
+			fmt.Println(string(jsonData))
+			return nil
+		}
+
+		if err := os.WriteFile(jsonOutputFile, jsonData, 0o644); err != nil { //nolint:gosec // no strict permissions needed
+			return fmt.Errorf("failed to write beacon state to JSON file: %w", err)
+		}
+
+		logrus.Infof("serialized beacon state to JSON file: %s", jsonOutputFile)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package eth1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// besuConfigAliases maps Besu chain config fields to the geth field with the
+// same meaning. Besu matches all fields case insensitively, like geth does.
+var besuConfigAliases = map[string]string{
+	"constantinoplefixblock": "petersburgBlock",
+}
+
+// isBesuGenesis reports whether data is a genesis with Besu specific chain
+// config fields, which geth would ignore.
+func isBesuGenesis(data []byte) bool {
+	var genesis struct {
+		Config map[string]json.RawMessage `json:"config"`
+	}
+
+	if err := json.Unmarshal(data, &genesis); err != nil {
+		return false
+	}
+
+	for key := range genesis.Config {
+		if _, found := besuConfigAliases[strings.ToLower(key)]; found {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseBesuGenesis converts a Besu genesis file into a geth genesis with the
+// same genesis block. Besu genesis files mostly follow the geth format, but
+// use some differently named config fields and accept alloc storage words
+// that geth rejects.
+func ParseBesuGenesis(data []byte) (*core.Genesis, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode besu genesis: %w", err)
+	}
+
+	alloc := map[common.UnprefixedAddress]*allocAccount{}
+
+	for key, value := range fields {
+		switch strings.ToLower(key) {
+		case "alloc":
+			if err := json.Unmarshal(value, &alloc); err != nil {
+				return nil, fmt.Errorf("failed to decode besu genesis alloc: %w", err)
+			}
+
+			delete(fields, key)
+		case "config":
+			config, err := convertBesuConfig(value)
+			if err != nil {
+				return nil, err
+			}
+
+			fields[key] = config
+		}
+	}
+
+	// the alloc is converted separately, geth requires the field
+	fields["alloc"] = json.RawMessage("{}")
+
+	gethData, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode besu genesis: %w", err)
+	}
+
+	genesis, err := parseGethGenesis(gethData)
+	if err != nil {
+		return nil, err
+	}
+
+	genesis.Alloc = make(types.GenesisAlloc, len(alloc))
+
+	for address, account := range alloc {
+		genesisAccount, err := account.toAccount()
+		if err != nil {
+			return nil, fmt.Errorf("account %s: %w", common.Address(address).String(), err)
+		}
+
+		genesis.Alloc[common.Address(address)] = genesisAccount
+	}
+
+	return genesis, nil
+}
+
+// convertBesuConfig renames the Besu specific chain config fields to their
+// geth names. Fields that are set under both names keep the geth one.
+func convertBesuConfig(data json.RawMessage) (json.RawMessage, error) {
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode besu genesis config: %w", err)
+	}
+
+	for key, value := range config {
+		gethKey, found := besuConfigAliases[strings.ToLower(key)]
+		if !found {
+			continue
+		}
+
+		delete(config, key)
+
+		if !hasFieldFold(config, gethKey) {
+			config[gethKey] = value
+		}
+	}
+
+	return json.Marshal(config)
+}
+
+// hasFieldFold reports whether fields has the key, ignoring case.
+func hasFieldFold(fields map[string]json.RawMessage, key string) bool {
+	for field := range fields {
+		if strings.EqualFold(field, key) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,6 +1,8 @@
 package eth1
 
 import (
+	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,6 +12,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/sirupsen/logrus"
 )
 
 type rpcBlock struct {
@@ -62,6 +66,8 @@ func ParseEthBlock(blockData json.RawMessage) (*types.Block, error) {
 	}), nil
 }
 
+// LoadBlockFromFile loads a block from a file or URL in any of the formats
+// supported by ParseBlock.
 func LoadBlockFromFile(filePath string) (*types.Block, error) {
 	var blockBytes []byte
 
@@ -86,18 +92,157 @@ func LoadBlockFromFile(filePath string) (*types.Block, error) {
 		}
 	}
 
-	// Unmarshal the JSON into a types.Block object
-	var resultData JSONData
+	block, format, err := ParseBlock(blockBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse eth1 block: %w", err)
+	}
+
+	logrus.Infof("parsed shadow fork block as %s", format)
+
+	return block, nil
+}
+
+// blockFormat is a supported encoding of a shadow fork block.
+type blockFormat struct {
+	name  string
+	parse func(data []byte) (*types.Block, error)
+}
+
+// blockFormats lists the supported block encodings in detection order.
+var blockFormats = []blockFormat{
+	{name: "JSON-RPC response", parse: parseRPCResponseBlock},
+	{name: "block JSON", parse: parseBlockJSON},
+	{name: "header JSON", parse: parseHeaderJSON},
+	{name: "hex RLP", parse: parseHexRLPBlock},
+	{name: "binary RLP", parse: parseBinaryRLPBlock},
+}
+
+// ParseBlock parses an execution block given as JSON-RPC response (of
+// eth_getBlockByNumber or debug_getRawBlock), bare block JSON, header JSON,
+// or RLP encoded block in hex or binary. It returns the name of the detected
+// format.
+func ParseBlock(data []byte) (*types.Block, string, error) {
+	failures := make([]string, 0, len(blockFormats))
+
+	for _, format := range blockFormats {
+		block, err := format.parse(data)
+		if err == nil {
+			return block, format.name, nil
+		}
+
+		failures = append(failures, fmt.Sprintf("%s: %v", format.name, err))
+	}
+
+	return nil, "", fmt.Errorf("unsupported block format, tried %s", strings.Join(failures, "; "))
+}
+
+// parseJSONObject decodes data as JSON object with raw field values.
+func parseJSONObject(data []byte) (map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("not a JSON object")
+	}
+
+	if fields == nil {
+		return nil, fmt.Errorf("not a JSON object")
+	}
+
+	return fields, nil
+}
+
+// parseRPCResponseBlock parses a JSON-RPC response with a block or header
+// JSON result, or a hex RLP result (debug_getRawBlock).
+func parseRPCResponseBlock(data []byte) (*types.Block, error) {
+	fields, err := parseJSONObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result, found := fields["result"]
+	if !found || string(result) == "null" {
+		return nil, fmt.Errorf("no result field")
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(result), []byte("\"")) {
+		return parseHexRLPBlock(result)
+	}
+
+	resultFields, err := parseJSONObject(result)
+	if err != nil {
+		return nil, fmt.Errorf("result is neither a block object nor hex RLP")
+	}
 
-	err2 := json.Unmarshal(blockBytes, &resultData)
-	if err2 != nil {
-		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err2)
+	if _, found := resultFields["transactions"]; found {
+		return ParseEthBlock(result)
 	}
 
-	// Set the eth1Block value for use later
-	block, err2 := ParseEthBlock(resultData.Result)
-	if err2 != nil {
-		return nil, fmt.Errorf("failed to parse eth1 block: %w", err2)
+	return parseHeaderJSON(result)
+}
+
+// parseBlockJSON parses a bare block JSON object with transactions.
+func parseBlockJSON(data []byte) (*types.Block, error) {
+	fields, err := parseJSONObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, found := fields["transactions"]; !found {
+		return nil, fmt.Errorf("no transactions field")
+	}
+
+	return ParseEthBlock(data)
+}
+
+// parseHeaderJSON parses a block header JSON object. Without the block body
+// the payload transactions and withdrawals roots can only be derived if the
+// header commits to empty lists.
+func parseHeaderJSON(data []byte) (*types.Block, error) {
+	if _, err := parseJSONObject(data); err != nil {
+		return nil, err
+	}
+
+	var header types.Header
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+
+	if header.TxHash != types.EmptyTxsHash {
+		return nil, fmt.Errorf("header has transactions (transactions root %s), the full block is needed", header.TxHash.String())
+	}
+
+	body := types.Body{}
+
+	if header.WithdrawalsHash != nil {
+		if *header.WithdrawalsHash != types.EmptyWithdrawalsHash {
+			return nil, fmt.Errorf("header has withdrawals (withdrawals root %s), the full block is needed", header.WithdrawalsHash.String())
+		}
+
+		body.Withdrawals = types.Withdrawals{}
+	}
+
+	return types.NewBlockWithHeader(&header).WithBody(body), nil
+}
+
+// parseHexRLPBlock parses a hex encoded RLP block, optionally 0x prefixed and
+// quoted as JSON string.
+func parseHexRLPBlock(data []byte) (*types.Block, error) {
+	hexData := strings.TrimSpace(string(data))
+	hexData = strings.TrimSuffix(strings.TrimPrefix(hexData, "\""), "\"")
+	hexData = strings.TrimPrefix(hexData, "0x")
+
+	rlpData, err := hex.DecodeString(hexData)
+	if err != nil {
+		return nil, fmt.Errorf("not hex encoded")
+	}
+
+	return parseBinaryRLPBlock(rlpData)
+}
+
+// parseBinaryRLPBlock parses a RLP encoded block.
+func parseBinaryRLPBlock(data []byte) (*types.Block, error) {
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(data, block); err != nil {
+		return nil, err
 	}
 
 	return block, nil
@@ -0,0 +1,183 @@
+package eth1
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+func createTestBlock(t *testing.T, withTransactions bool) *types.Block {
+	t.Helper()
+
+	body := &types.Body{
+		Withdrawals: types.Withdrawals{
+			{Index: 7, Validator: 3, Address: common.Address{0x42}, Amount: 1_000_000},
+		},
+	}
+
+	if withTransactions {
+		body.Transactions = types.Transactions{
+			types.NewTx(&types.LegacyTx{
+				Nonce:    1,
+				GasPrice: big.NewInt(7),
+				Gas:      21_000,
+				To:       &common.Address{0x01},
+				Value:    big.NewInt(1),
+			}),
+		}
+	}
+
+	return types.NewBlock(&types.Header{
+		ParentHash: common.Hash{0x01},
+		Root:       common.Hash{0x02},
+		Difficulty: big.NewInt(0),
+		Number:     big.NewInt(1234),
+		GasLimit:   30_000_000,
+		Time:       1700000000,
+		BaseFee:    big.NewInt(7),
+	}, body, nil, trie.NewStackTrie(nil))
+}
+
+// blockJSON returns the block as eth_getBlockByNumber result with full
+// transactions.
+func blockJSON(t *testing.T, block *types.Block) []byte {
+	t.Helper()
+
+	fields := map[string]any{}
+
+	headerJSON, err := json.Marshal(block.Header())
+	if err != nil {
+		t.Fatalf("failed to encode header: %v", err)
+	}
+
+	if err := json.Unmarshal(headerJSON, &fields); err != nil {
+		t.Fatalf("failed to decode header: %v", err)
+	}
+
+	fields["transactions"] = block.Transactions()
+	fields["uncles"] = []common.Hash{}
+	fields["withdrawals"] = block.Withdrawals()
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatalf("failed to encode block: %v", err)
+	}
+
+	return data
+}
+
+func TestParseBlock(t *testing.T) {
+	block := createTestBlock(t, true)
+
+	rlpData, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		t.Fatalf("failed to encode block: %v", err)
+	}
+
+	rlpHex := "0x" + hex.EncodeToString(rlpData)
+
+	tests := []struct {
+		name   string
+		data   string
+		format string
+	}{
+		{name: "rpc response", data: `{"jsonrpc":"2.0","id":1,"result":` + string(blockJSON(t, block)) + `}`, format: "JSON-RPC response"},
+		{name: "raw block response", data: `{"jsonrpc":"2.0","id":1,"result":"` + rlpHex + `"}`, format: "JSON-RPC response"},
+		{name: "block json", data: string(blockJSON(t, block)), format: "block JSON"},
+		{name: "hex rlp", data: rlpHex + "\n", format: "hex RLP"},
+		{name: "hex rlp without prefix", data: strings.TrimPrefix(rlpHex, "0x"), format: "hex RLP"},
+		{name: "binary rlp", data: string(rlpData), format: "binary RLP"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parsed, format, err := ParseBlock([]byte(test.data))
+			if err != nil {
+				t.Fatalf("failed to parse block: %v", err)
+			}
+
+			if format != test.format {
+				t.Fatalf("expected format %s, got %s", test.format, format)
+			}
+
+			if parsed.Hash() != block.Hash() {
+				t.Fatalf("expected block hash %s, got %s", block.Hash(), parsed.Hash())
+			}
+
+			if len(parsed.Transactions()) != 1 || len(parsed.Withdrawals()) != 1 {
+				t.Fatalf("expected 1 transaction and 1 withdrawal, got %d and %d", len(parsed.Transactions()), len(parsed.Withdrawals()))
+			}
+		})
+	}
+}
+
+func TestParseBlock_Header(t *testing.T) {
+	block := createTestBlock(t, false)
+
+	headerJSON, err := json.Marshal(block.Header())
+	if err != nil {
+		t.Fatalf("failed to encode header: %v", err)
+	}
+
+	// header without the block body, withdrawals are not empty
+	if _, _, err := ParseBlock(headerJSON); err == nil || !strings.Contains(err.Error(), "header has withdrawals") {
+		t.Fatalf("expected withdrawals error, got %v", err)
+	}
+
+	block = types.NewBlock(block.Header(), &types.Body{Withdrawals: types.Withdrawals{}}, nil, trie.NewStackTrie(nil))
+
+	headerJSON, err = json.Marshal(block.Header())
+	if err != nil {
+		t.Fatalf("failed to encode header: %v", err)
+	}
+
+	parsed, format, err := ParseBlock(headerJSON)
+	if err != nil {
+		t.Fatalf("failed to parse header: %v", err)
+	}
+
+	if format != "header JSON" {
+		t.Fatalf("expected header JSON format, got %s", format)
+	}
+
+	if parsed.Hash() != block.Hash() || parsed.Withdrawals() == nil {
+		t.Fatalf("expected block %s with empty withdrawals, got %s", block.Hash(), parsed.Hash())
+	}
+
+	// a header of a block with transactions is not enough
+	headerJSON, err = json.Marshal(createTestBlock(t, true).Header())
+	if err != nil {
+		t.Fatalf("failed to encode header: %v", err)
+	}
+
+	if _, _, err := ParseBlock(headerJSON); err == nil || !strings.Contains(err.Error(), "header has transactions") {
+		t.Fatalf("expected transactions error, got %v", err)
+	}
+}
+
+func TestLoadBlockFromFile_Unsupported(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "block.txt")
+	if err := os.WriteFile(path, []byte("not a block"), 0o600); err != nil {
+		t.Fatalf("failed to write block file: %v", err)
+	}
+
+	_, err := LoadBlockFromFile(path)
+	if err == nil {
+		t.Fatalf("expected error for unsupported block format")
+	}
+
+	for _, format := range []string{"JSON-RPC response", "block JSON", "header JSON", "hex RLP", "binary RLP"} {
+		if !strings.Contains(err.Error(), format) {
+			t.Fatalf("expected error to name the %s format, got %s", format, err)
+		}
+	}
+}
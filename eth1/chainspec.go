@@ -0,0 +1,323 @@
+package eth1
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// nethermindChainspec is a Nethermind chainspec, reduced to the fields that
+// affect the genesis block and the fork schedule.
+type nethermindChainspec struct {
+	Name     string                                          `json:"name"`
+	Engine   map[string]json.RawMessage                      `json:"engine"`
+	Params   nethermindParams                                `json:"params"`
+	Genesis  nethermindGenesis                               `json:"genesis"`
+	Accounts map[common.UnprefixedAddress]*nethermindAccount `json:"accounts"`
+}
+
+// nethermindParams are the chainspec params. Forks are not named in a
+// chainspec, they are scheduled by the transitions of their EIPs.
+type nethermindParams struct {
+	ChainID                    *math.HexOrDecimal256 `json:"chainId"`
+	NetworkID                  *math.HexOrDecimal256 `json:"networkID"`
+	TerminalTotalDifficulty    *math.HexOrDecimal256 `json:"terminalTotalDifficulty"`
+	DepositContractAddress     *common.Address       `json:"depositContractAddress"`
+	Eip1559BaseFeeInitialValue *math.HexOrDecimal256 `json:"eip1559BaseFeeInitialValue"`
+
+	Eip150Transition         *math.HexOrDecimal64 `json:"eip150Transition"`
+	Eip155Transition         *math.HexOrDecimal64 `json:"eip155Transition"`
+	Eip160Transition         *math.HexOrDecimal64 `json:"eip160Transition"`
+	Eip161abcTransition      *math.HexOrDecimal64 `json:"eip161abcTransition"`
+	Eip140Transition         *math.HexOrDecimal64 `json:"eip140Transition"`
+	Eip145Transition         *math.HexOrDecimal64 `json:"eip145Transition"`
+	Eip1283DisableTransition *math.HexOrDecimal64 `json:"eip1283DisableTransition"`
+	Eip1344Transition        *math.HexOrDecimal64 `json:"eip1344Transition"`
+	Eip2028Transition        *math.HexOrDecimal64 `json:"eip2028Transition"`
+	Eip2929Transition        *math.HexOrDecimal64 `json:"eip2929Transition"`
+	Eip1559Transition        *math.HexOrDecimal64 `json:"eip1559Transition"`
+	MergeForkIDTransition    *math.HexOrDecimal64 `json:"mergeForkIdTransition"`
+
+	Eip3651TransitionTimestamp *math.HexOrDecimal64 `json:"eip3651TransitionTimestamp"`
+	Eip4895TransitionTimestamp *math.HexOrDecimal64 `json:"eip4895TransitionTimestamp"`
+	Eip4844TransitionTimestamp *math.HexOrDecimal64 `json:"eip4844TransitionTimestamp"`
+	Eip4788TransitionTimestamp *math.HexOrDecimal64 `json:"eip4788TransitionTimestamp"`
+	Eip7702TransitionTimestamp *math.HexOrDecimal64 `json:"eip7702TransitionTimestamp"`
+	Eip7002TransitionTimestamp *math.HexOrDecimal64 `json:"eip7002TransitionTimestamp"`
+	Eip7594TransitionTimestamp *math.HexOrDecimal64 `json:"eip7594TransitionTimestamp"`
+	Eip7823TransitionTimestamp *math.HexOrDecimal64 `json:"eip7823TransitionTimestamp"`
+
+	BlobSchedule []*nethermindBlobSchedule `json:"blobSchedule"`
+}
+
+// nethermindBlobSchedule is a blob schedule entry, which applies from its
+// timestamp on.
+type nethermindBlobSchedule struct {
+	Timestamp             math.HexOrDecimal64 `json:"timestamp"`
+	Target                math.HexOrDecimal64 `json:"target"`
+	Max                   math.HexOrDecimal64 `json:"max"`
+	BaseFeeUpdateFraction math.HexOrDecimal64 `json:"baseFeeUpdateFraction"`
+}
+
+type nethermindGenesis struct {
+	Seal struct {
+		Ethereum *struct {
+			Nonce   math.HexOrDecimal64 `json:"nonce"`
+			MixHash common.Hash         `json:"mixHash"`
+		} `json:"ethereum"`
+	} `json:"seal"`
+	Difficulty    *math.HexOrDecimal256 `json:"difficulty"`
+	Author        common.Address        `json:"author"`
+	Timestamp     math.HexOrDecimal64   `json:"timestamp"`
+	ParentHash    common.Hash           `json:"parentHash"`
+	ExtraData     hexutil.Bytes         `json:"extraData"`
+	GasLimit      math.HexOrDecimal64   `json:"gasLimit"`
+	BaseFeePerGas *math.HexOrDecimal256 `json:"baseFeePerGas"`
+	ExcessBlobGas *math.HexOrDecimal64  `json:"excessBlobGas"`
+	BlobGasUsed   *math.HexOrDecimal64  `json:"blobGasUsed"`
+}
+
+type nethermindAccount struct {
+	allocAccount
+	Builtin     json.RawMessage `json:"builtin"`
+	Constructor hexutil.Bytes   `json:"constructor"`
+}
+
+// nethermindEthashParams are the params of the Ethash engine.
+type nethermindEthashParams struct {
+	Params struct {
+		HomesteadTransition *math.HexOrDecimal64 `json:"homesteadTransition"`
+	} `json:"params"`
+}
+
+// isNethermindChainspec reports whether data is a JSON object with the engine
+// and params sections of a chainspec.
+func isNethermindChainspec(data []byte) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return false
+	}
+
+	_, hasEngine := fields["engine"]
+	_, hasParams := fields["params"]
+
+	return hasEngine && hasParams
+}
+
+// ParseNethermindChainspec converts a Nethermind chainspec into a geth genesis
+// with the same genesis block. Builtin (precompile) accounts without balance
+// are not part of the genesis state and are skipped like Nethermind does.
+func ParseNethermindChainspec(data []byte) (*core.Genesis, error) {
+	var chainspec nethermindChainspec
+	if err := json.Unmarshal(data, &chainspec); err != nil {
+		return nil, fmt.Errorf("failed to decode nethermind chainspec: %w", err)
+	}
+
+	config, err := chainspec.chainConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	genesis := &core.Genesis{
+		Config:     config,
+		Timestamp:  uint64(chainspec.Genesis.Timestamp),
+		ExtraData:  chainspec.Genesis.ExtraData,
+		GasLimit:   uint64(chainspec.Genesis.GasLimit),
+		Coinbase:   chainspec.Genesis.Author,
+		ParentHash: chainspec.Genesis.ParentHash,
+		Difficulty: new(big.Int),
+		Alloc:      make(types.GenesisAlloc, len(chainspec.Accounts)),
+	}
+
+	if seal := chainspec.Genesis.Seal.Ethereum; seal != nil {
+		genesis.Nonce = uint64(seal.Nonce)
+		genesis.Mixhash = seal.MixHash
+	}
+
+	if chainspec.Genesis.Difficulty != nil {
+		genesis.Difficulty = (*big.Int)(chainspec.Genesis.Difficulty)
+	}
+
+	switch {
+	case chainspec.Genesis.BaseFeePerGas != nil:
+		genesis.BaseFee = (*big.Int)(chainspec.Genesis.BaseFeePerGas)
+	case chainspec.Params.Eip1559BaseFeeInitialValue != nil && config.IsLondon(common.Big0):
+		genesis.BaseFee = (*big.Int)(chainspec.Params.Eip1559BaseFeeInitialValue)
+	}
+
+	if chainspec.Genesis.ExcessBlobGas != nil {
+		excessBlobGas := uint64(*chainspec.Genesis.ExcessBlobGas)
+		genesis.ExcessBlobGas = &excessBlobGas
+	}
+
+	if chainspec.Genesis.BlobGasUsed != nil {
+		blobGasUsed := uint64(*chainspec.Genesis.BlobGasUsed)
+		genesis.BlobGasUsed = &blobGasUsed
+	}
+
+	for address, account := range chainspec.Accounts {
+		if account.Builtin != nil && account.Balance == nil {
+			continue
+		}
+
+		if len(account.Constructor) > 0 {
+			return nil, fmt.Errorf("account %s: constructor accounts are not supported", common.Address(address).String())
+		}
+
+		genesisAccount, err := account.toAccount()
+		if err != nil {
+			return nil, fmt.Errorf("account %s: %w", common.Address(address).String(), err)
+		}
+
+		genesis.Alloc[common.Address(address)] = genesisAccount
+	}
+
+	return genesis, nil
+}
+
+// chainConfig derives the chain config from the chainspec params. A fork is
+// scheduled by the transition of the first of its EIPs found in the params.
+func (c *nethermindChainspec) chainConfig() (*params.ChainConfig, error) {
+	p := &c.Params
+
+	config := &params.ChainConfig{
+		HomesteadBlock:      big.NewInt(0),
+		EIP150Block:         transitionBlock(p.Eip150Transition),
+		EIP155Block:         transitionBlock(p.Eip155Transition),
+		EIP158Block:         transitionBlock(p.Eip161abcTransition, p.Eip160Transition),
+		ByzantiumBlock:      transitionBlock(p.Eip140Transition),
+		ConstantinopleBlock: transitionBlock(p.Eip145Transition),
+		PetersburgBlock:     transitionBlock(p.Eip1283DisableTransition, p.Eip145Transition),
+		IstanbulBlock:       transitionBlock(p.Eip1344Transition, p.Eip2028Transition),
+		BerlinBlock:         transitionBlock(p.Eip2929Transition),
+		LondonBlock:         transitionBlock(p.Eip1559Transition),
+		MergeNetsplitBlock:  transitionBlock(p.MergeForkIDTransition),
+		ShanghaiTime:        transitionTime(p.Eip3651TransitionTimestamp, p.Eip4895TransitionTimestamp),
+		CancunTime:          transitionTime(p.Eip4844TransitionTimestamp, p.Eip4788TransitionTimestamp),
+		PragueTime:          transitionTime(p.Eip7702TransitionTimestamp, p.Eip7002TransitionTimestamp),
+		OsakaTime:           transitionTime(p.Eip7594TransitionTimestamp, p.Eip7823TransitionTimestamp),
+	}
+
+	switch {
+	case p.ChainID != nil:
+		config.ChainID = (*big.Int)(p.ChainID)
+	case p.NetworkID != nil:
+		config.ChainID = (*big.Int)(p.NetworkID)
+	default:
+		return nil, fmt.Errorf("nethermind chainspec has no chainId")
+	}
+
+	if p.TerminalTotalDifficulty != nil {
+		config.TerminalTotalDifficulty = (*big.Int)(p.TerminalTotalDifficulty)
+	}
+
+	if p.DepositContractAddress != nil {
+		config.DepositContractAddress = *p.DepositContractAddress
+	}
+
+	if ethashParams, found := c.Engine["Ethash"]; found {
+		config.Ethash = &params.EthashConfig{}
+
+		var ethash nethermindEthashParams
+		if err := json.Unmarshal(ethashParams, &ethash); err != nil {
+			return nil, fmt.Errorf("failed to decode Ethash engine params: %w", err)
+		}
+
+		if ethash.Params.HomesteadTransition != nil {
+			config.HomesteadBlock = transitionBlock(ethash.Params.HomesteadTransition)
+		}
+	}
+
+	blobSchedule, err := c.blobScheduleConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	config.BlobScheduleConfig = blobSchedule
+
+	return config, nil
+}
+
+// blobScheduleConfig maps the blob schedule entries to the cancun and prague
+// blob configs by their timestamp. Later entries are BPO forks.
+func (c *nethermindChainspec) blobScheduleConfig(config *params.ChainConfig) (*params.BlobScheduleConfig, error) {
+	if len(c.Params.BlobSchedule) == 0 {
+		return nil, nil
+	}
+
+	entries := make([]*nethermindBlobSchedule, len(c.Params.BlobSchedule))
+	copy(entries, c.Params.BlobSchedule)
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp < entries[j].Timestamp
+	})
+
+	schedule := &params.BlobScheduleConfig{}
+	bpoForks := []struct {
+		config **params.BlobConfig
+		time   **uint64
+	}{
+		{&schedule.BPO1, &config.BPO1Time},
+		{&schedule.BPO2, &config.BPO2Time},
+		{&schedule.BPO3, &config.BPO3Time},
+		{&schedule.BPO4, &config.BPO4Time},
+		{&schedule.BPO5, &config.BPO5Time},
+	}
+
+	for _, entry := range entries {
+		timestamp := uint64(entry.Timestamp)
+		blobConfig := &params.BlobConfig{
+			Target:         int(entry.Target), //nolint:gosec // blob counts are small
+			Max:            int(entry.Max),    //nolint:gosec // blob counts are small
+			UpdateFraction: uint64(entry.BaseFeeUpdateFraction),
+		}
+
+		switch {
+		case config.CancunTime != nil && timestamp == *config.CancunTime && schedule.Cancun == nil:
+			schedule.Cancun = blobConfig
+		case config.PragueTime != nil && timestamp == *config.PragueTime && schedule.Prague == nil:
+			schedule.Prague = blobConfig
+		case config.PragueTime != nil && timestamp > *config.PragueTime:
+			if len(bpoForks) == 0 {
+				return nil, fmt.Errorf("blob schedule has more than 5 entries after prague")
+			}
+
+			*bpoForks[0].config = blobConfig
+			*bpoForks[0].time = &timestamp
+			bpoForks = bpoForks[1:]
+		default:
+			return nil, fmt.Errorf("blob schedule entry at timestamp %d does not match a fork", timestamp)
+		}
+	}
+
+	return schedule, nil
+}
+
+// transitionBlock returns the first set transition as fork block.
+func transitionBlock(transitions ...*math.HexOrDecimal64) *big.Int {
+	if transition := transitionTime(transitions...); transition != nil {
+		return new(big.Int).SetUint64(*transition)
+	}
+
+	return nil
+}
+
+// transitionTime returns the first set transition.
+func transitionTime(transitions ...*math.HexOrDecimal64) *uint64 {
+	for _, transition := range transitions {
+		if transition != nil {
+			value := uint64(*transition)
+			return &value
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package eth1
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+)
+
+// Storage layout of the deposit contract: branch[32] in slots 0-31, the
+// deposit_count in slot 32 and zero_hashes[32] in slots 33-64. The zero hashes
+// are set by the constructor, which does not run for genesis alloc contracts.
+const (
+	depositContractTreeDepth = 32
+	depositCountSlot         = depositContractTreeDepth
+	zeroHashesSlot           = depositContractTreeDepth + 1
+)
+
+// GetDepositContractAddress returns the deposit contract address of the
+// execution chain config, or clAddress (DEPOSIT_CONTRACT_ADDRESS of the
+// consensus config) if the chain config has none.
+func GetDepositContractAddress(genesis *core.Genesis, clAddress []byte) (common.Address, error) {
+	if genesis.Config != nil && genesis.Config.DepositContractAddress != (common.Address{}) {
+		elAddress := genesis.Config.DepositContractAddress
+		if len(clAddress) > 0 && !bytes.Equal(clAddress, elAddress[:]) {
+			return common.Address{}, fmt.Errorf("deposit contract address of the execution config (%s) does not match DEPOSIT_CONTRACT_ADDRESS (0x%x)", elAddress.String(), clAddress)
+		}
+
+		return elAddress, nil
+	}
+
+	if len(clAddress) != common.AddressLength {
+		return common.Address{}, fmt.Errorf("no deposit contract address in execution or consensus config")
+	}
+
+	return common.BytesToAddress(clAddress), nil
+}
+
+// SetDepositContractStorage sets the storage of the deposit contract in the
+// genesis alloc to the incremental merkle tree after depositCount deposits,
+// so get_deposit_root of the contract matches the deposit tree of the
+// consensus genesis. The contract code must be in the alloc already.
+func SetDepositContractStorage(genesis *core.Genesis, address common.Address, branch []phase0.Root, depositCount uint64) error {
+	if len(branch) != depositContractTreeDepth {
+		return fmt.Errorf("deposit contract branch has %d nodes, expected %d", len(branch), depositContractTreeDepth)
+	}
+
+	account, found := genesis.Alloc[address]
+	if !found || len(account.Code) == 0 {
+		return fmt.Errorf("deposit contract %s not found in genesis alloc", address.String())
+	}
+
+	storage := make(map[common.Hash]common.Hash, len(account.Storage)+2*depositContractTreeDepth+1)
+	for key, value := range account.Storage {
+		storage[key] = value
+	}
+
+	setSlot := func(slot int64, value common.Hash) {
+		key := common.BigToHash(big.NewInt(slot))
+		if value == (common.Hash{}) {
+			delete(storage, key)
+		} else {
+			storage[key] = value
+		}
+	}
+
+	zeroHash := common.Hash{}
+
+	for height := int64(0); height < depositContractTreeDepth; height++ {
+		setSlot(height, common.Hash(branch[height]))
+		setSlot(zeroHashesSlot+height, zeroHash)
+
+		zeroHash = sha256.Sum256(append(zeroHash[:], zeroHash[:]...))
+	}
+
+	setSlot(depositCountSlot, common.BigToHash(new(big.Int).SetUint64(depositCount)))
+
+	account.Storage = storage
+	genesis.Alloc[address] = account
+
+	return nil
+}
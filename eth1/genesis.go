@@ -2,24 +2,170 @@ package eth1
 
 import (
 	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"os"
+	"strings"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Execution genesis config formats.
+const (
+	// GenesisFormatAuto detects the format: Nethermind chainspecs by their
+	// engine and params sections, Besu genesis files by their Besu specific
+	// config fields. Everything else is decoded as geth genesis with a
+	// fallback to the Besu genesis format.
+	GenesisFormatAuto = "auto"
+
+	// GenesisFormatGeth is the geth genesis.json format.
+	GenesisFormatGeth = "geth"
+
+	// GenesisFormatBesu is the Besu genesis file format.
+	GenesisFormatBesu = "besu"
+
+	// GenesisFormatNethermind is the Nethermind chainspec format.
+	GenesisFormatNethermind = "nethermind"
 )
 
 func LoadEth1GenesisConfig(configPath string) (*core.Genesis, error) {
+	return LoadEth1GenesisConfigFormat(configPath, GenesisFormatAuto)
+}
+
+// LoadEth1GenesisConfigFormat loads the execution genesis config from
+// configPath in the given format and converts it into a geth genesis.
+func LoadEth1GenesisConfigFormat(configPath, format string) (*core.Genesis, error) {
 	eth1ConfData, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read eth1 config file: %v", err)
 	}
 
+	return ParseEth1GenesisConfig(eth1ConfData, format)
+}
+
+// ParseEth1GenesisConfig decodes an execution genesis config in the given
+// format into a geth genesis.
+func ParseEth1GenesisConfig(data []byte, format string) (*core.Genesis, error) {
+	switch format {
+	case GenesisFormatAuto, "":
+		if isNethermindChainspec(data) {
+			return ParseNethermindChainspec(data)
+		}
+
+		if isBesuGenesis(data) {
+			return ParseBesuGenesis(data)
+		}
+
+		genesis, err := parseGethGenesis(data)
+		if err == nil {
+			return genesis, nil
+		}
+
+		genesis, besuErr := ParseBesuGenesis(data)
+		if besuErr != nil {
+			return nil, fmt.Errorf("failed to decode eth1 config file as geth genesis (%v) or besu genesis (%v)", err, besuErr)
+		}
+
+		return genesis, nil
+	case GenesisFormatGeth:
+		return parseGethGenesis(data)
+	case GenesisFormatBesu:
+		return ParseBesuGenesis(data)
+	case GenesisFormatNethermind:
+		return ParseNethermindChainspec(data)
+	default:
+		return nil, fmt.Errorf("unknown eth1 config format %q (expected %s, %s, %s or %s)", format, GenesisFormatAuto, GenesisFormatGeth, GenesisFormatBesu, GenesisFormatNethermind)
+	}
+}
+
+func parseGethGenesis(data []byte) (*core.Genesis, error) {
 	var eth1Genesis core.Genesis
 
-	if err := json.NewDecoder(bytes.NewReader(eth1ConfData)).Decode(&eth1Genesis); err != nil {
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&eth1Genesis); err != nil {
 		return nil, fmt.Errorf("failed to decode eth1 config file: %v", err)
 	}
 
 	return &eth1Genesis, nil
 }
+
+// WriteEth1GenesisConfig writes the execution genesis config to configPath.
+func WriteEth1GenesisConfig(configPath string, genesis *core.Genesis) error {
+	eth1ConfData, err := json.MarshalIndent(genesis, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode eth1 config: %w", err)
+	}
+
+	if err := os.WriteFile(configPath, eth1ConfData, 0o644); err != nil { //nolint:gosec // no strict permissions needed
+		return fmt.Errorf("failed to write eth1 config file: %w", err)
+	}
+
+	return nil
+}
+
+// allocAccount is a genesis alloc account of the Besu and Nethermind formats.
+// Both accept short or unprefixed storage words, which geth rejects.
+type allocAccount struct {
+	Balance *math.HexOrDecimal256 `json:"balance"`
+	Nonce   math.HexOrDecimal64   `json:"nonce"`
+	Code    hexutil.Bytes         `json:"code"`
+	Storage map[string]string     `json:"storage"`
+}
+
+// toAccount converts the account into a geth alloc account.
+func (a *allocAccount) toAccount() (types.Account, error) {
+	account := types.Account{
+		Balance: new(big.Int),
+		Nonce:   uint64(a.Nonce),
+		Code:    a.Code,
+	}
+
+	if a.Balance != nil {
+		account.Balance = (*big.Int)(a.Balance)
+	}
+
+	if len(a.Storage) > 0 {
+		account.Storage = make(map[common.Hash]common.Hash, len(a.Storage))
+
+		for key, value := range a.Storage {
+			storageKey, err := parseStorageWord(key)
+			if err != nil {
+				return types.Account{}, fmt.Errorf("invalid storage key %q: %w", key, err)
+			}
+
+			storageValue, err := parseStorageWord(value)
+			if err != nil {
+				return types.Account{}, fmt.Errorf("invalid storage value %q: %w", value, err)
+			}
+
+			account.Storage[storageKey] = storageValue
+		}
+	}
+
+	return account, nil
+}
+
+// parseStorageWord parses a hex encoded storage key or value of up to 32
+// bytes, optionally 0x prefixed and left padded to 32 bytes.
+func parseStorageWord(value string) (common.Hash, error) {
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "0x"), "0X")
+	if len(value) > 2*common.HashLength {
+		return common.Hash{}, fmt.Errorf("longer than %d bytes", common.HashLength)
+	}
+
+	if len(value)%2 == 1 {
+		value = "0" + value
+	}
+
+	word, err := hex.DecodeString(value)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return common.BytesToHash(word), nil
+}
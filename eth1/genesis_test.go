@@ -0,0 +1,154 @@
+package eth1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestLoadEth1GenesisConfigFormat(t *testing.T) {
+	expected, err := LoadEth1GenesisConfigFormat("testdata/genesis.json", GenesisFormatGeth)
+	if err != nil {
+		t.Fatalf("failed to load geth genesis: %v", err)
+	}
+
+	// genesis block hash of the sample devnet as computed by geth, the besu
+	// and nethermind files hold the same devnet in their formats
+	expectedHash := common.HexToHash("0xd0eef7b6bb26ae0fa20e661810476844bf17df6a0636bf70edc5e05bb6acb89f")
+	if hash := expected.ToBlock().Hash(); hash != expectedHash {
+		t.Fatalf("expected geth genesis hash %s, got %s", expectedHash, hash)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		format string
+	}{
+		{name: "geth", path: "testdata/genesis.json", format: GenesisFormatAuto},
+		{name: "besu", path: "testdata/besu.json", format: GenesisFormatBesu},
+		{name: "besu auto", path: "testdata/besu.json", format: GenesisFormatAuto},
+		{name: "nethermind", path: "testdata/chainspec.json", format: GenesisFormatNethermind},
+		{name: "nethermind auto", path: "testdata/chainspec.json", format: GenesisFormatAuto},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			genesis, err := LoadEth1GenesisConfigFormat(test.path, test.format)
+			if err != nil {
+				t.Fatalf("failed to load genesis: %v", err)
+			}
+
+			if hash := genesis.ToBlock().Hash(); hash != expectedHash {
+				t.Fatalf("expected genesis hash %s, got %s", expectedHash, hash)
+			}
+
+			config := genesis.Config
+			if config.ChainID.Uint64() != 1337 || config.DepositContractAddress != common.HexToAddress("0x4242424242424242424242424242424242424242") {
+				t.Fatalf("expected chain id 1337 and the deposit contract, got %v and %s", config.ChainID, config.DepositContractAddress)
+			}
+
+			if config.PetersburgBlock == nil || config.LondonBlock == nil || config.TerminalTotalDifficulty == nil {
+				t.Fatalf("expected petersburg, london and terminal total difficulty to be set")
+			}
+
+			if config.PragueTime == nil || *config.PragueTime != 0 || config.OsakaTime == nil || *config.OsakaTime != 1760000000 {
+				t.Fatalf("expected prague at 0 and osaka at 1760000000, got %v and %v", config.PragueTime, config.OsakaTime)
+			}
+
+			if config.BPO1Time == nil || *config.BPO1Time != 1760100000 {
+				t.Fatalf("expected bpo1 at 1760100000, got %v", config.BPO1Time)
+			}
+
+			schedule := config.BlobScheduleConfig
+			if schedule == nil || schedule.Cancun == nil || schedule.Prague == nil || schedule.BPO1 == nil {
+				t.Fatalf("expected cancun, prague and bpo1 blob configs, got %+v", schedule)
+			}
+
+			if schedule.Cancun.Max != 6 || schedule.Prague.Max != 9 || schedule.BPO1.Max != 15 || schedule.BPO1.UpdateFraction != 8346193 {
+				t.Fatalf("unexpected blob schedule: cancun %s, prague %s, bpo1 %s", schedule.Cancun, schedule.Prague, schedule.BPO1)
+			}
+		})
+	}
+}
+
+func TestLoadEth1GenesisConfigFormat_Sepolia(t *testing.T) {
+	// genesis block hash of sepolia, as besu and nethermind report it on init
+	// with their bundled sepolia genesis, which the test files reproduce
+	expectedHash := common.HexToHash("0x25a5cc106eea7138acab33231d7160d69cb777ee0c2c553fcddf5138993e6dd9")
+
+	tests := []struct {
+		name   string
+		path   string
+		format string
+	}{
+		{name: "besu", path: "testdata/sepolia-besu.json", format: GenesisFormatBesu},
+		{name: "nethermind", path: "testdata/sepolia-chainspec.json", format: GenesisFormatNethermind},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			genesis, err := LoadEth1GenesisConfigFormat(test.path, test.format)
+			if err != nil {
+				t.Fatalf("failed to load genesis: %v", err)
+			}
+
+			if hash := genesis.ToBlock().Hash(); hash != expectedHash {
+				t.Fatalf("expected sepolia genesis hash %s, got %s", expectedHash, hash)
+			}
+
+			if genesis.Config.ChainID.Uint64() != 11155111 || genesis.Config.LondonBlock == nil || genesis.Config.ShanghaiTime == nil {
+				t.Fatalf("expected sepolia chain id, london and shanghai, got %+v", genesis.Config)
+			}
+		})
+	}
+}
+
+func TestParseNethermindChainspec_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "no chain id",
+			data: `{"engine": {}, "params": {}, "genesis": {}, "accounts": {}}`,
+			want: "has no chainId",
+		},
+		{
+			name: "constructor",
+			data: `{"engine": {}, "params": {"chainId": "0x1"}, "genesis": {}, "accounts": {"0x0000000000000000000000000000000000000001": {"constructor": "0x6000"}}}`,
+			want: "constructor accounts are not supported",
+		},
+		{
+			name: "blob schedule without fork",
+			data: `{"engine": {}, "params": {"chainId": "0x1", "blobSchedule": [{"timestamp": "0x10", "target": 3, "max": 6}]}, "genesis": {}, "accounts": {}}`,
+			want: "does not match a fork",
+		},
+		{
+			name: "invalid storage",
+			data: `{"engine": {}, "params": {"chainId": "0x1"}, "genesis": {}, "accounts": {"0x0000000000000000000000000000000000000001": {"balance": "0x1", "storage": {"0x01": "0xzz"}}}}`,
+			want: "invalid storage value",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseEth1GenesisConfig([]byte(test.data), GenesisFormatAuto)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("expected error containing %q, got %v", test.want, err)
+			}
+		})
+	}
+}
+
+func TestParseEth1GenesisConfig_Invalid(t *testing.T) {
+	_, err := ParseEth1GenesisConfig([]byte(`{"config": {"chainId": 1}}`), GenesisFormatAuto)
+	if err == nil || !strings.Contains(err.Error(), "geth genesis") || !strings.Contains(err.Error(), "besu genesis") {
+		t.Fatalf("expected error naming both formats, got %v", err)
+	}
+
+	if _, err := ParseEth1GenesisConfig([]byte(`{}`), "parity"); err == nil {
+		t.Fatalf("expected error for unknown format")
+	}
+}
@@ -0,0 +1,140 @@
+package eth1
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// LoadGenesisHeaderFromFile loads a precomputed execution genesis header from
+// a file in any of the formats supported by ParseGenesisHeader.
+func LoadGenesisHeaderFromFile(filePath string) (*types.Header, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read genesis header: %w", err)
+	}
+
+	return ParseGenesisHeader(data)
+}
+
+// ParseGenesisHeader parses a precomputed execution genesis header, given as
+// header JSON, block JSON, JSON-RPC response of eth_getBlockByNumber("0x0")
+// or RLP encoded genesis block. If the JSON has a hash field, it must match
+// the hash of the header fields.
+func ParseGenesisHeader(data []byte) (*types.Header, error) {
+	block, _, err := ParseBlock(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse genesis header: %w", err)
+	}
+
+	if block.NumberU64() != 0 {
+		return nil, fmt.Errorf("not a genesis header, block number is %d", block.NumberU64())
+	}
+
+	hash, err := declaredBlockHash(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if hash != nil && *hash != block.Hash() {
+		return nil, fmt.Errorf("genesis header hash %s does not match the hash of the header fields %s", hash.String(), block.Hash().String())
+	}
+
+	return block.Header(), nil
+}
+
+// declaredBlockHash returns the hash field of a block or header JSON, or of
+// the result of a JSON-RPC response. It returns nil for RLP data and JSON
+// without a hash field.
+func declaredBlockHash(data []byte) (*common.Hash, error) {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		return nil, nil
+	}
+
+	if result, found := fields["result"]; found {
+		fields = nil
+
+		// a hex RLP result has no hash field
+		if json.Unmarshal(result, &fields) != nil {
+			return nil, nil
+		}
+	}
+
+	hashData, found := fields["hash"]
+	if !found {
+		return nil, nil
+	}
+
+	var hash common.Hash
+	if err := json.Unmarshal(hashData, &hash); err != nil {
+		return nil, fmt.Errorf("invalid genesis header hash: %w", err)
+	}
+
+	return &hash, nil
+}
+
+// GetGenesisBlockWithHeader returns the genesis block of genesis with the
+// state root of a precomputed genesis header. Computing the state root
+// requires building the trie of the whole alloc, which is slow and memory
+// intensive for huge allocs. All other header fields are derived from the
+// genesis config and must match the precomputed header.
+func GetGenesisBlockWithHeader(genesis *core.Genesis, header *types.Header) (*types.Block, error) {
+	emptyGenesis := *genesis
+	emptyGenesis.Alloc = types.GenesisAlloc{}
+
+	block := emptyGenesis.ToBlock()
+
+	expected := block.Header()
+	expected.Root = header.Root
+
+	if expected.Hash() != header.Hash() {
+		return nil, fmt.Errorf("genesis header %s does not match the execution genesis config, mismatching fields: %s", header.Hash().String(), strings.Join(headerFieldDiff(expected, header), ", "))
+	}
+
+	return block.WithSeal(expected), nil
+}
+
+// headerFieldDiff returns the names of the JSON fields that differ between
+// header a and b.
+func headerFieldDiff(a, b *types.Header) []string {
+	aFields, aErr := headerFields(a)
+	bFields, bErr := headerFields(b)
+
+	if aErr != nil || bErr != nil {
+		return []string{"unknown"}
+	}
+
+	diff := []string{}
+
+	for key, value := range aFields {
+		if key != "hash" && string(value) != string(bFields[key]) {
+			diff = append(diff, key)
+		}
+	}
+
+	for key := range bFields {
+		if _, found := aFields[key]; !found {
+			diff = append(diff, key)
+		}
+	}
+
+	sort.Strings(diff)
+
+	return diff
+}
+
+func headerFields(header *types.Header) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseJSONObject(data)
+}
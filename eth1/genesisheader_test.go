@@ -0,0 +1,113 @@
+package eth1
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestGetGenesisBlockWithHeader(t *testing.T) {
+	genesis, err := LoadEth1GenesisConfig("testdata/genesis.json")
+	if err != nil {
+		t.Fatalf("failed to load genesis: %v", err)
+	}
+
+	expected := genesis.ToBlock()
+
+	headerJSON, err := json.Marshal(expected.Header())
+	if err != nil {
+		t.Fatalf("failed to encode header: %v", err)
+	}
+
+	blockRLP, err := rlp.EncodeToBytes(expected)
+	if err != nil {
+		t.Fatalf("failed to encode block: %v", err)
+	}
+
+	inputs := map[string][]byte{
+		"header JSON":  headerJSON,
+		"RPC response": []byte(`{"jsonrpc": "2.0", "id": 1, "result": ` + string(headerJSON) + `}`),
+		"binary RLP":   blockRLP,
+	}
+
+	for name, data := range inputs {
+		t.Run(name, func(t *testing.T) {
+			header, err := ParseGenesisHeader(data)
+			if err != nil {
+				t.Fatalf("failed to parse genesis header: %v", err)
+			}
+
+			block, err := GetGenesisBlockWithHeader(genesis, header)
+			if err != nil {
+				t.Fatalf("failed to get genesis block: %v", err)
+			}
+
+			if block.Hash() != expected.Hash() || block.Root() != expected.Root() {
+				t.Fatalf("expected genesis block %s, got %s", expected.Hash(), block.Hash())
+			}
+
+			if block.Withdrawals() == nil {
+				t.Fatalf("expected empty withdrawals of the shanghai genesis block")
+			}
+		})
+	}
+}
+
+func TestGetGenesisBlockWithHeader_Mismatch(t *testing.T) {
+	genesis, err := LoadEth1GenesisConfig("testdata/genesis.json")
+	if err != nil {
+		t.Fatalf("failed to load genesis: %v", err)
+	}
+
+	header := genesis.ToBlock().Header()
+	header.GasLimit++
+	header.BaseFee = new(big.Int).Add(header.BaseFee, big.NewInt(1))
+
+	_, err = GetGenesisBlockWithHeader(genesis, header)
+	if err == nil || !strings.Contains(err.Error(), "baseFeePerGas, gasLimit") {
+		t.Fatalf("expected error naming the mismatching fields, got %v", err)
+	}
+}
+
+func TestParseGenesisHeader_Errors(t *testing.T) {
+	genesis, err := LoadEth1GenesisConfig("testdata/genesis.json")
+	if err != nil {
+		t.Fatalf("failed to load genesis: %v", err)
+	}
+
+	header := genesis.ToBlock().Header()
+
+	headerFields, err := headerFields(header)
+	if err != nil {
+		t.Fatalf("failed to encode header: %v", err)
+	}
+
+	headerFields["hash"] = json.RawMessage(`"` + common.Hash{1}.Hex() + `"`)
+
+	wrongHash, err := json.Marshal(headerFields)
+	if err != nil {
+		t.Fatalf("failed to encode header: %v", err)
+	}
+
+	_, err = ParseGenesisHeader(wrongHash)
+	if err == nil || !strings.Contains(err.Error(), "does not match the hash of the header fields") {
+		t.Fatalf("expected hash mismatch error, got %v", err)
+	}
+
+	header.Number = big.NewInt(1)
+
+	blockRLP, err := rlp.EncodeToBytes(types.NewBlockWithHeader(header))
+	if err != nil {
+		t.Fatalf("failed to encode block: %v", err)
+	}
+
+	_, err = ParseGenesisHeader(blockRLP)
+	if err == nil || !strings.Contains(err.Error(), "not a genesis header") {
+		t.Fatalf("expected error for block 1, got %v", err)
+	}
+}
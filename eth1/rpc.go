@@ -3,11 +3,21 @@ package eth1
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/sirupsen/logrus"
 )
 
 type JSONData struct {
@@ -16,24 +26,183 @@ type JSONData struct {
 	Result  json.RawMessage `json:"result"`
 }
 
+// RPCOptions configures how GetBlockFromRPCWithOptions fetches a block.
+type RPCOptions struct {
+	// Block selects the block by number, hash or tag (latest, safe or
+	// finalized). Empty selects the latest block.
+	Block string
+
+	// Timeout limits every request attempt (no limit if zero).
+	Timeout time.Duration
+
+	// Retries is the number of attempts after a failed one, RetryDelay the
+	// pause before every retry.
+	Retries    int
+	RetryDelay time.Duration
+
+	// Headers are sent with every request, e.g. auth tokens of hosted RPCs.
+	Headers http.Header
+}
+
+// blockSelector is a parsed RPCOptions.Block.
+type blockSelector struct {
+	hash   *common.Hash
+	number *big.Int
+}
+
+func (s *blockSelector) String() string {
+	switch {
+	case s.hash != nil:
+		return s.hash.String()
+	case s.number == nil:
+		return "latest"
+	case s.number.Sign() < 0:
+		return rpc.BlockNumber(s.number.Int64()).String()
+	default:
+		return s.number.String()
+	}
+}
+
+// RedactRPCURL returns the scheme and host of an RPC URL without credentials,
+// path and query, which often hold API keys of hosted RPCs. Other endpoints,
+// like IPC paths, are returned as is and invalid URLs as empty string.
+func RedactRPCURL(host string) string {
+	rpcURL, err := url.Parse(host)
+	if err != nil {
+		return ""
+	}
+
+	if rpcURL.Host == "" {
+		return host
+	}
+
+	return rpcURL.Scheme + "://" + rpcURL.Host
+}
+
 func GetBlockFromRPC(ctx context.Context, host string) (*types.Block, error) {
-	client, err := ethclient.Dial(host)
+	return GetBlockFromRPCWithOptions(ctx, host, &RPCOptions{})
+}
+
+// GetBlockFromRPCWithOptions fetches the block selected by opts.Block from the
+// execution RPC at host. Failed requests are retried, except for blocks the
+// RPC does not know.
+func GetBlockFromRPCWithOptions(ctx context.Context, host string, opts *RPCOptions) (*types.Block, error) {
+	selector, err := parseBlockSelector(opts.Block)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcClient, err := rpc.DialOptions(ctx, host, rpc.WithHeaders(opts.Headers))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create the ETH client %s", err)
 	}
 
-	// Get the latest block
-	blockNumberUint64, err := client.BlockNumber(ctx)
+	client := ethclient.NewClient(rpcClient)
+	defer client.Close()
+
+	for attempt := 0; ; attempt++ {
+		block, err := getBlock(ctx, client, selector, opts.Timeout)
+		if err == nil {
+			return block, nil
+		}
+
+		if errors.Is(err, ethereum.NotFound) {
+			return nil, fmt.Errorf("block %s not found", selector.String())
+		}
+
+		if attempt >= opts.Retries || ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to get the ETH block %s", err)
+		}
+
+		logrus.Warnf("failed to get block %s (attempt %d/%d): %v", selector.String(), attempt+1, opts.Retries+1, err)
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to get the ETH block %s", ctx.Err())
+		case <-time.After(opts.RetryDelay):
+		}
+	}
+}
+
+// getBlock fetches the selected block with a single request and checks that
+// it is the requested one.
+func getBlock(ctx context.Context, client *ethclient.Client, selector *blockSelector, timeout time.Duration) (*types.Block, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if selector.hash != nil {
+		block, err := client.BlockByHash(ctx, *selector.hash)
+		if err != nil {
+			return nil, err
+		}
+
+		// a different hash means the block has fields the block type drops
+		if block.Hash() != *selector.hash {
+			return nil, fmt.Errorf("block hash mismatch: got %s, expected %s", block.Hash().String(), selector.hash.String())
+		}
+
+		return block, nil
+	}
+
+	block, err := client.BlockByNumber(ctx, selector.number)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get the block number %s", err)
+		return nil, err
+	}
+
+	if selector.number != nil && selector.number.Sign() >= 0 && block.Number().Cmp(selector.number) != 0 {
+		return nil, fmt.Errorf("block number mismatch: got %s, expected %s", block.Number().String(), selector.number.String())
 	}
 
-	blockNumberBigint := new(big.Int).SetUint64(blockNumberUint64)
+	return block, nil
+}
+
+// parseBlockSelector parses a block number (decimal or 0x prefixed hex), a
+// 32 byte block hash or a block tag.
+func parseBlockSelector(block string) (*blockSelector, error) {
+	block = strings.TrimSpace(block)
+
+	switch strings.ToLower(block) {
+	case "", "latest":
+		return &blockSelector{}, nil
+	case "safe":
+		return &blockSelector{number: big.NewInt(int64(rpc.SafeBlockNumber))}, nil
+	case "finalized":
+		return &blockSelector{number: big.NewInt(int64(rpc.FinalizedBlockNumber))}, nil
+	}
+
+	if strings.HasPrefix(block, "0x") && len(block) == 2+2*common.HashLength {
+		hash := common.HexToHash(block)
+		if !strings.EqualFold(hash.Hex(), block) {
+			return nil, fmt.Errorf("invalid block hash: %s", block)
+		}
+
+		return &blockSelector{hash: &hash}, nil
+	}
 
-	resultBlock, err := client.BlockByNumber(ctx, blockNumberBigint)
+	number, err := strconv.ParseUint(block, 0, 64)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get the ETH block %s", err)
+		return nil, fmt.Errorf("invalid block %q: expected a block number, hash or tag (latest, safe or finalized)", block)
+	}
+
+	return &blockSelector{number: new(big.Int).SetUint64(number)}, nil
+}
+
+// ParseRPCHeaders parses HTTP headers given as "Name: value" or "Name=value".
+func ParseRPCHeaders(headers []string) (http.Header, error) {
+	parsed := http.Header{}
+
+	for _, header := range headers {
+		sep := strings.IndexAny(header, ":=")
+		if sep < 0 || strings.TrimSpace(header[:sep]) == "" {
+			return nil, fmt.Errorf("invalid header %q: expected \"Name: value\"", header)
+		}
+
+		parsed.Add(strings.TrimSpace(header[:sep]), strings.TrimSpace(header[sep+1:]))
 	}
 
-	return resultBlock, nil
+	return parsed, nil
 }
@@ -0,0 +1,172 @@
+package eth1
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethpandaops/go-eth2-client/spec"
+)
+
+// Handling modes of the system contracts required by the genesis fork.
+const (
+	// SystemContractsCheck warns about missing system contracts and system
+	// contracts with a different code.
+	SystemContractsCheck = "check"
+
+	// SystemContractsStrict fails on all missing system contracts and system
+	// contracts with a different code.
+	SystemContractsStrict = "strict"
+
+	// SystemContractsInsert inserts the missing system contracts with their
+	// canonical code before checking them.
+	SystemContractsInsert = "insert"
+
+	// SystemContractsSkip skips the system contract checks.
+	SystemContractsSkip = "skip"
+)
+
+// SystemContract is a contract that should be predeployed in the genesis
+// alloc if the chain starts at or after Fork. Required contracts are needed
+// to process blocks: the requests are read with a system call that fails if
+// the contract has no code, unless the contract is deployed before the first
+// block. The system calls of the other contracts are no-ops without code.
+type SystemContract struct {
+	Name     string
+	Fork     spec.DataVersion
+	Address  common.Address
+	Code     []byte
+	Required bool
+}
+
+// SystemContracts lists the predeploys of the consensus forks.
+var SystemContracts = []*SystemContract{
+	{Name: "beacon roots (EIP-4788)", Fork: spec.DataVersionDeneb, Address: params.BeaconRootsAddress, Code: params.BeaconRootsCode},
+	{Name: "history storage (EIP-2935)", Fork: spec.DataVersionElectra, Address: params.HistoryStorageAddress, Code: params.HistoryStorageCode},
+	{Name: "withdrawal requests (EIP-7002)", Fork: spec.DataVersionElectra, Address: params.WithdrawalQueueAddress, Code: params.WithdrawalQueueCode, Required: true},
+	{Name: "consolidation requests (EIP-7251)", Fork: spec.DataVersionElectra, Address: params.ConsolidationQueueAddress, Code: params.ConsolidationQueueCode, Required: true},
+}
+
+// GetSystemContracts returns the system contracts of a chain that starts at
+// the given consensus fork.
+func GetSystemContracts(version spec.DataVersion) []*SystemContract {
+	contracts := []*SystemContract{}
+
+	for _, contract := range SystemContracts {
+		if version >= contract.Fork {
+			contracts = append(contracts, contract)
+		}
+	}
+
+	return contracts
+}
+
+// SystemContractIssue is a system contract that is missing in the genesis
+// alloc or deployed with a different code.
+type SystemContractIssue struct {
+	Contract *SystemContract
+	Missing  bool
+	CodeHash common.Hash
+}
+
+func (i *SystemContractIssue) String() string {
+	if i.Missing {
+		return fmt.Sprintf("%s contract %s is missing in the genesis alloc", i.Contract.Name, i.Contract.Address.String())
+	}
+
+	return fmt.Sprintf("%s contract %s has code hash %s, expected %s", i.Contract.Name, i.Contract.Address.String(), i.CodeHash.String(), crypto.Keccak256Hash(i.Contract.Code).String())
+}
+
+// CheckSystemContracts checks that the contracts are deployed with their
+// canonical code in the genesis alloc. Accounts without code count as missing.
+func CheckSystemContracts(genesis *core.Genesis, contracts []*SystemContract) []*SystemContractIssue {
+	issues := []*SystemContractIssue{}
+
+	for _, contract := range contracts {
+		account, found := genesis.Alloc[contract.Address]
+		if !found || len(account.Code) == 0 {
+			issues = append(issues, &SystemContractIssue{
+				Contract: contract,
+				Missing:  true,
+			})
+
+			continue
+		}
+
+		if !bytes.Equal(account.Code, contract.Code) {
+			issues = append(issues, &SystemContractIssue{
+				Contract: contract,
+				CodeHash: crypto.Keccak256Hash(account.Code),
+			})
+		}
+	}
+
+	return issues
+}
+
+// InsertSystemContracts deploys the missing contracts with their canonical
+// code to the genesis alloc, with nonce 1 like the deployment transactions of
+// the EIPs. Accounts without code keep their balance. It returns the inserted
+// contracts, contracts with a different code are not replaced.
+func InsertSystemContracts(genesis *core.Genesis, contracts []*SystemContract) []*SystemContract {
+	inserted := []*SystemContract{}
+
+	if genesis.Alloc == nil {
+		genesis.Alloc = types.GenesisAlloc{}
+	}
+
+	for _, contract := range contracts {
+		account, found := genesis.Alloc[contract.Address]
+		if found && len(account.Code) > 0 {
+			continue
+		}
+
+		balance := account.Balance
+		if balance == nil {
+			balance = new(big.Int)
+		}
+
+		genesis.Alloc[contract.Address] = types.Account{
+			Balance: balance,
+			Nonce:   1,
+			Code:    common.CopyBytes(contract.Code),
+		}
+
+		inserted = append(inserted, contract)
+	}
+
+	return inserted
+}
+
+// HandleSystemContracts handles the system contracts of a chain that starts at
+// the given consensus fork in mode: insert mode inserts the missing contracts
+// first, all modes except skip check the contracts. It returns the inserted
+// contracts and the remaining issues. Issues only fail strict mode.
+func HandleSystemContracts(genesis *core.Genesis, version spec.DataVersion, mode string) ([]*SystemContract, []*SystemContractIssue, error) {
+	switch mode {
+	case SystemContractsSkip:
+		return nil, nil, nil
+	case SystemContractsCheck, SystemContractsStrict, SystemContractsInsert:
+	default:
+		return nil, nil, fmt.Errorf("unsupported system contracts mode %q", mode)
+	}
+
+	contracts := GetSystemContracts(version)
+
+	var inserted []*SystemContract
+	if mode == SystemContractsInsert {
+		inserted = InsertSystemContracts(genesis, contracts)
+	}
+
+	issues := CheckSystemContracts(genesis, contracts)
+	if mode == SystemContractsStrict && len(issues) > 0 {
+		return inserted, issues, fmt.Errorf("execution genesis failed the system contract check: %s", issues[0])
+	}
+
+	return inserted, issues, nil
+}
@@ -0,0 +1,112 @@
+package eth1
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethpandaops/go-eth2-client/spec"
+)
+
+func TestGetSystemContracts(t *testing.T) {
+	tests := []struct {
+		version spec.DataVersion
+		count   int
+	}{
+		{version: spec.DataVersionCapella, count: 0},
+		{version: spec.DataVersionDeneb, count: 1},
+		{version: spec.DataVersionElectra, count: 4},
+		{version: spec.DataVersionFulu, count: 4},
+	}
+
+	for _, test := range tests {
+		if contracts := GetSystemContracts(test.version); len(contracts) != test.count {
+			t.Fatalf("expected %d system contracts for %s, got %d", test.count, test.version, len(contracts))
+		}
+	}
+
+	// only the request contracts fail blocks without code
+	for _, contract := range SystemContracts {
+		required := contract.Address == params.WithdrawalQueueAddress || contract.Address == params.ConsolidationQueueAddress
+		if contract.Required != required {
+			t.Fatalf("expected %s contract required: %v", contract.Name, required)
+		}
+	}
+}
+
+func TestCheckSystemContracts(t *testing.T) {
+	genesis, err := LoadEth1GenesisConfig("testdata/genesis.json")
+	if err != nil {
+		t.Fatalf("failed to load genesis: %v", err)
+	}
+
+	contracts := GetSystemContracts(spec.DataVersionElectra)
+
+	// a funded withdrawal request address without code and a different code
+	// at the consolidation request address
+	genesis.Alloc[params.WithdrawalQueueAddress] = types.Account{Balance: big.NewInt(1)}
+	genesis.Alloc[params.ConsolidationQueueAddress] = types.Account{Balance: new(big.Int), Code: []byte{0x00}}
+
+	issues := CheckSystemContracts(genesis, contracts)
+	if len(issues) != 4 {
+		t.Fatalf("expected 4 issues, got %d", len(issues))
+	}
+
+	if !issues[0].Missing || issues[3].Missing || !strings.Contains(issues[3].String(), "has code hash") {
+		t.Fatalf("expected 3 missing contracts and a code mismatch, got %v", issues)
+	}
+
+	inserted := InsertSystemContracts(genesis, contracts)
+	if len(inserted) != 3 {
+		t.Fatalf("expected 3 inserted contracts, got %d", len(inserted))
+	}
+
+	if account := genesis.Alloc[params.WithdrawalQueueAddress]; account.Nonce != 1 || account.Balance.Int64() != 1 {
+		t.Fatalf("expected withdrawal request contract with nonce 1 and the previous balance, got %+v", account)
+	}
+
+	issues = CheckSystemContracts(genesis, contracts)
+	if len(issues) != 1 || issues[0].Contract.Address != params.ConsolidationQueueAddress {
+		t.Fatalf("expected only the consolidation request code mismatch, got %v", issues)
+	}
+}
+
+func TestHandleSystemContracts(t *testing.T) {
+	tests := []struct {
+		mode     string
+		inserted int
+		issues   int
+		wantErr  string
+	}{
+		// existing electra genesis configs without the predeploys keep working
+		{mode: SystemContractsCheck, issues: 4},
+		{mode: SystemContractsStrict, issues: 4, wantErr: "failed the system contract check"},
+		{mode: SystemContractsInsert, inserted: 4},
+		{mode: SystemContractsSkip},
+		{mode: "fix", wantErr: "unsupported system contracts mode"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.mode, func(t *testing.T) {
+			genesis, err := LoadEth1GenesisConfig("testdata/genesis.json")
+			if err != nil {
+				t.Fatalf("failed to load genesis: %v", err)
+			}
+
+			inserted, issues, err := HandleSystemContracts(genesis, spec.DataVersionElectra, test.mode)
+
+			switch {
+			case test.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)):
+				t.Fatalf("expected error to contain %q, got %v", test.wantErr, err)
+			}
+
+			if len(inserted) != test.inserted || len(issues) != test.issues {
+				t.Fatalf("expected %d inserted contracts and %d issues, got %d and %d", test.inserted, test.issues, len(inserted), len(issues))
+			}
+		})
+	}
+}
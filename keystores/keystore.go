@@ -0,0 +1,161 @@
+package keystores
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// StandardPBKDF2Rounds is the PBKDF2 iteration count recommended by EIP-2335.
+	StandardPBKDF2Rounds = 262144
+
+	// InsecurePBKDF2Rounds makes keystores cheap to generate and decrypt. Only
+	// meant for throw-away devnets with many validators.
+	InsecurePBKDF2Rounds = 16
+)
+
+// Keystore is an EIP-2335 (version 4) keystore.
+type Keystore struct {
+	Crypto      *KeystoreCrypto `json:"crypto"`
+	Description string          `json:"description"`
+	Pubkey      string          `json:"pubkey"`
+	Path        string          `json:"path"`
+	UUID        string          `json:"uuid"`
+	Version     uint            `json:"version"`
+}
+
+// KeystoreCrypto holds the kdf, checksum and cipher modules of a keystore.
+type KeystoreCrypto struct {
+	KDF      *KeystoreModule `json:"kdf"`
+	Checksum *KeystoreModule `json:"checksum"`
+	Cipher   *KeystoreModule `json:"cipher"`
+}
+
+type KeystoreModule struct {
+	Function string         `json:"function"`
+	Params   map[string]any `json:"params"`
+	Message  string         `json:"message"`
+}
+
+// NewKeystore encrypts a BLS secret key with password into an EIP-2335
+// keystore, using PBKDF2 with the given number of rounds and a random salt and IV.
+func NewKeystore(secretKey, pubkey []byte, path, password string, rounds int) (*Keystore, error) {
+	keystoreCrypto, err := EncryptSecret(secretKey, password, rounds)
+	if err != nil {
+		return nil, err
+	}
+
+	uuid, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Keystore{
+		Crypto:  keystoreCrypto,
+		Pubkey:  hex.EncodeToString(pubkey),
+		Path:    path,
+		UUID:    uuid,
+		Version: 4,
+	}, nil
+}
+
+// EncryptSecret encrypts secret as described by EIP-2335 with a random salt
+// and IV.
+func EncryptSecret(secret []byte, password string, rounds int) (*KeystoreCrypto, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	iv := make([]byte, 16)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, fmt.Errorf("failed to generate iv: %w", err)
+	}
+
+	return encryptSecret(secret, password, salt, iv, rounds)
+}
+
+func encryptSecret(secret []byte, password string, salt, iv []byte, rounds int) (*KeystoreCrypto, error) {
+	decryptionKey, err := pbkdf2.Key(sha256.New, processPassword(password), salt, rounds, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive decryption key: %w", err)
+	}
+
+	block, err := aes.NewCipher(decryptionKey[:16])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	cipherMessage := make([]byte, len(secret))
+	cipher.NewCTR(block, iv).XORKeyStream(cipherMessage, secret)
+
+	checksum := sha256.New()
+	checksum.Write(decryptionKey[16:32])
+	checksum.Write(cipherMessage)
+
+	return &KeystoreCrypto{
+		KDF: &KeystoreModule{
+			Function: "pbkdf2",
+			Params: map[string]any{
+				"dklen": 32,
+				"c":     rounds,
+				"prf":   "hmac-sha256",
+				"salt":  hex.EncodeToString(salt),
+			},
+		},
+		Checksum: &KeystoreModule{
+			Function: "sha256",
+			Params:   map[string]any{},
+			Message:  hex.EncodeToString(checksum.Sum(nil)),
+		},
+		Cipher: &KeystoreModule{
+			Function: "aes-128-ctr",
+			Params: map[string]any{
+				"iv": hex.EncodeToString(iv),
+			},
+			Message: hex.EncodeToString(cipherMessage),
+		},
+	}, nil
+}
+
+// processPassword normalizes a password with NFKD and strips the control
+// codes as required by EIP-2335.
+func processPassword(password string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || (r >= 0x7f && r <= 0x9f) {
+			return -1
+		}
+		return r
+	}, norm.NFKD.String(password))
+}
+
+// newPassword returns a random 32 character hex password.
+func newPassword() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate uuid: %w", err)
+	}
+
+	buf[6] = (buf[6] & 0x0f) | 0x40
+	buf[8] = (buf[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:16]), nil
+}
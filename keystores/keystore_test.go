@@ -0,0 +1,115 @@
+package keystores
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+// decryptSecret reverses encryptSecret for keystores using pbkdf2.
+func decryptSecret(t *testing.T, keystoreCrypto *KeystoreCrypto, password string) []byte {
+	t.Helper()
+
+	saltStr, ok := keystoreCrypto.KDF.Params["salt"].(string)
+	if !ok {
+		t.Fatalf("missing kdf salt")
+	}
+
+	ivStr, ok := keystoreCrypto.Cipher.Params["iv"].(string)
+	if !ok {
+		t.Fatalf("missing cipher iv")
+	}
+
+	rounds, ok := keystoreCrypto.KDF.Params["c"].(int)
+	if !ok {
+		t.Fatalf("missing kdf rounds")
+	}
+
+	salt, _ := hex.DecodeString(saltStr)
+	iv, _ := hex.DecodeString(ivStr)
+	cipherMessage, _ := hex.DecodeString(keystoreCrypto.Cipher.Message)
+
+	decryptionKey, err := pbkdf2.Key(sha256.New, processPassword(password), salt, rounds, 32)
+	if err != nil {
+		t.Fatalf("failed to derive decryption key: %v", err)
+	}
+
+	checksum := sha256.Sum256(append(decryptionKey[16:32:32], cipherMessage...))
+	if hex.EncodeToString(checksum[:]) != keystoreCrypto.Checksum.Message {
+		t.Fatalf("checksum mismatch")
+	}
+
+	block, err := aes.NewCipher(decryptionKey[:16])
+	if err != nil {
+		t.Fatalf("failed to create cipher: %v", err)
+	}
+
+	secret := make([]byte, len(cipherMessage))
+	cipher.NewCTR(block, iv).XORKeyStream(secret, cipherMessage)
+
+	return secret
+}
+
+// eip2335Password is the password of the EIP-2335 test vectors. It is
+// "testpassword🔑" once NFKD normalized.
+const eip2335Password = "\U0001D531\U0001D522\U0001D530\U0001D531\U0001D52D\U0001D51E\U0001D530\U0001D530\U0001D534\U0001D52C\U0001D52F\U0001D521\U0001F511"
+
+func TestProcessPassword(t *testing.T) {
+	tests := []struct {
+		password string
+		want     string
+	}{
+		{password: "plainpassword", want: "plainpassword"},
+		{password: "pass\x00word\n\x7f\u0085", want: "password"},
+		{password: eip2335Password, want: "testpassword\U0001F511"},
+	}
+
+	for _, test := range tests {
+		if got := processPassword(test.password); got != test.want {
+			t.Fatalf("processPassword(%q) = %q, want %q", test.password, got, test.want)
+		}
+	}
+}
+
+func TestEncryptSecret_EIP2335Vector(t *testing.T) {
+	// pbkdf2 test vector from EIP-2335
+	secret, _ := hex.DecodeString("000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f")
+	salt, _ := hex.DecodeString("d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3")
+	iv, _ := hex.DecodeString("264daa3f303d7259501c93d997d84fe6")
+
+	keystoreCrypto, err := encryptSecret(secret, eip2335Password, salt, iv, 262144)
+	if err != nil {
+		t.Fatalf("failed to encrypt secret: %v", err)
+	}
+
+	if keystoreCrypto.Checksum.Message != "8a9f5d9912ed7e75ea794bc5a89bca5f193721d30868ade6f73043c6ea6febf1" {
+		t.Fatalf("unexpected checksum %s", keystoreCrypto.Checksum.Message)
+	}
+
+	if keystoreCrypto.Cipher.Message != "cee03fde2af33149775b7223e7845e4fb2c8ae1792e5f99fe9ecf474cc8c16ad" {
+		t.Fatalf("unexpected cipher message %s", keystoreCrypto.Cipher.Message)
+	}
+}
+
+func TestNewKeystore_RoundTrip(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x42}, 32)
+	pubkey := bytes.Repeat([]byte{0xaa}, 48)
+
+	keystore, err := NewKeystore(secret, pubkey, "m/12381/3600/0/0/0", "pass\u0007word", InsecurePBKDF2Rounds)
+	if err != nil {
+		t.Fatalf("failed to create keystore: %v", err)
+	}
+
+	if keystore.Version != 4 || keystore.Pubkey != hex.EncodeToString(pubkey) || len(keystore.UUID) != 36 {
+		t.Fatalf("unexpected keystore metadata: %+v", keystore)
+	}
+
+	// control codes are stripped from the password
+	if got := decryptSecret(t, keystore.Crypto, "password"); !bytes.Equal(got, secret) {
+		t.Fatalf("decrypted secret 0x%x, want 0x%x", got, secret)
+	}
+}
@@ -0,0 +1,208 @@
+package keystores
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ethpandaops/eth-beacon-genesis/validators"
+)
+
+// Options controls how keystores are written.
+type Options struct {
+	// Password is used for every keystore and the Prysm wallet. A random
+	// password is generated per keystore if empty.
+	Password string
+
+	// PBKDF2Rounds is the PBKDF2 iteration count (defaults to StandardPBKDF2Rounds).
+	PBKDF2Rounds int
+}
+
+// prysmAccountStore is the plaintext of the Prysm all-accounts keystore.
+type prysmAccountStore struct {
+	PrivateKeys [][]byte `json:"private_keys"`
+	PublicKeys  [][]byte `json:"public_keys"`
+}
+
+// prysmKeystore is the Prysm variant of the keystore container.
+type prysmKeystore struct {
+	Crypto  *KeystoreCrypto `json:"crypto"`
+	ID      string          `json:"uuid"`
+	Version uint            `json:"version"`
+	Name    string          `json:"name"`
+}
+
+// WriteKeystores encrypts keys into EIP-2335 keystores and writes them to
+// outputDir in the layouts expected by the supported validator clients:
+//
+//	keys/<pubkey>/voting-keystore.json                Lighthouse and Lodestar keystores
+//	secrets/<pubkey>                                  keystore passwords for Lighthouse, Lodestar and Nimbus
+//	nimbus-keys/<pubkey>/keystore.json                Nimbus keystores
+//	teku-keys/<pubkey>.json                           Teku keystores
+//	teku-secrets/<pubkey>.txt                         Teku keystore passwords
+//	prysm/direct/accounts/all-accounts.keystore.json  Prysm wallet
+//	prysm/wallet-password.txt                         Prysm wallet password
+//	pubkeys.json                                      list of all written pubkeys
+//
+// outputDir must not exist yet or be empty, so keys are never mixed with the
+// output of a previous run.
+func WriteKeystores(outputDir string, keys []*validators.SigningKey, opts *Options) error {
+	if entries, err := os.ReadDir(outputDir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("output directory %s is not empty", outputDir)
+	}
+
+	rounds := opts.PBKDF2Rounds
+	if rounds == 0 {
+		rounds = StandardPBKDF2Rounds
+	}
+
+	for _, dir := range []string{"keys", "secrets", "nimbus-keys", "teku-keys", "teku-secrets", "prysm/direct/accounts"} {
+		if err := os.MkdirAll(filepath.Join(outputDir, dir), 0o700); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	var g errgroup.Group
+
+	g.SetLimit(runtime.NumCPU())
+
+	for _, key := range keys {
+		g.Go(func() error {
+			return writeKeystore(outputDir, key, opts.Password, rounds)
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	logrus.Infof("wrote %d keystores", len(keys))
+
+	if err := writePrysmWallet(outputDir, keys, opts.Password, rounds); err != nil {
+		return err
+	}
+
+	pubkeys := make([]string, len(keys))
+	for i, key := range keys {
+		pubkeys[i] = key.PublicKey.String()
+	}
+
+	return writeJSONFile(filepath.Join(outputDir, "pubkeys.json"), pubkeys)
+}
+
+func writeKeystore(outputDir string, key *validators.SigningKey, password string, rounds int) error {
+	if password == "" {
+		var err error
+
+		password, err = newPassword()
+		if err != nil {
+			return err
+		}
+	}
+
+	keystore, err := NewKeystore(key.SecretKey, key.PublicKey[:], key.Path, password, rounds)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt key %s: %w", key.PublicKey.String(), err)
+	}
+
+	pubkey := key.PublicKey.String()
+
+	for _, dir := range []string{"keys", "nimbus-keys"} {
+		if err := os.MkdirAll(filepath.Join(outputDir, dir, pubkey), 0o700); err != nil {
+			return fmt.Errorf("failed to create keystore directory: %w", err)
+		}
+	}
+
+	keystorePaths := []string{
+		filepath.Join("keys", pubkey, "voting-keystore.json"),
+		filepath.Join("nimbus-keys", pubkey, "keystore.json"),
+		filepath.Join("teku-keys", pubkey+".json"),
+	}
+
+	for _, path := range keystorePaths {
+		if err := writeJSONFile(filepath.Join(outputDir, path), keystore); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	secretPaths := []string{
+		filepath.Join("secrets", pubkey),
+		filepath.Join("teku-secrets", pubkey+".txt"),
+	}
+
+	for _, path := range secretPaths {
+		if err := os.WriteFile(filepath.Join(outputDir, path), []byte(password), 0o600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// writePrysmWallet writes a Prysm "direct" (imported) wallet holding all keys.
+func writePrysmWallet(outputDir string, keys []*validators.SigningKey, password string, rounds int) error {
+	if password == "" {
+		var err error
+
+		password, err = newPassword()
+		if err != nil {
+			return err
+		}
+	}
+
+	store := &prysmAccountStore{
+		PrivateKeys: make([][]byte, len(keys)),
+		PublicKeys:  make([][]byte, len(keys)),
+	}
+
+	for i, key := range keys {
+		store.PrivateKeys[i] = key.SecretKey
+		store.PublicKeys[i] = key.PublicKey[:]
+	}
+
+	plaintext, err := json.Marshal(store)
+	if err != nil {
+		return fmt.Errorf("failed to encode prysm account store: %w", err)
+	}
+
+	keystoreCrypto, err := EncryptSecret(plaintext, password, rounds)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt prysm wallet: %w", err)
+	}
+
+	uuid, err := newUUID()
+	if err != nil {
+		return err
+	}
+
+	wallet := &prysmKeystore{
+		Crypto:  keystoreCrypto,
+		ID:      uuid,
+		Version: 4,
+		Name:    "keystore",
+	}
+
+	if err := writeJSONFile(filepath.Join(outputDir, "prysm", "direct", "accounts", "all-accounts.keystore.json"), wallet); err != nil {
+		return fmt.Errorf("failed to write prysm wallet: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(outputDir, "prysm", "wallet-password.txt"), []byte(password), 0o600); err != nil {
+		return fmt.Errorf("failed to write prysm wallet password: %w", err)
+	}
+
+	return nil
+}
+
+func writeJSONFile(path string, data any) error {
+	encoded, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, encoded, 0o600)
+}
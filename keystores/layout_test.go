@@ -0,0 +1,149 @@
+package keystores
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+
+	"github.com/ethpandaops/eth-beacon-genesis/validators"
+)
+
+func makeSigningKeys(count int) []*validators.SigningKey {
+	keys := make([]*validators.SigningKey, count)
+
+	for i := range keys {
+		var pubkey phase0.BLSPubKey
+
+		pubkey[0] = byte(i + 1)
+
+		keys[i] = &validators.SigningKey{
+			Source:    "mnemonic-0",
+			KeyIndex:  uint64(i),
+			Path:      "m/12381/3600/0/0/0",
+			PublicKey: pubkey,
+			SecretKey: bytes.Repeat([]byte{byte(i + 1)}, 32),
+		}
+	}
+
+	return keys
+}
+
+func TestWriteKeystores_Layout(t *testing.T) {
+	outputDir := t.TempDir()
+	keys := makeSigningKeys(2)
+
+	if err := WriteKeystores(outputDir, keys, &Options{PBKDF2Rounds: InsecurePBKDF2Rounds}); err != nil {
+		t.Fatalf("failed to write keystores: %v", err)
+	}
+
+	for _, key := range keys {
+		pubkey := key.PublicKey.String()
+
+		password, err := os.ReadFile(filepath.Join(outputDir, "secrets", pubkey))
+		if err != nil {
+			t.Fatalf("missing secret: %v", err)
+		}
+
+		tekuPassword, err := os.ReadFile(filepath.Join(outputDir, "teku-secrets", pubkey+".txt"))
+		if err != nil || !bytes.Equal(password, tekuPassword) {
+			t.Fatalf("expected matching teku secret, got %q (%v)", tekuPassword, err)
+		}
+
+		for _, path := range []string{
+			filepath.Join("keys", pubkey, "voting-keystore.json"),
+			filepath.Join("nimbus-keys", pubkey, "keystore.json"),
+			filepath.Join("teku-keys", pubkey+".json"),
+		} {
+			data, err := os.ReadFile(filepath.Join(outputDir, path))
+			if err != nil {
+				t.Fatalf("missing keystore %s: %v", path, err)
+			}
+
+			keystore := &Keystore{}
+			if err := json.Unmarshal(data, keystore); err != nil {
+				t.Fatalf("invalid keystore %s: %v", path, err)
+			}
+
+			if keystore.Pubkey != strings.TrimPrefix(pubkey, "0x") || keystore.Path != key.Path {
+				t.Fatalf("unexpected keystore %s: %+v", path, keystore)
+			}
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "prysm", "direct", "accounts", "all-accounts.keystore.json")); err != nil {
+		t.Fatalf("missing prysm wallet: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "prysm", "wallet-password.txt")); err != nil {
+		t.Fatalf("missing prysm wallet password: %v", err)
+	}
+
+	var pubkeys []string
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "pubkeys.json"))
+	if err != nil {
+		t.Fatalf("missing pubkeys.json: %v", err)
+	}
+
+	if err := json.Unmarshal(data, &pubkeys); err != nil || len(pubkeys) != 2 || pubkeys[1] != keys[1].PublicKey.String() {
+		t.Fatalf("unexpected pubkeys.json: %s", data)
+	}
+}
+
+func TestWriteKeystores_FixedPassword(t *testing.T) {
+	outputDir := t.TempDir()
+	keys := makeSigningKeys(1)
+
+	if err := WriteKeystores(outputDir, keys, &Options{Password: "devnet", PBKDF2Rounds: InsecurePBKDF2Rounds}); err != nil {
+		t.Fatalf("failed to write keystores: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "keys", keys[0].PublicKey.String(), "voting-keystore.json"))
+	if err != nil {
+		t.Fatalf("missing keystore: %v", err)
+	}
+
+	var raw struct {
+		Crypto struct {
+			KDF struct {
+				Params struct {
+					C    int    `json:"c"`
+					Salt string `json:"salt"`
+				} `json:"params"`
+			} `json:"kdf"`
+			Checksum KeystoreModule `json:"checksum"`
+			Cipher   KeystoreModule `json:"cipher"`
+		} `json:"crypto"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("invalid keystore: %v", err)
+	}
+
+	keystoreCrypto := &KeystoreCrypto{
+		KDF:      &KeystoreModule{Params: map[string]any{"salt": raw.Crypto.KDF.Params.Salt, "c": raw.Crypto.KDF.Params.C}},
+		Checksum: &raw.Crypto.Checksum,
+		Cipher:   &raw.Crypto.Cipher,
+	}
+
+	if got := decryptSecret(t, keystoreCrypto, "devnet"); !bytes.Equal(got, keys[0].SecretKey) {
+		t.Fatalf("decrypted secret 0x%x, want 0x%x", got, keys[0].SecretKey)
+	}
+}
+
+func TestWriteKeystores_NonEmptyOutputDir(t *testing.T) {
+	outputDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outputDir, "existing"), []byte{}, 0o600); err != nil {
+		t.Fatalf("failed to prepare output dir: %v", err)
+	}
+
+	err := WriteKeystores(outputDir, makeSigningKeys(1), &Options{PBKDF2Rounds: InsecurePBKDF2Rounds})
+	if err == nil || !strings.Contains(err.Error(), "not empty") {
+		t.Fatalf("expected not empty error, got %v", err)
+	}
+}
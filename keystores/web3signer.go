@@ -0,0 +1,186 @@
+package keystores
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ethpandaops/eth-beacon-genesis/validators"
+)
+
+// Web3Signer key configuration types.
+const (
+	Web3SignerFileRaw      = "file-raw"
+	Web3SignerFileKeystore = "file-keystore"
+)
+
+// Web3SignerOptions controls how Web3Signer key configurations are written.
+type Web3SignerOptions struct {
+	// Type is the key configuration type, Web3SignerFileRaw (the default) or
+	// Web3SignerFileKeystore.
+	Type string
+
+	// BasePath replaces outputDir in the keystore paths referenced by
+	// file-keystore configs, for when Web3Signer sees the output directory at a
+	// different location (e.g. inside a container).
+	BasePath string
+
+	// Password and PBKDF2Rounds are used for file-keystore configs, see Options.
+	Password     string
+	PBKDF2Rounds int
+}
+
+// web3SignerKeyConfig is a Web3Signer key configuration file.
+type web3SignerKeyConfig struct {
+	Type                 string `yaml:"type"`
+	KeyType              string `yaml:"keyType"`
+	PrivateKey           string `yaml:"privateKey,omitempty"`
+	KeystoreFile         string `yaml:"keystoreFile,omitempty"`
+	KeystorePasswordFile string `yaml:"keystorePasswordFile,omitempty"`
+}
+
+// WriteWeb3SignerConfigs writes one Web3Signer key configuration per key to
+// outputDir, grouped by the source of the key:
+//
+//	<source>/<pubkey>.yaml                   key configuration
+//	<source>/keystores/<pubkey>.json         keystore (file-keystore only)
+//	<source>/keystores/<pubkey>.txt          keystore password (file-keystore only)
+//
+// Each source directory can be passed to Web3Signer as --key-store-path.
+// outputDir must not exist yet or be empty.
+func WriteWeb3SignerConfigs(outputDir string, keys []*validators.SigningKey, opts *Web3SignerOptions) error {
+	if entries, err := os.ReadDir(outputDir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("output directory %s is not empty", outputDir)
+	}
+
+	configType := opts.Type
+	if configType == "" {
+		configType = Web3SignerFileRaw
+	}
+
+	if configType != Web3SignerFileRaw && configType != Web3SignerFileKeystore {
+		return fmt.Errorf("unsupported web3signer key config type %q", configType)
+	}
+
+	rounds := opts.PBKDF2Rounds
+	if rounds == 0 {
+		rounds = StandardPBKDF2Rounds
+	}
+
+	basePath := opts.BasePath
+	if basePath == "" {
+		var err error
+
+		basePath, err = filepath.Abs(outputDir)
+		if err != nil {
+			return fmt.Errorf("failed to resolve output directory: %w", err)
+		}
+	}
+
+	sources := map[string]bool{}
+
+	for _, key := range keys {
+		if sources[key.Source] {
+			continue
+		}
+
+		if key.Source == "" || key.Source == "." || key.Source == ".." || strings.ContainsAny(key.Source, `/\`) {
+			return fmt.Errorf("source name %q can not be used as directory name", key.Source)
+		}
+
+		dir := filepath.Join(outputDir, key.Source)
+		if configType == Web3SignerFileKeystore {
+			dir = filepath.Join(dir, "keystores")
+		}
+
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+
+		sources[key.Source] = true
+	}
+
+	var g errgroup.Group
+
+	g.SetLimit(runtime.NumCPU())
+
+	for _, key := range keys {
+		g.Go(func() error {
+			pubkey := key.PublicKey.String()
+			config := &web3SignerKeyConfig{
+				Type:    configType,
+				KeyType: "BLS",
+			}
+
+			if configType == Web3SignerFileRaw {
+				config.PrivateKey = fmt.Sprintf("0x%x", key.SecretKey)
+			} else {
+				keystoreFile, passwordFile, err := writeWeb3SignerKeystore(outputDir, key, opts.Password, rounds)
+				if err != nil {
+					return err
+				}
+
+				// Web3Signer resolves these paths itself, so they use forward slashes
+				// relative to basePath
+				config.KeystoreFile = path.Join(filepath.ToSlash(basePath), keystoreFile)
+				config.KeystorePasswordFile = path.Join(filepath.ToSlash(basePath), passwordFile)
+			}
+
+			data, err := yaml.Marshal(config)
+			if err != nil {
+				return fmt.Errorf("failed to encode web3signer key config: %w", err)
+			}
+
+			if err := os.WriteFile(filepath.Join(outputDir, key.Source, pubkey+".yaml"), data, 0o600); err != nil {
+				return fmt.Errorf("failed to write web3signer key config: %w", err)
+			}
+
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	logrus.Infof("wrote %d web3signer key configs (%s) for %d sources", len(keys), configType, len(sources))
+
+	return nil
+}
+
+// writeWeb3SignerKeystore writes the keystore and password file of key and
+// returns their paths relative to outputDir.
+func writeWeb3SignerKeystore(outputDir string, key *validators.SigningKey, password string, rounds int) (keystoreFile, passwordFile string, err error) {
+	if password == "" {
+		password, err = newPassword()
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	keystore, err := NewKeystore(key.SecretKey, key.PublicKey[:], key.Path, password, rounds)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt key %s: %w", key.PublicKey.String(), err)
+	}
+
+	pubkey := key.PublicKey.String()
+	keystoreFile = path.Join(key.Source, "keystores", pubkey+".json")
+	passwordFile = path.Join(key.Source, "keystores", pubkey+".txt")
+
+	if err := writeJSONFile(filepath.Join(outputDir, filepath.FromSlash(keystoreFile)), keystore); err != nil {
+		return "", "", fmt.Errorf("failed to write %s: %w", keystoreFile, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(outputDir, filepath.FromSlash(passwordFile)), []byte(password), 0o600); err != nil {
+		return "", "", fmt.Errorf("failed to write %s: %w", passwordFile, err)
+	}
+
+	return keystoreFile, passwordFile, nil
+}
@@ -0,0 +1,107 @@
+package keystores
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func readWeb3SignerConfig(t *testing.T, path string) *web3SignerKeyConfig {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("missing web3signer key config: %v", err)
+	}
+
+	config := &web3SignerKeyConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		t.Fatalf("invalid web3signer key config: %v", err)
+	}
+
+	return config
+}
+
+func TestWriteWeb3SignerConfigs_FileRaw(t *testing.T) {
+	outputDir := t.TempDir()
+	keys := makeSigningKeys(3)
+	keys[2].Source = "operator-b"
+
+	if err := WriteWeb3SignerConfigs(outputDir, keys, &Web3SignerOptions{}); err != nil {
+		t.Fatalf("failed to write web3signer configs: %v", err)
+	}
+
+	for _, key := range keys {
+		config := readWeb3SignerConfig(t, filepath.Join(outputDir, key.Source, key.PublicKey.String()+".yaml"))
+
+		if config.Type != Web3SignerFileRaw || config.KeyType != "BLS" {
+			t.Fatalf("unexpected config type %s/%s", config.Type, config.KeyType)
+		}
+
+		if config.PrivateKey != "0x"+hex.EncodeToString(key.SecretKey) {
+			t.Fatalf("unexpected private key %s", config.PrivateKey)
+		}
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(outputDir, "mnemonic-0"))
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 configs for mnemonic-0, got %d", len(entries))
+	}
+}
+
+func TestWriteWeb3SignerConfigs_FileKeystore(t *testing.T) {
+	outputDir := t.TempDir()
+	keys := makeSigningKeys(1)
+
+	opts := &Web3SignerOptions{
+		Type:         Web3SignerFileKeystore,
+		BasePath:     "/data/web3signer",
+		Password:     "devnet",
+		PBKDF2Rounds: InsecurePBKDF2Rounds,
+	}
+
+	if err := WriteWeb3SignerConfigs(outputDir, keys, opts); err != nil {
+		t.Fatalf("failed to write web3signer configs: %v", err)
+	}
+
+	pubkey := keys[0].PublicKey.String()
+	config := readWeb3SignerConfig(t, filepath.Join(outputDir, "mnemonic-0", pubkey+".yaml"))
+
+	if config.Type != Web3SignerFileKeystore || config.PrivateKey != "" {
+		t.Fatalf("unexpected config %+v", config)
+	}
+
+	if config.KeystoreFile != "/data/web3signer/mnemonic-0/keystores/"+pubkey+".json" ||
+		config.KeystorePasswordFile != "/data/web3signer/mnemonic-0/keystores/"+pubkey+".txt" {
+		t.Fatalf("unexpected keystore paths %s, %s", config.KeystoreFile, config.KeystorePasswordFile)
+	}
+
+	password, err := os.ReadFile(filepath.Join(outputDir, "mnemonic-0", "keystores", pubkey+".txt"))
+	if err != nil || !bytes.Equal(password, []byte("devnet")) {
+		t.Fatalf("unexpected keystore password %q (%v)", password, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "mnemonic-0", "keystores", pubkey+".json")); err != nil {
+		t.Fatalf("missing keystore: %v", err)
+	}
+}
+
+func TestWriteWeb3SignerConfigs_InvalidOptions(t *testing.T) {
+	err := WriteWeb3SignerConfigs(t.TempDir(), makeSigningKeys(1), &Web3SignerOptions{Type: "hashicorp"})
+	if err == nil || !strings.Contains(err.Error(), "unsupported web3signer key config type") {
+		t.Fatalf("expected unsupported type error, got %v", err)
+	}
+
+	keys := makeSigningKeys(1)
+	keys[0].Source = "../escape"
+
+	err = WriteWeb3SignerConfigs(t.TempDir(), keys, &Web3SignerOptions{})
+	if err == nil || !strings.Contains(err.Error(), "can not be used as directory name") {
+		t.Fatalf("expected invalid source error, got %v", err)
+	}
+}
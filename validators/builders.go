@@ -0,0 +1,254 @@
+package validators
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethpandaops/go-eth2-client/spec/bellatrix"
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// BuilderWithdrawalPrefix is the withdrawal credentials prefix of Gloas
+	// builders and the default builder version.
+	BuilderWithdrawalPrefix = 0x03
+
+	// builderFileSource is the default source of builders with explicit pubkeys.
+	builderFileSource = "builders"
+)
+
+// Builder is a Gloas builder for the genesis builder registry.
+type Builder struct {
+	PublicKey        phase0.BLSPubKey
+	ExecutionAddress bellatrix.ExecutionAddress
+	Balance          *uint64
+	Version          uint8
+	DepositEpoch     uint64
+
+	// WithdrawableEpoch defaults to FAR_FUTURE_EPOCH if nil
+	WithdrawableEpoch *uint64
+
+	// Source identifies where the key originated
+	Source         string
+	SourceKeyIndex uint64
+}
+
+// BuilderSrc is a single entry of a builders file. It either defines one
+// builder with an explicit pubkey, or a range of builders derived from a
+// mnemonic (mnemonic, start and count).
+type BuilderSrc struct {
+	Pubkey string `yaml:"pubkey"`
+
+	Mnemonic       string `yaml:"mnemonic"`
+	Start          uint64 `yaml:"start"`
+	Count          uint64 `yaml:"count"`
+	Passphrase     string `yaml:"passphrase"`
+	PassphraseFile string `yaml:"passphrase_file"`
+	SigningPath    string `yaml:"signing_path"`
+
+	Name              string  `yaml:"name"`
+	ExecutionAddress  string  `yaml:"execution_address"`
+	Balance           *uint64 `yaml:"balance"`
+	Version           *uint8  `yaml:"version"`
+	DepositEpoch      uint64  `yaml:"deposit_epoch"`
+	WithdrawableEpoch *uint64 `yaml:"withdrawable_epoch"`
+}
+
+// LoadBuildersFromFile loads the Gloas builders defined in a YAML builders
+// file. Keys of mnemonic ranges are looked up in keyCache first (may be nil).
+// Builders with explicit pubkeys use the source "builders" unless named,
+// mnemonic ranges use "builder-mnemonic-<index>" unless named.
+func LoadBuildersFromFile(path string, keyCache *KeyCache) ([]*Builder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read builders file: %w", err)
+	}
+
+	srcs := []*BuilderSrc{}
+	if err := yaml.Unmarshal(data, &srcs); err != nil {
+		return nil, fmt.Errorf("failed to decode builders file: %w", err)
+	}
+
+	builders := make([]*Builder, 0)
+	pubkeys := make(map[phase0.BLSPubKey]int)
+	nextKeyIndex := make(map[string]uint64)
+
+	for i, src := range srcs {
+		template, err := src.template()
+		if err != nil {
+			return nil, fmt.Errorf("builder %d: %w", i, err)
+		}
+
+		var srcBuilders []*Builder
+
+		switch {
+		case src.Pubkey != "" && src.Mnemonic != "":
+			return nil, fmt.Errorf("builder %d: pubkey and mnemonic are mutually exclusive", i)
+		case src.Pubkey != "":
+			pubkey, err := ParsePubkey(src.Pubkey)
+			if err != nil {
+				return nil, fmt.Errorf("builder %d: invalid pubkey: %w", i, err)
+			}
+
+			builder := *template
+			builder.PublicKey = pubkey
+
+			if builder.Source == "" {
+				builder.Source = builderFileSource
+			}
+
+			builder.SourceKeyIndex = nextKeyIndex[builder.Source]
+			nextKeyIndex[builder.Source]++
+
+			srcBuilders = []*Builder{&builder}
+		case src.Mnemonic != "":
+			srcBuilders, err = src.deriveBuilders(i, template, filepath.Dir(path), keyCache)
+			if err != nil {
+				return nil, fmt.Errorf("builder %d: %w", i, err)
+			}
+		default:
+			return nil, fmt.Errorf("builder %d: either pubkey or mnemonic is required", i)
+		}
+
+		for _, builder := range srcBuilders {
+			if prev, found := pubkeys[builder.PublicKey]; found {
+				return nil, fmt.Errorf("duplicate builder pubkey %s in builder %d and %d", builder.PublicKey.String(), prev, i)
+			}
+
+			pubkeys[builder.PublicKey] = i
+		}
+
+		builders = append(builders, srcBuilders...)
+	}
+
+	if err := keyCache.Save(); err != nil {
+		return nil, err
+	}
+
+	return builders, nil
+}
+
+// template returns a builder with the settings shared by all builders of src.
+func (src *BuilderSrc) template() (*Builder, error) {
+	if src.ExecutionAddress == "" {
+		return nil, fmt.Errorf("execution_address is required")
+	}
+
+	address, err := hex.DecodeString(strings.TrimPrefix(src.ExecutionAddress, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid execution_address: %w", err)
+	}
+
+	if len(address) != 20 {
+		return nil, fmt.Errorf("invalid execution_address (invalid length)")
+	}
+
+	builder := &Builder{
+		ExecutionAddress:  bellatrix.ExecutionAddress(address),
+		Balance:           src.Balance,
+		Version:           BuilderWithdrawalPrefix,
+		DepositEpoch:      src.DepositEpoch,
+		WithdrawableEpoch: src.WithdrawableEpoch,
+		Source:            src.Name,
+	}
+
+	if src.Version != nil {
+		builder.Version = *src.Version
+	}
+
+	return builder, nil
+}
+
+// deriveBuilders derives the builders of a mnemonic range.
+func (src *BuilderSrc) deriveBuilders(index int, template *Builder, baseDir string, keyCache *KeyCache) ([]*Builder, error) {
+	mnemonicSrc := &MnemonicSrc{
+		Mnemonic:       src.Mnemonic,
+		Start:          src.Start,
+		Count:          src.Count,
+		Passphrase:     src.Passphrase,
+		PassphraseFile: src.PassphraseFile,
+		SigningPath:    src.SigningPath,
+	}
+
+	if err := mnemonicSrc.prepare(baseDir); err != nil {
+		return nil, err
+	}
+
+	seed, err := seedFromMnemonic(mnemonicSrc.Mnemonic, mnemonicSrc.Passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("mnemonic is bad")
+	}
+
+	source := template.Source
+	if source == "" {
+		source = fmt.Sprintf("builder-mnemonic-%d", index)
+	}
+
+	if err := verifyKeyCacheRanges(keyCache, seed, mnemonicSrc, false); err != nil {
+		return nil, fmt.Errorf("failed to verify key cache: %w", err)
+	}
+
+	builders := make([]*Builder, 0, src.Count)
+
+	for i := uint64(0); i < src.Count; i++ {
+		idx := src.Start + i
+
+		pubkey, err := keyCache.DerivePubkey(seed, mnemonicSrc.SigningPath, idx)
+		if err != nil {
+			return nil, err
+		}
+
+		builder := *template
+		builder.PublicKey = pubkey
+		builder.Source = source
+		builder.SourceKeyIndex = idx
+
+		builders = append(builders, &builder)
+	}
+
+	return builders, nil
+}
+
+// BuilderFromValidator converts a validator with builder (0x03) withdrawal
+// credentials into a builder, taking the execution address from the
+// credentials. Exited validators become withdrawable at epoch 0.
+func BuilderFromValidator(val *Validator) *Builder {
+	builder := &Builder{
+		PublicKey:      val.PublicKey,
+		Balance:        val.Balance,
+		Version:        val.WithdrawalCredentials[0],
+		Source:         val.Source,
+		SourceKeyIndex: val.SourceKeyIndex,
+	}
+
+	copy(builder.ExecutionAddress[:], val.WithdrawalCredentials[12:32])
+
+	if val.Status == ValidatorStatusExited {
+		withdrawableEpoch := uint64(0)
+		builder.WithdrawableEpoch = &withdrawableEpoch
+	}
+
+	return builder
+}
+
+// SeparateBuilderValidators splits vals into the validators with builder
+// (0x03) withdrawal credentials, converted to builders, and the remaining
+// validators.
+func SeparateBuilderValidators(vals []*Validator) (builders []*Builder, validatorList []*Validator) {
+	builders = make([]*Builder, 0)
+	validatorList = make([]*Validator, 0, len(vals))
+
+	for _, val := range vals {
+		if len(val.WithdrawalCredentials) > 0 && val.WithdrawalCredentials[0] == BuilderWithdrawalPrefix {
+			builders = append(builders, BuilderFromValidator(val))
+		} else {
+			validatorList = append(validatorList, val)
+		}
+	}
+
+	return builders, validatorList
+}
@@ -0,0 +1,139 @@
+package validators
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func createTestBuildersFile(t *testing.T, data string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "builders.yaml")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write builders file: %v", err)
+	}
+
+	return path
+}
+
+func TestLoadBuildersFromFile(t *testing.T) {
+	initTestBLS(t)
+
+	path := createTestBuildersFile(t, `
+- pubkey: "0x`+strings.Repeat("11", 48)+`"
+  execution_address: "0x`+strings.Repeat("aa", 20)+`"
+- pubkey: "0x`+strings.Repeat("22", 48)+`"
+  execution_address: "0x`+strings.Repeat("bb", 20)+`"
+  balance: 100000000000
+  version: 4
+  deposit_epoch: 3
+  withdrawable_epoch: 10
+- mnemonic: "`+testMnemonic+`"
+  name: "ePBS-builders"
+  start: 5
+  count: 2
+  execution_address: "0x`+strings.Repeat("cc", 20)+`"
+`)
+
+	builders, err := LoadBuildersFromFile(path, nil)
+	if err != nil {
+		t.Fatalf("failed to load builders: %v", err)
+	}
+
+	if len(builders) != 4 {
+		t.Fatalf("expected 4 builders, got %d", len(builders))
+	}
+
+	first := builders[0]
+	if first.Source != "builders" || first.SourceKeyIndex != 0 || first.Version != BuilderWithdrawalPrefix ||
+		first.Balance != nil || first.WithdrawableEpoch != nil || first.ExecutionAddress[0] != 0xaa {
+		t.Fatalf("unexpected builder 0: %+v", first)
+	}
+
+	second := builders[1]
+	if second.SourceKeyIndex != 1 || second.Version != 4 || *second.Balance != 100_000_000_000 ||
+		second.DepositEpoch != 3 || *second.WithdrawableEpoch != 10 {
+		t.Fatalf("unexpected builder 1: %+v", second)
+	}
+
+	// mnemonic builders use the validator signing keys of the same indices
+	vals, err := GenerateValidatorsByMnemonic(createTestMnemonicsFile(t, `
+- mnemonic: "`+testMnemonic+`"
+  start: 5
+  count: 2
+`))
+	if err != nil {
+		t.Fatalf("failed to generate validators: %v", err)
+	}
+
+	for i, builder := range builders[2:] {
+		if builder.Source != "ePBS-builders" || builder.SourceKeyIndex != uint64(5+i) || builder.PublicKey != vals[i].PublicKey {
+			t.Fatalf("unexpected mnemonic builder %d: %+v", i, builder)
+		}
+	}
+
+	entries := BuildBuilderMapping(builders)
+	if len(entries) != 2 || entries[1] != (MappingEntry{StateIndexFrom: 2, StateIndexTo: 3, Source: "ePBS-builders", KeyIndexFrom: 5, KeyIndexTo: 6}) {
+		t.Fatalf("unexpected builder mapping: %+v", entries)
+	}
+}
+
+func TestLoadBuildersFromFile_Invalid(t *testing.T) {
+	pubkey := `"0x` + strings.Repeat("11", 48) + `"`
+	address := `"0x` + strings.Repeat("aa", 20) + `"`
+
+	tests := []struct {
+		data    string
+		wantErr string
+	}{
+		{"- pubkey: " + pubkey + "\n", "execution_address is required"},
+		{"- pubkey: " + pubkey + "\n  execution_address: \"0x1234\"\n", "invalid execution_address (invalid length)"},
+		{"- execution_address: " + address + "\n", "either pubkey or mnemonic is required"},
+		{"- pubkey: " + pubkey + "\n  mnemonic: \"" + testMnemonic + "\"\n  execution_address: " + address + "\n", "mutually exclusive"},
+		{"- pubkey: " + pubkey + "\n  execution_address: " + address + "\n- pubkey: " + pubkey + "\n  execution_address: " + address + "\n", "duplicate builder pubkey"},
+		{"- pubkey: \"0x1234\"\n  execution_address: " + address + "\n", "invalid pubkey"},
+	}
+
+	for _, test := range tests {
+		_, err := LoadBuildersFromFile(createTestBuildersFile(t, test.data), nil)
+		if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Fatalf("expected error containing %q, got %v", test.wantErr, err)
+		}
+	}
+}
+
+func TestSeparateBuilderValidators(t *testing.T) {
+	vals := makeValidators("mnemonic-0", 4)
+
+	for i, val := range vals {
+		val.WithdrawalCredentials = make([]byte, 32)
+		val.WithdrawalCredentials[0] = 0x01
+
+		if i%2 == 1 {
+			val.WithdrawalCredentials[0] = BuilderWithdrawalPrefix
+			val.WithdrawalCredentials[31] = byte(i)
+		}
+	}
+
+	vals[3].Status = ValidatorStatusExited
+
+	builders, remaining := SeparateBuilderValidators(vals)
+
+	if len(builders) != 2 || len(remaining) != 2 {
+		t.Fatalf("expected 2 builders and 2 validators, got %d and %d", len(builders), len(remaining))
+	}
+
+	if builders[0].SourceKeyIndex != 1 || builders[0].ExecutionAddress[19] != 1 || builders[0].WithdrawableEpoch != nil {
+		t.Fatalf("unexpected builder 0: %+v", builders[0])
+	}
+
+	if builders[1].WithdrawableEpoch == nil || *builders[1].WithdrawableEpoch != 0 {
+		t.Fatalf("expected exited builder to be withdrawable at epoch 0")
+	}
+
+	if remaining[0].SourceKeyIndex != 0 || remaining[1].SourceKeyIndex != 2 {
+		t.Fatalf("unexpected remaining validators")
+	}
+}
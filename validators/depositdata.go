@@ -0,0 +1,78 @@
+package validators
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DepositDataEntry is a single deposit of a deposit data file, as written by
+// the staking deposit CLI (deposit_data-*.json).
+type DepositDataEntry struct {
+	Pubkey                string `json:"pubkey"`
+	WithdrawalCredentials string `json:"withdrawal_credentials"`
+	Amount                uint64 `json:"amount"`
+	Signature             string `json:"signature"`
+	DepositMessageRoot    string `json:"deposit_message_root"`
+	DepositDataRoot       string `json:"deposit_data_root"`
+	ForkVersion           string `json:"fork_version"`
+	NetworkName           string `json:"network_name"`
+}
+
+// LoadDepositDataFile loads the genesis validators from a deposit data file.
+// Each deposit becomes a validator with the deposit amount as balance and the
+// deposit signature, in file order. The source of the validators is the file
+// name and the key index is the position in the file.
+// Pubkeys repeated in the file are returned as is, so they can be resolved
+// with the duplicate policy along with the other validator sources.
+func LoadDepositDataFile(depositDataPath string) ([]*Validator, error) {
+	data, err := os.ReadFile(depositDataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deposit data file: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse deposit data file: %w", err)
+	}
+
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("deposit data file must be a json array")
+	}
+
+	list := newValidatorList(true)
+	list.defaultSource = filepath.Base(depositDataPath)
+
+	for dec.More() {
+		lineNum := jsonLineAt(data, dec.InputOffset())
+
+		entry := &DepositDataEntry{}
+		if err := dec.Decode(entry); err != nil {
+			return nil, fmt.Errorf("invalid deposit on line %v: %w", lineNum, err)
+		}
+
+		if entry.Signature == "" {
+			return nil, fmt.Errorf("missing deposit signature on line %v", lineNum)
+		}
+
+		err := list.add(&ValidatorEntry{
+			Pubkey:                entry.Pubkey,
+			WithdrawalCredentials: entry.WithdrawalCredentials,
+			Balance:               &entry.Amount,
+			Signature:             entry.Signature,
+		}, lineNum)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("failed to parse deposit data file: %w", err)
+	}
+
+	return list.validators, nil
+}
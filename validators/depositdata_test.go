@@ -0,0 +1,95 @@
+package validators
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const testSignature = "0xa5f3a4b1b2b5b6c5c3cd4e1d0b24f6e7cb5e5ac8c3f6e6bd1e1df4b3e4f4e2a3c1d1e4a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f7a8b9c0d"
+
+func TestLoadDepositDataFile(t *testing.T) {
+	depositDataFile := createTestValidatorsFileWithExt(t, ".json", `[
+  {
+    "pubkey": "`+strings.TrimPrefix(testPubkey0, "0x")+`",
+    "withdrawal_credentials": "`+strings.TrimPrefix(testCreds0, "0x")+`",
+    "amount": 32000000000,
+    "signature": "`+strings.TrimPrefix(testSignature, "0x")+`",
+    "deposit_message_root": "b9d8ddcb8e8b1e6a1e0c8a4d1d3f2c5e4e5c6a7b8f9e0d1c2b3a4f5e6d7c8b9a",
+    "deposit_data_root": "0c1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e",
+    "fork_version": "00000000",
+    "network_name": "mainnet",
+    "deposit_cli_version": "2.7.0"
+  },
+  {
+    "pubkey": "`+strings.TrimPrefix(testPubkey1, "0x")+`",
+    "withdrawal_credentials": "`+strings.TrimPrefix(testCreds1, "0x")+`",
+    "amount": 64000000000,
+    "signature": "`+strings.TrimPrefix(testSignature, "0x")+`"
+  }
+]`)
+
+	validators, err := LoadDepositDataFile(depositDataFile)
+	if err != nil {
+		t.Fatalf("failed to load deposit data file: %v", err)
+	}
+
+	if len(validators) != 2 {
+		t.Fatalf("expected 2 validators, got %d", len(validators))
+	}
+
+	if validators[1].PublicKey.String() != testPubkey1 {
+		t.Fatalf("expected validator 1 to have pubkey %s, got %s", testPubkey1, validators[1].PublicKey.String())
+	}
+
+	if validators[1].Balance == nil || *validators[1].Balance != 64000000000 {
+		t.Fatalf("expected validator 1 to have balance 64000000000, got %v", validators[1].Balance)
+	}
+
+	if validators[0].DepositSignature == nil || fmt.Sprintf("%#x", validators[0].DepositSignature[:]) != testSignature {
+		t.Fatalf("expected validator 0 to have deposit signature %s, got %v", testSignature, validators[0].DepositSignature)
+	}
+
+	if validators[1].Source != "validators.json" || validators[1].SourceKeyIndex != 1 {
+		t.Fatalf("expected validator 1 to be validators.json/1, got %s/%d", validators[1].Source, validators[1].SourceKeyIndex)
+	}
+}
+
+func TestLoadDepositDataFile_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "not an array",
+			data: "{}",
+			want: "must be a json array",
+		},
+		{
+			name: "missing signature",
+			data: "[\n  {\"pubkey\": \"" + testPubkey0 + "\", \"withdrawal_credentials\": \"" + testCreds0 + "\", \"amount\": 32000000000}\n]",
+			want: "missing deposit signature on line 2",
+		},
+		{
+			name: "invalid signature",
+			data: "[\n  {\"pubkey\": \"" + testPubkey0 + "\", \"withdrawal_credentials\": \"" + testCreds0 + "\", \"amount\": 32000000000, \"signature\": \"0xzz\"}\n]",
+			want: "invalid deposit signature on line 2",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			depositDataFile := createTestValidatorsFileWithExt(t, ".json", test.data)
+
+			_, err := LoadDepositDataFile(depositDataFile)
+			if err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+
+			if !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("expected error to contain %q, got %s", test.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+	"gopkg.in/yaml.v3"
+)
+
+// Duplicate pubkey policies.
+const (
+	// DuplicatePolicyError rejects validator sets with repeated pubkeys.
+	DuplicatePolicyError = "error"
+
+	// DuplicatePolicyKeepFirst keeps the first occurrence of a repeated pubkey.
+	DuplicatePolicyKeepFirst = "keep-first"
+
+	// DuplicatePolicyKeepLast keeps the last occurrence of a repeated pubkey.
+	DuplicatePolicyKeepLast = "keep-last"
+)
+
+// ErrDuplicatePubkey is returned when a pubkey is repeated with the error policy.
+var ErrDuplicatePubkey = errors.New("duplicate pubkey")
+
+// DuplicateKey is a single occurrence of a repeated pubkey.
+type DuplicateKey struct {
+	Source                string `yaml:"source"`
+	KeyIndex              uint64 `yaml:"key_index"`
+	WithdrawalCredentials string `yaml:"withdrawal_credentials"`
+}
+
+// DuplicateConflict lists all occurrences of a repeated pubkey, split into
+// the occurrence kept by the policy and the dropped ones. With the error
+// policy the first occurrence is reported as kept.
+type DuplicateConflict struct {
+	Pubkey  string          `yaml:"pubkey"`
+	Kept    *DuplicateKey   `yaml:"kept"`
+	Dropped []*DuplicateKey `yaml:"dropped"`
+}
+
+func newDuplicateKey(val *Validator) *DuplicateKey {
+	return &DuplicateKey{
+		Source:                val.Source,
+		KeyIndex:              val.SourceKeyIndex,
+		WithdrawalCredentials: fmt.Sprintf("0x%x", val.WithdrawalCredentials),
+	}
+}
+
+// ResolveDuplicates removes repeated pubkeys from vals according to policy and
+// returns the remaining validators in their original order along with one
+// conflict per repeated pubkey. The kept occurrence stays at its own position,
+// so keep-last moves the validator behind the sources it was repeated in.
+// With the error policy (or an empty policy) the validators are resolved like
+// keep-first, but an error wrapping ErrDuplicatePubkey is returned if any
+// pubkey is repeated.
+func ResolveDuplicates(vals []*Validator, policy string) ([]*Validator, []*DuplicateConflict, error) {
+	if policy == "" {
+		policy = DuplicatePolicyError
+	}
+
+	if policy != DuplicatePolicyError && policy != DuplicatePolicyKeepFirst && policy != DuplicatePolicyKeepLast {
+		return nil, nil, fmt.Errorf("unsupported duplicate policy %q", policy)
+	}
+
+	occurrences := make(map[phase0.BLSPubKey][]int, len(vals))
+
+	for idx, val := range vals {
+		occurrences[val.PublicKey] = append(occurrences[val.PublicKey], idx)
+	}
+
+	dropped := make(map[int]bool)
+	conflicts := []*DuplicateConflict{}
+
+	// walk the validators again to report conflicts in first occurrence order
+	for idx, val := range vals {
+		occurrence := occurrences[val.PublicKey]
+		if len(occurrence) < 2 || occurrence[0] != idx {
+			continue
+		}
+
+		keep := occurrence[0]
+		if policy == DuplicatePolicyKeepLast {
+			keep = occurrence[len(occurrence)-1]
+		}
+
+		conflict := &DuplicateConflict{
+			Pubkey:  val.PublicKey.String(),
+			Kept:    newDuplicateKey(vals[keep]),
+			Dropped: make([]*DuplicateKey, 0, len(occurrence)-1),
+		}
+
+		for _, dupIdx := range occurrence {
+			if dupIdx == keep {
+				continue
+			}
+
+			dropped[dupIdx] = true
+			conflict.Dropped = append(conflict.Dropped, newDuplicateKey(vals[dupIdx]))
+		}
+
+		conflicts = append(conflicts, conflict)
+	}
+
+	if len(conflicts) == 0 {
+		return vals, nil, nil
+	}
+
+	resolved := make([]*Validator, 0, len(vals)-len(dropped))
+
+	for idx, val := range vals {
+		if !dropped[idx] {
+			resolved = append(resolved, val)
+		}
+	}
+
+	if policy == DuplicatePolicyError {
+		return resolved, conflicts, DuplicatesError(conflicts)
+	}
+
+	return resolved, conflicts, nil
+}
+
+// DuplicatesError returns an error wrapping ErrDuplicatePubkey that describes
+// the first of conflicts, or nil if there are no conflicts.
+func DuplicatesError(conflicts []*DuplicateConflict) error {
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	first := conflicts[0]
+
+	return fmt.Errorf("%w in validator set: %d pubkeys repeated, first %s (%s key %d and %s key %d)",
+		ErrDuplicatePubkey, len(conflicts), first.Pubkey,
+		first.Kept.Source, first.Kept.KeyIndex, first.Dropped[0].Source, first.Dropped[0].KeyIndex)
+}
+
+// WriteDuplicateReport writes the duplicate pubkey conflicts to path in YAML
+// format. An empty list is written if there are no conflicts.
+func WriteDuplicateReport(path string, conflicts []*DuplicateConflict) error {
+	if conflicts == nil {
+		conflicts = []*DuplicateConflict{}
+	}
+
+	data, err := yaml.Marshal(conflicts)
+	if err != nil {
+		return fmt.Errorf("failed to encode duplicate report: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil { //nolint:gosec // no strict permissions needed
+		return fmt.Errorf("failed to write duplicate report: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package validators
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// makeDuplicateSet returns two sources of 4 validators where key 1 and 3 of
+// source-b repeat key 2 and 0 of source-a.
+func makeDuplicateSet() []*Validator {
+	sourceA := makeValidators("source-a", 4)
+	sourceB := makeValidators("source-b", 4)
+
+	for i, val := range sourceB {
+		val.PublicKey[2] = 0xbb
+		val.PublicKey[3] = byte(i)
+	}
+
+	sourceB[1].PublicKey = sourceA[2].PublicKey
+	sourceB[3].PublicKey = sourceA[0].PublicKey
+
+	return append(sourceA, sourceB...)
+}
+
+func TestResolveDuplicates_Policies(t *testing.T) {
+	tests := []struct {
+		policy   string
+		wantKeys []string
+		wantKept []string
+	}{
+		{
+			policy:   DuplicatePolicyKeepFirst,
+			wantKeys: []string{"source-a/0", "source-a/1", "source-a/2", "source-a/3", "source-b/0", "source-b/2"},
+			wantKept: []string{"source-a/0", "source-a/2"},
+		},
+		{
+			policy:   DuplicatePolicyKeepLast,
+			wantKeys: []string{"source-a/1", "source-a/3", "source-b/0", "source-b/1", "source-b/2", "source-b/3"},
+			wantKept: []string{"source-b/3", "source-b/1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.policy, func(t *testing.T) {
+			vals, conflicts, err := ResolveDuplicates(makeDuplicateSet(), test.policy)
+			if err != nil {
+				t.Fatalf("failed to resolve duplicates: %v", err)
+			}
+
+			keys := make([]string, len(vals))
+			for i, val := range vals {
+				keys[i] = val.Source + "/" + string(rune('0'+val.SourceKeyIndex))
+			}
+
+			if strings.Join(keys, ",") != strings.Join(test.wantKeys, ",") {
+				t.Fatalf("expected validators %v, got %v", test.wantKeys, keys)
+			}
+
+			// conflicts are ordered by the first occurrence of the pubkey
+			if len(conflicts) != len(test.wantKept) {
+				t.Fatalf("expected %d conflicts, got %d", len(test.wantKept), len(conflicts))
+			}
+
+			for i, conflict := range conflicts {
+				kept := conflict.Kept.Source + "/" + string(rune('0'+conflict.Kept.KeyIndex))
+				if kept != test.wantKept[i] {
+					t.Fatalf("expected conflict %d to keep %s, got %s", i, test.wantKept[i], kept)
+				}
+
+				if len(conflict.Dropped) != 1 {
+					t.Fatalf("expected 1 dropped key in conflict %d, got %d", i, len(conflict.Dropped))
+				}
+			}
+		})
+	}
+}
+
+func TestResolveDuplicates_Error(t *testing.T) {
+	vals, conflicts, err := ResolveDuplicates(makeDuplicateSet(), DuplicatePolicyError)
+	if !errors.Is(err, ErrDuplicatePubkey) {
+		t.Fatalf("expected duplicate pubkey error, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "2 pubkeys repeated") || !strings.Contains(err.Error(), "source-a key 0 and source-b key 3") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(vals) != 6 || len(conflicts) != 2 {
+		t.Fatalf("expected 6 validators and 2 conflicts, got %d and %d", len(vals), len(conflicts))
+	}
+
+	unique := makeValidators("source-a", 4)
+
+	vals, conflicts, err = ResolveDuplicates(unique, "")
+	if err != nil {
+		t.Fatalf("expected no error for unique validators, got %v", err)
+	}
+
+	if len(vals) != 4 || conflicts != nil {
+		t.Fatalf("expected unique validators to be returned unchanged")
+	}
+
+	if _, _, err := ResolveDuplicates(unique, "keep-none"); err == nil || !strings.Contains(err.Error(), "unsupported duplicate policy") {
+		t.Fatalf("expected unsupported policy error, got %v", err)
+	}
+}
+
+func TestLoadValidatorsFromFileWithPolicy(t *testing.T) {
+	validatorsFile := createTestValidatorsFileWithExt(t, ".yaml", `
+- pubkey: "`+testPubkey0+`"
+  withdrawal_credentials: "`+testCreds0+`"
+- pubkey: "`+testPubkey1+`"
+  withdrawal_credentials: "`+testCreds0+`"
+- pubkey: "`+testPubkey0+`"
+  withdrawal_credentials: "`+testCreds1+`"
+`)
+
+	if _, err := LoadValidatorsFromFile(validatorsFile); err == nil || !strings.Contains(err.Error(), "duplicate pubkey on line 2 and 6") {
+		t.Fatalf("expected duplicate pubkey error with lines, got %v", err)
+	}
+
+	_, conflicts, err := LoadValidatorsFromFileWithPolicy(validatorsFile, DuplicatePolicyError)
+	if !errors.Is(err, ErrDuplicatePubkey) || len(conflicts) != 1 {
+		t.Fatalf("expected duplicate pubkey error with 1 conflict, got %v (%d conflicts)", err, len(conflicts))
+	}
+
+	vals, conflicts, err := LoadValidatorsFromFileWithPolicy(validatorsFile, DuplicatePolicyKeepLast)
+	if err != nil {
+		t.Fatalf("failed to load validators: %v", err)
+	}
+
+	if len(vals) != 2 || vals[1].SourceKeyIndex != 2 || vals[1].WithdrawalCredentials[0] != 0x02 {
+		t.Fatalf("expected the last occurrence to be kept")
+	}
+
+	if len(conflicts) != 1 || conflicts[0].Dropped[0].KeyIndex != 0 || conflicts[0].Dropped[0].Source != fileSource {
+		t.Fatalf("expected conflict with dropped key 0 of %s", fileSource)
+	}
+
+	reportFile := filepath.Join(t.TempDir(), "duplicates.yaml")
+	if err := WriteDuplicateReport(reportFile, conflicts); err != nil {
+		t.Fatalf("failed to write duplicate report: %v", err)
+	}
+
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatalf("failed to read duplicate report: %v", err)
+	}
+
+	report := []*DuplicateConflict{}
+	if err := yaml.Unmarshal(data, &report); err != nil {
+		t.Fatalf("failed to decode duplicate report: %v", err)
+	}
+
+	if len(report) != 1 || report[0].Pubkey != testPubkey0 || report[0].Kept.KeyIndex != 2 || report[0].Kept.WithdrawalCredentials != strings.ToLower(testCreds1) {
+		t.Fatalf("unexpected duplicate report: %s", data)
+	}
+}
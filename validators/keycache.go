@@ -0,0 +1,312 @@
+package validators
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+	"github.com/sirupsen/logrus"
+
+	e2util "github.com/wealdtech/go-eth2-util"
+)
+
+const (
+	// keyCacheVersion is the format version of the cache files and records.
+	keyCacheVersion = 0x02
+
+	// keyCacheMagic prefixes every key cache file and carries the format version.
+	keyCacheMagic = "ebgkeys\x02"
+
+	// keyCacheRecordSize is the size of a single cache record: the 32 byte
+	// lookup key followed by the 48 byte BLS public key.
+	keyCacheRecordSize = 32 + 48
+
+	// keyCacheHeaderSize covers the magic, the seed hash and the record count.
+	keyCacheHeaderSize = len(keyCacheMagic) + 32 + 8
+)
+
+// KeyCache is an opt-in on-disk cache for BLS public keys derived from
+// mnemonics. Deriving keys via EIP-2333 dominates the runtime for large
+// validator sets, while the derived keys never change for a given mnemonic and
+// derivation path, so they can be reused between runs.
+//
+// The cache keeps one file per mnemonic seed. Records are keyed by a hash of
+// the format version, the seed, the derivation path template and the key
+// index, so signing keys and the withdrawal keys used for 0x00 credentials are
+// cached alike. Files carry a checksum and are ignored (and rebuilt) when they
+// fail validation. As the checksum does not catch wrong entries, VerifyRange
+// re-derives the first and last key of every range.
+//
+// A nil *KeyCache is valid and derives every key without caching.
+type KeyCache struct {
+	dir   string
+	mtx   sync.Mutex
+	seeds map[[32]byte]*keyCacheSeed
+}
+
+type keyCacheSeed struct {
+	keys    map[[32]byte]phase0.BLSPubKey
+	dirty   bool
+	hits    uint64
+	derived uint64
+}
+
+// NewKeyCache returns a key cache that stores its files in dir, creating the
+// directory if needed.
+func NewKeyCache(dir string) (*KeyCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create key cache directory: %w", err)
+	}
+
+	return &KeyCache{
+		dir:   dir,
+		seeds: map[[32]byte]*keyCacheSeed{},
+	}, nil
+}
+
+// DerivePubkey returns the BLS public key for seed at the path of template
+// and index, loading it from the cache when available and deriving (and
+// recording) it otherwise.
+func (c *KeyCache) DerivePubkey(seed []byte, template string, index uint64) (phase0.BLSPubKey, error) {
+	path := keyPath(template, index)
+
+	if c == nil {
+		return derivePubkey(seed, path)
+	}
+
+	seedHash := keyCacheSeedHash(seed)
+	recordKey := keyCacheRecordKey(seedHash, template, index)
+
+	c.mtx.Lock()
+	entry := c.getSeed(seedHash)
+	pubkey, found := entry.keys[recordKey]
+
+	if found {
+		entry.hits++
+	}
+	c.mtx.Unlock()
+
+	if found {
+		return pubkey, nil
+	}
+
+	pubkey, err := derivePubkey(seed, path)
+	if err != nil {
+		return phase0.BLSPubKey{}, err
+	}
+
+	c.mtx.Lock()
+	entry.keys[recordKey] = pubkey
+	entry.derived++
+	entry.dirty = true
+	c.mtx.Unlock()
+
+	return pubkey, nil
+}
+
+// VerifyRange re-derives the cached keys of the first and last index of a
+// range and drops all cached keys of seed if one of them differs, so stale or
+// wrong entries are never used.
+func (c *KeyCache) VerifyRange(seed []byte, template string, first, last uint64) error {
+	if c == nil {
+		return nil
+	}
+
+	seedHash := keyCacheSeedHash(seed)
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	entry := c.getSeed(seedHash)
+
+	for _, index := range []uint64{first, last} {
+		cached, found := entry.keys[keyCacheRecordKey(seedHash, template, index)]
+		if !found {
+			continue
+		}
+
+		pubkey, err := derivePubkey(seed, keyPath(template, index))
+		if err != nil {
+			return err
+		}
+
+		if pubkey != cached {
+			logrus.Warnf("key cache %x: cached key %s differs from derived key, dropping cached keys", seedHash[:4], keyPath(template, index))
+
+			entry.keys = map[[32]byte]phase0.BLSPubKey{}
+			entry.dirty = true
+
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// Save writes every cache file that received new keys back to disk.
+func (c *KeyCache) Save() error {
+	if c == nil {
+		return nil
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for seedHash, entry := range c.seeds {
+		logrus.Infof("key cache %x: %d keys reused, %d keys derived", seedHash[:4], entry.hits, entry.derived)
+
+		if !entry.dirty {
+			continue
+		}
+
+		if err := writeKeyCacheFile(c.filePath(seedHash), seedHash, entry.keys); err != nil {
+			return err
+		}
+
+		entry.dirty = false
+	}
+
+	return nil
+}
+
+// getSeed returns the in-memory cache for a seed, loading it from disk on
+// first use. Must be called with c.mtx held.
+func (c *KeyCache) getSeed(seedHash [32]byte) *keyCacheSeed {
+	if entry, ok := c.seeds[seedHash]; ok {
+		return entry
+	}
+
+	entry := &keyCacheSeed{}
+
+	keys, err := readKeyCacheFile(c.filePath(seedHash), seedHash)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logrus.Warnf("ignoring invalid key cache file: %v", err)
+		}
+
+		keys = map[[32]byte]phase0.BLSPubKey{}
+	}
+
+	entry.keys = keys
+	c.seeds[seedHash] = entry
+
+	return entry
+}
+
+func (c *KeyCache) filePath(seedHash [32]byte) string {
+	return filepath.Join(c.dir, hex.EncodeToString(seedHash[:16])+".keycache")
+}
+
+func derivePubkey(seed []byte, path string) (phase0.BLSPubKey, error) {
+	sk, err := e2util.PrivateKeyFromSeedAndPath(seed, path)
+	if err != nil {
+		return phase0.BLSPubKey{}, err
+	}
+
+	return phase0.BLSPubKey(sk.PublicKey().Marshal()), nil
+}
+
+// keyCacheSeedHash identifies a seed without storing anything that allows
+// recovering it.
+func keyCacheSeedHash(seed []byte) [32]byte {
+	h := sha256.New()
+	h.Write([]byte("eth-beacon-genesis/keycache"))
+	h.Write(seed)
+
+	var res [32]byte
+
+	copy(res[:], h.Sum(nil))
+
+	return res
+}
+
+func keyCacheRecordKey(seedHash [32]byte, template string, index uint64) [32]byte {
+	h := sha256.New()
+	h.Write([]byte{keyCacheVersion})
+	h.Write(seedHash[:])
+	h.Write(binary.LittleEndian.AppendUint64(nil, uint64(len(template))))
+	h.Write([]byte(template))
+	h.Write(binary.LittleEndian.AppendUint64(nil, index))
+
+	var res [32]byte
+
+	copy(res[:], h.Sum(nil))
+
+	return res
+}
+
+// readKeyCacheFile loads and validates a cache file. The layout is:
+//
+//	magic | seed hash (32) | record count (8, LE) | records | sha256 of all preceding bytes
+func readKeyCacheFile(path string, seedHash [32]byte) (map[[32]byte]phase0.BLSPubKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < keyCacheHeaderSize+32 || string(data[:len(keyCacheMagic)]) != keyCacheMagic {
+		return nil, fmt.Errorf("%s: unknown file format", path)
+	}
+
+	body, checksum := data[:len(data)-32], data[len(data)-32:]
+	if sum := sha256.Sum256(body); !bytes.Equal(sum[:], checksum) {
+		return nil, fmt.Errorf("%s: checksum mismatch", path)
+	}
+
+	if !bytes.Equal(body[len(keyCacheMagic):len(keyCacheMagic)+32], seedHash[:]) {
+		return nil, fmt.Errorf("%s: seed hash mismatch", path)
+	}
+
+	count := binary.LittleEndian.Uint64(body[keyCacheHeaderSize-8 : keyCacheHeaderSize])
+	records := body[keyCacheHeaderSize:]
+
+	if uint64(len(records)) != count*keyCacheRecordSize {
+		return nil, fmt.Errorf("%s: expected %d records, got %d bytes", path, count, len(records))
+	}
+
+	keys := make(map[[32]byte]phase0.BLSPubKey, count)
+
+	for offset := 0; offset < len(records); offset += keyCacheRecordSize {
+		var recordKey [32]byte
+
+		copy(recordKey[:], records[offset:offset+32])
+		keys[recordKey] = phase0.BLSPubKey(records[offset+32 : offset+keyCacheRecordSize])
+	}
+
+	return keys, nil
+}
+
+func writeKeyCacheFile(path string, seedHash [32]byte, keys map[[32]byte]phase0.BLSPubKey) error {
+	buf := make([]byte, 0, keyCacheHeaderSize+len(keys)*keyCacheRecordSize+32)
+	buf = append(buf, keyCacheMagic...)
+	buf = append(buf, seedHash[:]...)
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(len(keys)))
+
+	for recordKey, pubkey := range keys {
+		buf = append(buf, recordKey[:]...)
+		buf = append(buf, pubkey[:]...)
+	}
+
+	checksum := sha256.Sum256(buf)
+	buf = append(buf, checksum[:]...)
+
+	// write to a temporary file first, so an interrupted run never leaves a
+	// truncated cache behind
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, buf, 0o600); err != nil {
+		return fmt.Errorf("failed to write key cache file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to write key cache file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,244 @@
+package validators
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	hbls "github.com/herumi/bls-eth-go-binary/bls"
+)
+
+const testMnemonic = "rare observe fox place unfold bargain cannon direct title sorry rabbit juice body autumn quality decrease mixture transfer crisp unveil path depend brick scissors"
+
+func initTestBLS(t *testing.T) {
+	t.Helper()
+
+	if err := hbls.Init(hbls.BLS12_381); err != nil {
+		t.Fatalf("failed to initialize BLS12-381: %v", err)
+	}
+
+	if err := hbls.SetETHmode(hbls.EthModeLatest); err != nil {
+		t.Fatalf("failed to set ETH mode: %v", err)
+	}
+}
+
+func TestKeyCache_ReusesDerivedKeys(t *testing.T) {
+	initTestBLS(t)
+
+	cacheDir := t.TempDir()
+
+	smallFile := createTestMnemonicsFile(t, `
+- mnemonic: "`+testMnemonic+`"
+  start: 0
+  count: 10
+  wd_prefix: "0x00"
+`)
+	largeFile := createTestMnemonicsFile(t, `
+- mnemonic: "`+testMnemonic+`"
+  start: 0
+  count: 15
+  wd_prefix: "0x00"
+`)
+
+	uncached, err := GenerateValidatorsByMnemonic(largeFile)
+	if err != nil {
+		t.Fatalf("failed to generate validators: %v", err)
+	}
+
+	cache, err := NewKeyCache(cacheDir)
+	if err != nil {
+		t.Fatalf("failed to create key cache: %v", err)
+	}
+
+	if _, err = GenerateValidatorsByMnemonicWithCache(smallFile, cache); err != nil {
+		t.Fatalf("failed to generate validators: %v", err)
+	}
+
+	// a fresh cache instance has to pick up the 10 keys stored on disk and only
+	// derive the 5 new ones
+	cache, err = NewKeyCache(cacheDir)
+	if err != nil {
+		t.Fatalf("failed to create key cache: %v", err)
+	}
+
+	cached, err := GenerateValidatorsByMnemonicWithCache(largeFile, cache)
+	if err != nil {
+		t.Fatalf("failed to generate validators: %v", err)
+	}
+
+	for _, entry := range cache.seeds {
+		// signing and withdrawal keys are both cached
+		if entry.hits != 20 || entry.derived != 10 {
+			t.Fatalf("expected 20 cache hits and 10 derived keys, got %d and %d", entry.hits, entry.derived)
+		}
+	}
+
+	if len(cached) != len(uncached) {
+		t.Fatalf("expected %d validators, got %d", len(uncached), len(cached))
+	}
+
+	for i := range cached {
+		if cached[i].PublicKey != uncached[i].PublicKey {
+			t.Fatalf("validator %d: cached pubkey %s differs from derived pubkey %s", i, cached[i].PublicKey.String(), uncached[i].PublicKey.String())
+		}
+
+		if !bytes.Equal(cached[i].WithdrawalCredentials, uncached[i].WithdrawalCredentials) {
+			t.Fatalf("validator %d: cached withdrawal credentials 0x%x differ from 0x%x", i, cached[i].WithdrawalCredentials, uncached[i].WithdrawalCredentials)
+		}
+	}
+}
+
+func TestKeyCache_IgnoresCorruptFile(t *testing.T) {
+	initTestBLS(t)
+
+	cacheDir := t.TempDir()
+
+	cache, err := NewKeyCache(cacheDir)
+	if err != nil {
+		t.Fatalf("failed to create key cache: %v", err)
+	}
+
+	seed, err := seedFromMnemonic(testMnemonic, "")
+	if err != nil {
+		t.Fatalf("failed to derive seed: %v", err)
+	}
+
+	want, err := cache.DerivePubkey(seed, DefaultSigningPath, 0)
+	if err != nil {
+		t.Fatalf("failed to derive pubkey: %v", err)
+	}
+
+	if err = cache.Save(); err != nil {
+		t.Fatalf("failed to save key cache: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(cacheDir, "*.keycache"))
+	if len(files) != 1 {
+		t.Fatalf("expected 1 cache file, got %d", len(files))
+	}
+
+	// flip a byte of the stored pubkey, the checksum must reject the file
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("failed to read cache file: %v", err)
+	}
+
+	data[keyCacheHeaderSize+40] ^= 0xff
+
+	if err = os.WriteFile(files[0], data, 0o600); err != nil {
+		t.Fatalf("failed to write cache file: %v", err)
+	}
+
+	cache, err = NewKeyCache(cacheDir)
+	if err != nil {
+		t.Fatalf("failed to create key cache: %v", err)
+	}
+
+	got, err := cache.DerivePubkey(seed, DefaultSigningPath, 0)
+	if err != nil {
+		t.Fatalf("failed to derive pubkey: %v", err)
+	}
+
+	if got != want {
+		t.Fatalf("expected pubkey %s, got %s", want.String(), got.String())
+	}
+
+	if _, err = readKeyCacheFile(files[0], keyCacheSeedHash(seed)); err == nil {
+		t.Fatalf("expected corrupt cache file to fail validation")
+	}
+}
+
+func TestKeyCache_DropsStaleKeys(t *testing.T) {
+	initTestBLS(t)
+
+	cacheDir := t.TempDir()
+
+	mnemonicsFile := createTestMnemonicsFile(t, `
+- mnemonic: "`+testMnemonic+`"
+  start: 0
+  count: 4
+  wd_prefix: "0x01"
+  wd_address: "0x1234567890abcdef1234567890abcdef12345678"
+`)
+
+	expected, err := GenerateValidatorsByMnemonic(mnemonicsFile)
+	if err != nil {
+		t.Fatalf("failed to generate validators: %v", err)
+	}
+
+	seed, err := seedFromMnemonic(testMnemonic, "")
+	if err != nil {
+		t.Fatalf("failed to derive seed: %v", err)
+	}
+
+	// a cache with a wrong key under a valid record key and checksum, as
+	// written by a build with a different key derivation
+	cache, err := NewKeyCache(cacheDir)
+	if err != nil {
+		t.Fatalf("failed to create key cache: %v", err)
+	}
+
+	for i := uint64(0); i < 4; i++ {
+		if _, err = cache.DerivePubkey(seed, DefaultSigningPath, i); err != nil {
+			t.Fatalf("failed to derive pubkey: %v", err)
+		}
+	}
+
+	seedHash := keyCacheSeedHash(seed)
+	entry := cache.seeds[seedHash]
+	entry.keys[keyCacheRecordKey(seedHash, DefaultSigningPath, 3)] = expected[0].PublicKey
+
+	if err = cache.Save(); err != nil {
+		t.Fatalf("failed to save key cache: %v", err)
+	}
+
+	cache, err = NewKeyCache(cacheDir)
+	if err != nil {
+		t.Fatalf("failed to create key cache: %v", err)
+	}
+
+	vals, err := GenerateValidatorsByMnemonicWithCache(mnemonicsFile, cache)
+	if err != nil {
+		t.Fatalf("failed to generate validators: %v", err)
+	}
+
+	for i := range vals {
+		if vals[i].PublicKey != expected[i].PublicKey {
+			t.Fatalf("validator %d: got pubkey %s, expected %s", i, vals[i].PublicKey.String(), expected[i].PublicKey.String())
+		}
+	}
+
+	if entry := cache.seeds[seedHash]; entry.hits != 0 || entry.derived != 4 {
+		t.Fatalf("expected all keys to be derived again, got %d hits and %d derived keys", entry.hits, entry.derived)
+	}
+
+	// the path template is part of the record key
+	if keyCacheRecordKey(seedHash, DefaultSigningPath, 0) == keyCacheRecordKey(seedHash, DefaultWithdrawalPath, 0) {
+		t.Fatalf("expected different record keys for different path templates")
+	}
+}
+
+func TestKeyCache_NilDerivesDirectly(t *testing.T) {
+	initTestBLS(t)
+
+	var cache *KeyCache
+
+	seed, err := seedFromMnemonic(testMnemonic, "")
+	if err != nil {
+		t.Fatalf("failed to derive seed: %v", err)
+	}
+
+	pubkey, err := cache.DerivePubkey(seed, DefaultSigningPath, 0)
+	if err != nil {
+		t.Fatalf("failed to derive pubkey: %v", err)
+	}
+
+	if pubkey.String() != "0xa72ce460a5ab6bea347e59b17ee349bebf6adfa0a240993ed70a5be0da9638b6e2dc7bbdd19e24a8292c1c7b30f23c9e" {
+		t.Fatalf("unexpected pubkey %s", pubkey.String())
+	}
+
+	if err := cache.Save(); err != nil {
+		t.Fatalf("expected nil cache save to succeed, got %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -16,7 +17,64 @@ import (
 // additional-validators file.
 const fileSource = "additional-validators"
 
+// ValidatorEntry is a single validator definition as read from an
+// additional-validators file. The plain text format only fills Pubkey,
+// WithdrawalCredentials and Balance; the structured formats (YAML, JSON, CSV)
+// can set every field.
+type ValidatorEntry struct {
+	Pubkey                string          `yaml:"pubkey" json:"pubkey"`
+	WithdrawalCredentials string          `yaml:"withdrawal_credentials" json:"withdrawal_credentials"`
+	Balance               *uint64         `yaml:"balance" json:"balance"`
+	Status                ValidatorStatus `yaml:"status" json:"status"`
+
+	// Source is the name used in the validator mapping (defaults to
+	// "additional-validators"). KeyIndex is the key index within that source
+	// and defaults to the number of entries seen for the source so far.
+	Source   string  `yaml:"source" json:"source"`
+	KeyIndex *uint64 `yaml:"key_index" json:"key_index"`
+
+	// Signature is the optional deposit signature of the validator, used to
+	// build the genesis deposit tree.
+	Signature string `yaml:"signature" json:"signature"`
+}
+
+// LoadValidatorsFromFile loads the additional genesis validators from path.
+// The format is selected by the file extension: .yaml/.yml, .json and .csv
+// files are parsed as structured lists of ValidatorEntry, anything else as
+// plain text with one <pubkey>:<withdrawal credentials>[:<balance>] per line.
+// Pubkeys repeated within the file are rejected.
 func LoadValidatorsFromFile(validatorsConfigPath string) ([]*Validator, error) {
+	return loadValidatorsFile(validatorsConfigPath, false)
+}
+
+// LoadValidatorsFromFileWithPolicy loads the additional genesis validators
+// like LoadValidatorsFromFile, but resolves pubkeys repeated within the file
+// with the given duplicate policy (see ResolveDuplicates). The conflicts are
+// returned with the error policy as well, along with an error wrapping
+// ErrDuplicatePubkey.
+func LoadValidatorsFromFileWithPolicy(validatorsConfigPath, policy string) ([]*Validator, []*DuplicateConflict, error) {
+	vals, err := loadValidatorsFile(validatorsConfigPath, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ResolveDuplicates(vals, policy)
+}
+
+func loadValidatorsFile(validatorsConfigPath string, allowDuplicates bool) ([]*Validator, error) {
+	switch strings.ToLower(filepath.Ext(validatorsConfigPath)) {
+	case ".yaml", ".yml":
+		return loadValidatorsFromYAML(validatorsConfigPath, allowDuplicates)
+	case ".json":
+		return loadValidatorsFromJSON(validatorsConfigPath, allowDuplicates)
+	case ".csv":
+		return loadValidatorsFromCSV(validatorsConfigPath, allowDuplicates)
+	default:
+		return loadValidatorsFromText(validatorsConfigPath, allowDuplicates)
+	}
+}
+
+func loadValidatorsFromText(validatorsConfigPath string, allowDuplicates bool) ([]*Validator, error) {
 	validatorsFile, err := os.Open(validatorsConfigPath)
 	if err != nil {
 		return nil, err
@@ -24,9 +82,7 @@ func LoadValidatorsFromFile(validatorsConfigPath string) ([]*Validator, error) {
 
 	defer validatorsFile.Close()
 
-	validators := make([]*Validator, 0)
-	pubkeyMap := map[string]int{}
-
+	list := newValidatorList(allowDuplicates)
 	scanner := bufio.NewScanner(validatorsFile)
 	lineNum := 0
 
@@ -39,63 +95,155 @@ func LoadValidatorsFromFile(validatorsConfigPath string) ([]*Validator, error) {
 		}
 
 		lineParts := strings.Split(line, ":")
-
-		// Public key
-		pubKey, err := hex.DecodeString(strings.ReplaceAll(lineParts[0], "0x", ""))
-		if err != nil {
-			return nil, err
+		if len(lineParts) < 2 {
+			return nil, fmt.Errorf("missing withdrawal credentials on line %v", lineNum)
 		}
 
-		if len(pubKey) != 48 {
-			return nil, fmt.Errorf("invalid pubkey (invalid length) on line %v", lineNum)
+		entry := &ValidatorEntry{
+			Pubkey:                lineParts[0],
+			WithdrawalCredentials: lineParts[1],
 		}
 
-		if pubkeyMap[string(pubKey)] != 0 {
-			return nil, fmt.Errorf("duplicate pubkey on line %v and %v", pubkeyMap[string(pubKey)], lineNum)
-		}
+		// Validator balance
+		if len(lineParts) > 2 {
+			balance, err := strconv.ParseUint(lineParts[2], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid balance on line %v: %w", lineNum, err)
+			}
 
-		pubkeyMap[string(pubKey)] = lineNum
-		validatorEntry := &Validator{
-			PublicKey:             phase0.BLSPubKey(pubKey),
-			WithdrawalCredentials: make([]byte, 32),
-			Source:                fileSource,
-			SourceKeyIndex:        uint64(len(validators)),
+			entry.Balance = &balance
 		}
 
-		// Withdrawal credentials
-		withdrawalCred, err := hex.DecodeString(strings.ReplaceAll(lineParts[1], "0x", ""))
-		if err != nil {
+		if err := list.add(entry, lineNum); err != nil {
 			return nil, err
 		}
+	}
 
-		if len(withdrawalCred) != 32 {
-			return nil, fmt.Errorf("invalid withdrawal credentials (invalid length) on line %v", lineNum)
-		}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
 
-		switch withdrawalCred[0] {
-		case 0x00:
-		case 0x01, 0x02, 0x03:
-			if !bytes.Equal(withdrawalCred[1:12], []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}) {
-				return nil, fmt.Errorf("invalid withdrawal credentials (invalid 0x01/0x02/0x03 cred) on line %v", lineNum)
-			}
-		default:
-			return nil, fmt.Errorf("invalid withdrawal credentials (invalid type) on line %v", lineNum)
+	return list.validators, nil
+}
+
+// validatorList collects the entries of an additional-validators file. It
+// validates each entry, rejects pubkeys repeated within the file (unless
+// allowDuplicates is set) and assigns default key indices per source. Key
+// indices of a source can only be used by one pubkey.
+type validatorList struct {
+	validators      []*Validator
+	pubkeyLines     map[phase0.BLSPubKey]int
+	nextKeyIndex    map[string]uint64
+	usedKeys        map[sourceKey]*usedKey
+	allowDuplicates bool
+	defaultSource   string
+}
+
+// sourceKey identifies a key by source and key index.
+type sourceKey struct {
+	source   string
+	keyIndex uint64
+}
+
+// usedKey is the pubkey and line of the entry that uses a sourceKey.
+type usedKey struct {
+	pubkey  phase0.BLSPubKey
+	lineNum int
+}
+
+func newValidatorList(allowDuplicates bool) *validatorList {
+	return &validatorList{
+		validators:      make([]*Validator, 0),
+		pubkeyLines:     map[phase0.BLSPubKey]int{},
+		nextKeyIndex:    map[string]uint64{},
+		usedKeys:        map[sourceKey]*usedKey{},
+		allowDuplicates: allowDuplicates,
+		defaultSource:   fileSource,
+	}
+}
+
+func (l *validatorList) add(entry *ValidatorEntry, lineNum int) error {
+	// Public key
+	pubKey, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(entry.Pubkey), "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid pubkey on line %v: %w", lineNum, err)
+	}
+
+	if len(pubKey) != 48 {
+		return fmt.Errorf("invalid pubkey (invalid length) on line %v", lineNum)
+	}
+
+	blsPubKey := phase0.BLSPubKey(pubKey)
+
+	if prevLine, found := l.pubkeyLines[blsPubKey]; found && !l.allowDuplicates {
+		return fmt.Errorf("duplicate pubkey on line %v and %v", prevLine, lineNum)
+	}
+
+	// Withdrawal credentials
+	withdrawalCred, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(entry.WithdrawalCredentials), "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid withdrawal credentials on line %v: %w", lineNum, err)
+	}
+
+	if len(withdrawalCred) != 32 {
+		return fmt.Errorf("invalid withdrawal credentials (invalid length) on line %v", lineNum)
+	}
+
+	switch withdrawalCred[0] {
+	case 0x00:
+	case 0x01, 0x02, 0x03:
+		if !bytes.Equal(withdrawalCred[1:12], []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}) {
+			return fmt.Errorf("invalid withdrawal credentials (invalid 0x01/0x02/0x03 cred) on line %v", lineNum)
 		}
+	default:
+		return fmt.Errorf("invalid withdrawal credentials (invalid type) on line %v", lineNum)
+	}
 
-		copy(validatorEntry.WithdrawalCredentials, withdrawalCred)
+	// Deposit signature
+	var depositSignature *phase0.BLSSignature
 
-		// Validator balance
-		if len(lineParts) > 2 {
-			balance, err := strconv.ParseUint(lineParts[2], 10, 64)
-			if err != nil {
-				return nil, err
-			}
+	if entry.Signature != "" {
+		signature, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(entry.Signature), "0x"))
+		if err != nil {
+			return fmt.Errorf("invalid deposit signature on line %v: %w", lineNum, err)
+		}
 
-			validatorEntry.Balance = &balance
+		if len(signature) != 96 {
+			return fmt.Errorf("invalid deposit signature (invalid length) on line %v", lineNum)
 		}
 
-		validators = append(validators, validatorEntry)
+		depositSignature = (*phase0.BLSSignature)(signature)
+	}
+
+	source := entry.Source
+	if source == "" {
+		source = l.defaultSource
+	}
+
+	keyIndex := l.nextKeyIndex[source]
+	if entry.KeyIndex != nil {
+		keyIndex = *entry.KeyIndex
+	}
+
+	// repeated pubkeys may share a key, they are resolved as duplicates
+	key := sourceKey{source, keyIndex}
+	if used, found := l.usedKeys[key]; found && used.pubkey != blsPubKey {
+		return fmt.Errorf("key index %d of source %s on line %v is already used on line %v", keyIndex, source, lineNum, used.lineNum)
 	}
 
-	return validators, nil
+	l.usedKeys[key] = &usedKey{pubkey: blsPubKey, lineNum: lineNum}
+	l.nextKeyIndex[source] = keyIndex + 1
+	l.pubkeyLines[blsPubKey] = lineNum
+
+	l.validators = append(l.validators, &Validator{
+		PublicKey:             blsPubKey,
+		WithdrawalCredentials: withdrawalCred,
+		Balance:               entry.Balance,
+		Status:                entry.Status,
+		Source:                source,
+		SourceKeyIndex:        keyIndex,
+		DepositSignature:      depositSignature,
+	})
+
+	return nil
 }
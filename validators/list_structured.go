@@ -0,0 +1,241 @@
+package validators
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadValidatorsFromYAML parses a YAML list of ValidatorEntry. Each list item
+// is decoded separately so errors can reference the line the item starts on.
+func loadValidatorsFromYAML(validatorsConfigPath string, allowDuplicates bool) ([]*Validator, error) {
+	data, err := os.ReadFile(validatorsConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse validators yaml: %w", err)
+	}
+
+	list := newValidatorList(allowDuplicates)
+
+	if len(root.Content) == 0 {
+		return list.validators, nil
+	}
+
+	seq := root.Content[0]
+	if seq.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("validators yaml must be a list (line %v)", seq.Line)
+	}
+
+	for _, item := range seq.Content {
+		entry := &ValidatorEntry{}
+		if err := item.Decode(entry); err != nil {
+			return nil, fmt.Errorf("invalid validator entry on line %v: %w", item.Line, err)
+		}
+
+		if err := list.add(entry, item.Line); err != nil {
+			return nil, err
+		}
+	}
+
+	return list.validators, nil
+}
+
+// loadValidatorsFromJSON parses a JSON array of ValidatorEntry. The array is
+// streamed entry by entry to track the line each entry starts on.
+func loadValidatorsFromJSON(validatorsConfigPath string, allowDuplicates bool) ([]*Validator, error) {
+	data, err := os.ReadFile(validatorsConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse validators json: %w", err)
+	}
+
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("validators json must be an array")
+	}
+
+	list := newValidatorList(allowDuplicates)
+
+	for dec.More() {
+		lineNum := jsonLineAt(data, dec.InputOffset())
+
+		entry := &ValidatorEntry{}
+		if err := dec.Decode(entry); err != nil {
+			return nil, fmt.Errorf("invalid validator entry on line %v: %w", lineNum, err)
+		}
+
+		if err := list.add(entry, lineNum); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("failed to parse validators json: %w", err)
+	}
+
+	return list.validators, nil
+}
+
+// jsonLineAt returns the 1-based line of the first value at or after offset,
+// skipping the whitespace and separators the decoder has not consumed yet.
+func jsonLineAt(data []byte, offset int64) int {
+	pos := int(offset)
+	for pos < len(data) && strings.ContainsRune(" \t\r\n,", rune(data[pos])) {
+		pos++
+	}
+
+	return bytes.Count(data[:pos], []byte("\n")) + 1
+}
+
+// csvColumns lists the header names recognised in CSV validator files.
+var csvColumns = []string{"pubkey", "withdrawal_credentials", "balance", "status", "source", "key_index", "signature"}
+
+// loadValidatorsFromCSV parses a CSV validator list. The first non-comment
+// row is a header naming the columns (see csvColumns); pubkey and
+// withdrawal_credentials are required, all other columns are optional and
+// empty cells fall back to their defaults.
+func loadValidatorsFromCSV(validatorsConfigPath string, allowDuplicates bool) ([]*Validator, error) {
+	validatorsFile, err := os.Open(validatorsConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer validatorsFile.Close()
+
+	reader := csv.NewReader(validatorsFile)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var columns map[string]int
+
+	list := newValidatorList(allowDuplicates)
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse validators csv: %w", err)
+		}
+
+		lineNum, _ := reader.FieldPos(0)
+
+		if columns == nil {
+			columns, err = parseCSVHeader(record)
+			if err != nil {
+				return nil, fmt.Errorf("invalid header on line %v: %w", lineNum, err)
+			}
+
+			continue
+		}
+
+		entry, err := parseCSVRecord(record, columns)
+		if err != nil {
+			return nil, fmt.Errorf("invalid validator entry on line %v: %w", lineNum, err)
+		}
+
+		if err := list.add(entry, lineNum); err != nil {
+			return nil, err
+		}
+	}
+
+	return list.validators, nil
+}
+
+func parseCSVHeader(record []string) (map[string]int, error) {
+	columns := make(map[string]int, len(record))
+
+	for i, name := range record {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		known := false
+
+		for _, col := range csvColumns {
+			if col == name {
+				known = true
+				break
+			}
+		}
+
+		if !known {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+
+		columns[name] = i
+	}
+
+	if _, ok := columns["pubkey"]; !ok {
+		return nil, fmt.Errorf("missing pubkey column")
+	}
+
+	if _, ok := columns["withdrawal_credentials"]; !ok {
+		return nil, fmt.Errorf("missing withdrawal_credentials column")
+	}
+
+	return columns, nil
+}
+
+func parseCSVRecord(record []string, columns map[string]int) (*ValidatorEntry, error) {
+	field := func(name string) string {
+		idx, ok := columns[name]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[idx])
+	}
+
+	entry := &ValidatorEntry{
+		Pubkey:                field("pubkey"),
+		WithdrawalCredentials: field("withdrawal_credentials"),
+		Source:                field("source"),
+		Signature:             field("signature"),
+	}
+
+	if value := field("balance"); value != "" {
+		balance, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid balance: %w", err)
+		}
+
+		entry.Balance = &balance
+	}
+
+	status, err := ParseValidatorStatus(field("status"))
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Status = status
+
+	if value := field("key_index"); value != "" {
+		keyIndex, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key index: %w", err)
+		}
+
+		entry.KeyIndex = &keyIndex
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,254 @@
+package validators
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testPubkey0 = "0x9824e447621e4b3bca7794b91c664cc0b43322a70b1881b2f804e3a990a3965a64bfe7f098cb4c0396cd0c89218de0b4"
+	testPubkey1 = "0xace5689384f87725790499fb5261b586d7dfb7d86058f0a909856272ba02df9929dcdb4b1ea529b02b948b3a1dca4d57"
+	testPubkey2 = "0xa33dfc09b4031e8c520469024c0ef419cc148f71d7b9501f58f2e54fc644462f208119791e57c5c9b33bf5e47f705060"
+	testCreds0  = "0x001547805ff0547da9e51a7463a6a0c603eeda01dd930f7016185f0642b9ecaf"
+	testCreds1  = "0x020000000000000000000000000000000000000000000000000000000000dEaD"
+)
+
+func createTestValidatorsFileWithExt(t *testing.T, ext, data string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "validators"+ext)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write validators data: %v", err)
+	}
+
+	return path
+}
+
+// checkStructuredValidators asserts the validator set shared by the YAML, JSON
+// and CSV test fixtures.
+func checkStructuredValidators(t *testing.T, validators []*Validator) {
+	t.Helper()
+
+	if len(validators) != 3 {
+		t.Fatalf("expected 3 validators, got %d", len(validators))
+	}
+
+	if validators[0].PublicKey.String() != testPubkey0 {
+		t.Fatalf("expected validator 0 to have pubkey %s, got %s", testPubkey0, validators[0].PublicKey.String())
+	}
+
+	if validators[0].Balance == nil || *validators[0].Balance != 32000000000 {
+		t.Fatalf("expected validator 0 to have balance 32000000000, got %v", validators[0].Balance)
+	}
+
+	if validators[0].Source != "operator-a" || validators[0].SourceKeyIndex != 7 {
+		t.Fatalf("expected validator 0 to be operator-a/7, got %s/%d", validators[0].Source, validators[0].SourceKeyIndex)
+	}
+
+	if validators[1].Status != ValidatorStatusSlashed {
+		t.Fatalf("expected validator 1 to be slashed, got %v", validators[1].Status)
+	}
+
+	if validators[1].Source != "operator-a" || validators[1].SourceKeyIndex != 8 {
+		t.Fatalf("expected validator 1 to be operator-a/8, got %s/%d", validators[1].Source, validators[1].SourceKeyIndex)
+	}
+
+	if validators[1].WithdrawalCredentials[0] != 0x02 {
+		t.Fatalf("expected validator 1 to have 0x02 credentials, got 0x%x", validators[1].WithdrawalCredentials)
+	}
+
+	if validators[2].Balance != nil {
+		t.Fatalf("expected validator 2 to have no balance, got %d", *validators[2].Balance)
+	}
+
+	if validators[2].Status != ValidatorStatusExited {
+		t.Fatalf("expected validator 2 to be exited, got %v", validators[2].Status)
+	}
+
+	if validators[2].Source != fileSource || validators[2].SourceKeyIndex != 0 {
+		t.Fatalf("expected validator 2 to be %s/0, got %s/%d", fileSource, validators[2].Source, validators[2].SourceKeyIndex)
+	}
+}
+
+func TestLoadValidatorsFromFile_YAML(t *testing.T) {
+	validatorsFile := createTestValidatorsFileWithExt(t, ".yaml", `
+- pubkey: "`+testPubkey0+`"
+  withdrawal_credentials: "`+testCreds0+`"
+  balance: 32000000000
+  source: operator-a
+  key_index: 7
+- pubkey: "`+testPubkey1+`"
+  withdrawal_credentials: "`+testCreds1+`"
+  status: slashed
+  source: operator-a
+- pubkey: "`+testPubkey2+`"
+  withdrawal_credentials: "`+testCreds0+`"
+  status: 2
+`)
+
+	validators, err := LoadValidatorsFromFile(validatorsFile)
+	if err != nil {
+		t.Fatalf("failed to load validators: %v", err)
+	}
+
+	checkStructuredValidators(t, validators)
+}
+
+func TestLoadValidatorsFromFile_JSON(t *testing.T) {
+	validatorsFile := createTestValidatorsFileWithExt(t, ".json", `[
+  {"pubkey": "`+testPubkey0+`", "withdrawal_credentials": "`+testCreds0+`", "balance": 32000000000, "source": "operator-a", "key_index": 7},
+  {"pubkey": "`+testPubkey1+`", "withdrawal_credentials": "`+testCreds1+`", "status": "slashed", "source": "operator-a"},
+  {"pubkey": "`+testPubkey2+`", "withdrawal_credentials": "`+testCreds0+`", "status": 2}
+]`)
+
+	validators, err := LoadValidatorsFromFile(validatorsFile)
+	if err != nil {
+		t.Fatalf("failed to load validators: %v", err)
+	}
+
+	checkStructuredValidators(t, validators)
+}
+
+func TestLoadValidatorsFromFile_CSV(t *testing.T) {
+	validatorsFile := createTestValidatorsFileWithExt(t, ".csv", `# operator submitted keys
+pubkey,withdrawal_credentials,balance,status,source,key_index
+`+testPubkey0+`,`+testCreds0+`,32000000000,,operator-a,7
+`+testPubkey1+`,`+testCreds1+`,,slashed,operator-a,
+`+testPubkey2+`,`+testCreds0+`,,exited,,
+`)
+
+	validators, err := LoadValidatorsFromFile(validatorsFile)
+	if err != nil {
+		t.Fatalf("failed to load validators: %v", err)
+	}
+
+	checkStructuredValidators(t, validators)
+}
+
+func TestLoadValidatorsFromFile_StructuredErrorLines(t *testing.T) {
+	tests := []struct {
+		name string
+		ext  string
+		data string
+		want string
+	}{
+		{
+			name: "yaml invalid pubkey length",
+			ext:  ".yaml",
+			data: "- pubkey: \"" + testPubkey0 + "\"\n  withdrawal_credentials: \"" + testCreds0 + "\"\n- pubkey: \"0x1234\"\n  withdrawal_credentials: \"" + testCreds0 + "\"\n",
+			want: "invalid pubkey (invalid length) on line 3",
+		},
+		{
+			name: "yaml invalid status",
+			ext:  ".yaml",
+			data: "- pubkey: \"" + testPubkey0 + "\"\n  withdrawal_credentials: \"" + testCreds0 + "\"\n  status: retired\n",
+			want: "invalid validator entry on line 1",
+		},
+		{
+			name: "yaml invalid signature length",
+			ext:  ".yaml",
+			data: "- pubkey: \"" + testPubkey0 + "\"\n  withdrawal_credentials: \"" + testCreds0 + "\"\n  signature: \"0x1234\"\n",
+			want: "invalid deposit signature (invalid length) on line 1",
+		},
+		{
+			name: "json duplicate pubkey",
+			ext:  ".json",
+			data: "[\n  {\"pubkey\": \"" + testPubkey0 + "\", \"withdrawal_credentials\": \"" + testCreds0 + "\"},\n  {\"pubkey\": \"" + testPubkey0 + "\", \"withdrawal_credentials\": \"" + testCreds1 + "\"}\n]",
+			want: "duplicate pubkey on line 2 and 3",
+		},
+		{
+			name: "yaml explicit key index collision",
+			ext:  ".yaml",
+			data: "- pubkey: \"" + testPubkey0 + "\"\n  withdrawal_credentials: \"" + testCreds0 + "\"\n  key_index: 3\n- pubkey: \"" + testPubkey1 + "\"\n  withdrawal_credentials: \"" + testCreds0 + "\"\n  key_index: 3\n",
+			want: "key index 3 of source additional-validators on line 4 is already used on line 1",
+		},
+		{
+			name: "json default key index collision",
+			ext:  ".json",
+			data: "[\n  {\"pubkey\": \"" + testPubkey0 + "\", \"withdrawal_credentials\": \"" + testCreds0 + "\", \"source\": \"op\", \"key_index\": 1},\n  {\"pubkey\": \"" + testPubkey1 + "\", \"withdrawal_credentials\": \"" + testCreds0 + "\", \"source\": \"op\", \"key_index\": 0},\n  {\"pubkey\": \"" + testPubkey2 + "\", \"withdrawal_credentials\": \"" + testCreds0 + "\", \"source\": \"op\"}\n]",
+			want: "key index 1 of source op on line 4 is already used on line 2",
+		},
+		{
+			name: "json not an array",
+			ext:  ".json",
+			data: "{}",
+			want: "must be an array",
+		},
+		{
+			name: "csv invalid credential type",
+			ext:  ".csv",
+			data: "pubkey,withdrawal_credentials\n" + testPubkey0 + ",0xff1547805ff0547da9e51a7463a6a0c603eeda01dd930f7016185f0642b9ecaf\n",
+			want: "invalid withdrawal credentials (invalid type) on line 2",
+		},
+		{
+			name: "csv unknown column",
+			ext:  ".csv",
+			data: "pubkey,withdrawal_credentials,colour\n",
+			want: "unknown column \"colour\"",
+		},
+		{
+			name: "csv missing column",
+			ext:  ".csv",
+			data: "pubkey,balance\n",
+			want: "missing withdrawal_credentials column",
+		},
+		{
+			name: "csv invalid balance",
+			ext:  ".csv",
+			data: "pubkey,withdrawal_credentials,balance\n" + testPubkey0 + "," + testCreds0 + ",lots\n",
+			want: "invalid validator entry on line 2: invalid balance",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			validatorsFile := createTestValidatorsFileWithExt(t, test.ext, test.data)
+
+			_, err := LoadValidatorsFromFile(validatorsFile)
+			if err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+
+			if !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("expected error to contain %q, got %s", test.want, err)
+			}
+		})
+	}
+}
+
+func TestParseValidatorStatus(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    ValidatorStatus
+		wantErr bool
+	}{
+		{value: "", want: ValidatorStatusActive},
+		{value: "0", want: ValidatorStatusActive},
+		{value: "active", want: ValidatorStatusActive},
+		{value: "Slashed", want: ValidatorStatusSlashed},
+		{value: "2", want: ValidatorStatusExited},
+		{value: "3", wantErr: true},
+		{value: "pending", wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseValidatorStatus(test.value)
+		if test.wantErr {
+			if err == nil {
+				t.Fatalf("ParseValidatorStatus(%q) expected error, got %v", test.value, got)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("ParseValidatorStatus(%q) failed: %v", test.value, err)
+		}
+
+		if got != test.want {
+			t.Fatalf("ParseValidatorStatus(%q) = %v, want %v", test.value, got, test.want)
+		}
+	}
+}
@@ -3,7 +3,11 @@ package validators
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 // MappingEntry describes a contiguous range of validators in the final state
@@ -30,32 +34,50 @@ type MappingEntry struct {
 // produces one entry per source and a block-shuffled set produces one entry per
 // contiguous block.
 func BuildMapping(vals []*Validator) []MappingEntry {
+	return buildMapping(len(vals), func(i int) (string, uint64) {
+		return vals[i].Source, vals[i].SourceKeyIndex
+	})
+}
+
+// BuildBuilderMapping derives the mapping of the builder registry indices to
+// source key ranges, the same way BuildMapping does for validators.
+func BuildBuilderMapping(builders []*Builder) []MappingEntry {
+	return buildMapping(len(builders), func(i int) (string, uint64) {
+		return builders[i].Source, builders[i].SourceKeyIndex
+	})
+}
+
+// buildMapping collapses the count (source, key index) pairs returned by key
+// into mapping entries.
+func buildMapping(count int, key func(i int) (source string, keyIndex uint64)) []MappingEntry {
 	entries := make([]MappingEntry, 0)
-	if len(vals) == 0 {
+	if count == 0 {
 		return entries
 	}
 
 	start := 0
 
-	for i := 1; i <= len(vals); i++ {
-		// A run continues while the next validator shares the source and its key
-		// index follows the previous one. The final iteration (i == len) always
-		// closes the open run.
-		continues := i < len(vals) &&
-			vals[i].Source == vals[start].Source &&
-			vals[i].SourceKeyIndex == vals[i-1].SourceKeyIndex+1
-
-		if continues {
-			continue
+	for i := 1; i <= count; i++ {
+		startSource, startKeyIndex := key(start)
+		_, prevKeyIndex := key(i - 1)
+
+		// A run continues while the next entry shares the source and its key
+		// index follows the previous one. The final iteration (i == count)
+		// always closes the open run.
+		if i < count {
+			source, keyIndex := key(i)
+			if source == startSource && keyIndex == prevKeyIndex+1 {
+				continue
+			}
 		}
 
 		//nolint:gosec // start and i-1 are loop indices, always >= 0
 		entries = append(entries, MappingEntry{
 			StateIndexFrom: uint64(start),
 			StateIndexTo:   uint64(i - 1),
-			Source:         vals[start].Source,
-			KeyIndexFrom:   vals[start].SourceKeyIndex,
-			KeyIndexTo:     vals[i-1].SourceKeyIndex,
+			Source:         startSource,
+			KeyIndexFrom:   startKeyIndex,
+			KeyIndexTo:     prevKeyIndex,
 		})
 
 		start = i
@@ -69,8 +91,10 @@ func BuildMapping(vals []*Validator) []MappingEntry {
 //
 //   - <state-from>-<state-to>: { src: "<source>", from: <key-from>, to: <key-to> }
 func WriteMappingFile(path string, vals []*Validator) error {
-	entries := BuildMapping(vals)
+	return writeMappingEntries(path, BuildMapping(vals))
+}
 
+func writeMappingEntries(path string, entries []MappingEntry) error {
 	var sb strings.Builder
 
 	for _, e := range entries {
@@ -84,3 +108,131 @@ func WriteMappingFile(path string, vals []*Validator) error {
 
 	return nil
 }
+
+// mappingValue is the value of a single validator-mapping line.
+type mappingValue struct {
+	Source string  `yaml:"src"`
+	From   *uint64 `yaml:"from"`
+	To     *uint64 `yaml:"to"`
+}
+
+// LoadMappingFile reads a validator mapping written by WriteMappingFile. The
+// entries are returned ordered by state index; overlapping state ranges and
+// ranges whose state and key lengths differ are rejected.
+func LoadMappingFile(path string) ([]MappingEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read validator mapping file: %w", err)
+	}
+
+	return ParseMapping(data)
+}
+
+// ParseMapping parses the content of a validator mapping file.
+func ParseMapping(data []byte) ([]MappingEntry, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode validator mapping: %w", err)
+	}
+
+	entries := make([]MappingEntry, 0)
+	if len(doc.Content) == 0 {
+		return entries, nil
+	}
+
+	list := doc.Content[0]
+	if list.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("validator mapping on line %d is not a list", list.Line)
+	}
+
+	for _, item := range list.Content {
+		if item.Kind != yaml.MappingNode || len(item.Content) != 2 {
+			return nil, fmt.Errorf("invalid validator mapping entry on line %d", item.Line)
+		}
+
+		entry, err := parseMappingEntry(item.Content[0], item.Content[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid validator mapping entry on line %d: %w", item.Line, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].StateIndexFrom < entries[j].StateIndexFrom
+	})
+
+	for i := 1; i < len(entries); i++ {
+		if entries[i].StateIndexFrom <= entries[i-1].StateIndexTo {
+			return nil, fmt.Errorf("overlapping validator mapping entries %d-%d and %d-%d",
+				entries[i-1].StateIndexFrom, entries[i-1].StateIndexTo, entries[i].StateIndexFrom, entries[i].StateIndexTo)
+		}
+	}
+
+	return entries, nil
+}
+
+func parseMappingEntry(keyNode, valueNode *yaml.Node) (MappingEntry, error) {
+	entry := MappingEntry{}
+
+	fromStr, toStr, found := strings.Cut(keyNode.Value, "-")
+	if !found {
+		return entry, fmt.Errorf("invalid state index range %q", keyNode.Value)
+	}
+
+	var err error
+
+	if entry.StateIndexFrom, err = strconv.ParseUint(fromStr, 10, 64); err != nil {
+		return entry, fmt.Errorf("invalid state index range %q: %w", keyNode.Value, err)
+	}
+
+	if entry.StateIndexTo, err = strconv.ParseUint(toStr, 10, 64); err != nil {
+		return entry, fmt.Errorf("invalid state index range %q: %w", keyNode.Value, err)
+	}
+
+	value := mappingValue{}
+	if err := valueNode.Decode(&value); err != nil {
+		return entry, err
+	}
+
+	if value.Source == "" || value.From == nil || value.To == nil {
+		return entry, fmt.Errorf("src, from and to are required")
+	}
+
+	entry.Source = value.Source
+	entry.KeyIndexFrom = *value.From
+	entry.KeyIndexTo = *value.To
+
+	if entry.StateIndexTo < entry.StateIndexFrom || entry.KeyIndexTo < entry.KeyIndexFrom ||
+		entry.StateIndexTo-entry.StateIndexFrom != entry.KeyIndexTo-entry.KeyIndexFrom {
+		return entry, fmt.Errorf("state range %d-%d does not match key range %d-%d",
+			entry.StateIndexFrom, entry.StateIndexTo, entry.KeyIndexFrom, entry.KeyIndexTo)
+	}
+
+	return entry, nil
+}
+
+// LookupStateIndex resolves a state validator index to its source and key
+// index. entries must be ordered by state index (see LoadMappingFile).
+func LookupStateIndex(entries []MappingEntry, stateIndex uint64) (source string, keyIndex uint64, found bool) {
+	i := sort.Search(len(entries), func(i int) bool {
+		return entries[i].StateIndexTo >= stateIndex
+	})
+
+	if i == len(entries) || entries[i].StateIndexFrom > stateIndex {
+		return "", 0, false
+	}
+
+	return entries[i].Source, entries[i].KeyIndexFrom + (stateIndex - entries[i].StateIndexFrom), true
+}
+
+// LookupKeyIndex resolves a key index of a source to its state validator index.
+func LookupKeyIndex(entries []MappingEntry, source string, keyIndex uint64) (stateIndex uint64, found bool) {
+	for _, e := range entries {
+		if e.Source == source && keyIndex >= e.KeyIndexFrom && keyIndex <= e.KeyIndexTo {
+			return e.StateIndexFrom + (keyIndex - e.KeyIndexFrom), true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,280 @@
+package validators
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+)
+
+// Validator mapping output formats.
+const (
+	// MappingFormatYAML writes the compact range mapping (see WriteMappingFile).
+	MappingFormatYAML = "yaml"
+
+	// MappingFormatJSON writes a JSON array with one ValidatorRow per validator.
+	MappingFormatJSON = "json"
+
+	// MappingFormatCSV writes a CSV table with one ValidatorRow per validator.
+	MappingFormatCSV = "csv"
+)
+
+// ValidatorRow is a single validator of the per-validator mapping output.
+type ValidatorRow struct {
+	StateIndex            uint64 `json:"state_index"`
+	Source                string `json:"source"`
+	KeyIndex              uint64 `json:"key_index"`
+	Pubkey                string `json:"pubkey"`
+	WithdrawalCredentials string `json:"withdrawal_credentials"`
+	Balance               uint64 `json:"balance"`
+	EffectiveBalance      uint64 `json:"effective_balance"`
+	Status                string `json:"status"`
+}
+
+// validatorRowColumns are the CSV columns, in the order of ValidatorRow.
+var validatorRowColumns = []string{
+	"state_index", "source", "key_index", "pubkey", "withdrawal_credentials", "balance", "effective_balance", "status",
+}
+
+func (r *ValidatorRow) csvRecord() []string {
+	return []string{
+		strconv.FormatUint(r.StateIndex, 10),
+		r.Source,
+		strconv.FormatUint(r.KeyIndex, 10),
+		r.Pubkey,
+		r.WithdrawalCredentials,
+		strconv.FormatUint(r.Balance, 10),
+		strconv.FormatUint(r.EffectiveBalance, 10),
+		r.Status,
+	}
+}
+
+// newValidatorRow returns the row of the validator at stateIndex, with the
+// balance and effective balance it got in the genesis state.
+func newValidatorRow(stateIndex int, val *Validator, stateVal *phase0.Validator, balance phase0.Gwei) *ValidatorRow {
+	return &ValidatorRow{
+		StateIndex:            uint64(stateIndex), //nolint:gosec // stateIndex is a slice index, always >= 0
+		Source:                val.Source,
+		KeyIndex:              val.SourceKeyIndex,
+		Pubkey:                val.PublicKey.String(),
+		WithdrawalCredentials: fmt.Sprintf("0x%x", val.WithdrawalCredentials),
+		Balance:               uint64(balance),
+		EffectiveBalance:      uint64(stateVal.EffectiveBalance),
+		Status:                val.Status.String(),
+	}
+}
+
+// BuilderRow is a single builder of the per-builder mapping output.
+type BuilderRow struct {
+	BuilderIndex      uint64 `json:"builder_index"`
+	Source            string `json:"source"`
+	KeyIndex          uint64 `json:"key_index"`
+	Pubkey            string `json:"pubkey"`
+	ExecutionAddress  string `json:"execution_address"`
+	Balance           uint64 `json:"balance"`
+	Version           uint8  `json:"version"`
+	DepositEpoch      uint64 `json:"deposit_epoch"`
+	WithdrawableEpoch uint64 `json:"withdrawable_epoch"`
+}
+
+// builderRowColumns are the CSV columns, in the order of BuilderRow.
+var builderRowColumns = []string{
+	"builder_index", "source", "key_index", "pubkey", "execution_address", "balance", "version", "deposit_epoch", "withdrawable_epoch",
+}
+
+func (r *BuilderRow) csvRecord() []string {
+	return []string{
+		strconv.FormatUint(r.BuilderIndex, 10),
+		r.Source,
+		strconv.FormatUint(r.KeyIndex, 10),
+		r.Pubkey,
+		r.ExecutionAddress,
+		strconv.FormatUint(r.Balance, 10),
+		strconv.FormatUint(uint64(r.Version), 10),
+		strconv.FormatUint(r.DepositEpoch, 10),
+		strconv.FormatUint(r.WithdrawableEpoch, 10),
+	}
+}
+
+// newBuilderRow returns the row of the builder at builderIndex.
+func newBuilderRow(builderIndex int, builder *Builder, defaultBalance, farFutureEpoch uint64) *BuilderRow {
+	row := &BuilderRow{
+		BuilderIndex:      uint64(builderIndex), //nolint:gosec // builderIndex is a slice index, always >= 0
+		Source:            builder.Source,
+		KeyIndex:          builder.SourceKeyIndex,
+		Pubkey:            builder.PublicKey.String(),
+		ExecutionAddress:  fmt.Sprintf("0x%x", builder.ExecutionAddress[:]),
+		Balance:           defaultBalance,
+		Version:           builder.Version,
+		DepositEpoch:      builder.DepositEpoch,
+		WithdrawableEpoch: farFutureEpoch,
+	}
+
+	if builder.Balance != nil {
+		row.Balance = *builder.Balance
+	}
+
+	if builder.WithdrawableEpoch != nil {
+		row.WithdrawableEpoch = *builder.WithdrawableEpoch
+	}
+
+	return row
+}
+
+// mappingRow is a row of the per-validator or per-builder mapping output.
+type mappingRow interface {
+	csvRecord() []string
+}
+
+// WriteMappingFileFormat writes the validator mapping to path in the given
+// format. MappingFormatYAML writes the range mapping of WriteMappingFile, the
+// other formats write one row per validator (see WriteValidatorRows).
+func WriteMappingFileFormat(path, format string, vals []*Validator, stateVals []*phase0.Validator, balances []phase0.Gwei) error {
+	if format == "" || format == MappingFormatYAML {
+		return WriteMappingFile(path, vals)
+	}
+
+	if err := checkRowFormat(format); err != nil {
+		return err
+	}
+
+	return writeRowsFile(path, func(w io.Writer) error {
+		return WriteValidatorRows(w, format, vals, stateVals, balances)
+	})
+}
+
+// WriteBuilderMappingFileFormat writes the builder mapping to path in the given
+// format, like WriteMappingFileFormat does for validators. Builders without an
+// explicit withdrawable epoch are reported with farFutureEpoch.
+func WriteBuilderMappingFileFormat(path, format string, builders []*Builder, defaultBalance, farFutureEpoch uint64) error {
+	if format == "" || format == MappingFormatYAML {
+		return writeMappingEntries(path, BuildBuilderMapping(builders))
+	}
+
+	if err := checkRowFormat(format); err != nil {
+		return err
+	}
+
+	return writeRowsFile(path, func(w io.Writer) error {
+		return writeRows(w, format, builderRowColumns, len(builders), func(i int) *BuilderRow {
+			return newBuilderRow(i, builders[i], defaultBalance, farFutureEpoch)
+		})
+	})
+}
+
+// WriteValidatorRows writes one row per validator to w as JSON array or CSV
+// table. The balances are taken from the validator registry (stateVals) and
+// balances of the genesis state, which must be in the order of vals. Rows are
+// encoded one at a time, so the memory needed does not grow with the number of
+// validators.
+func WriteValidatorRows(w io.Writer, format string, vals []*Validator, stateVals []*phase0.Validator, balances []phase0.Gwei) error {
+	if len(stateVals) != len(vals) || len(balances) != len(vals) {
+		return fmt.Errorf("genesis state has %d validators and %d balances, expected %d", len(stateVals), len(balances), len(vals))
+	}
+
+	return writeRows(w, format, validatorRowColumns, len(vals), func(i int) *ValidatorRow {
+		return newValidatorRow(i, vals[i], stateVals[i], balances[i])
+	})
+}
+
+func checkRowFormat(format string) error {
+	if format != MappingFormatJSON && format != MappingFormatCSV {
+		return fmt.Errorf("unsupported mapping format %q", format)
+	}
+
+	return nil
+}
+
+func writeRowsFile(path string, write func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create mapping file: %w", err)
+	}
+
+	if err := write(file); err != nil {
+		file.Close()
+
+		return fmt.Errorf("failed to write mapping file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write mapping file: %w", err)
+	}
+
+	return nil
+}
+
+// writeRows writes count rows returned by row to w in the given format.
+func writeRows[T mappingRow](w io.Writer, format string, columns []string, count int, row func(i int) T) error {
+	writer := bufio.NewWriter(w)
+
+	var err error
+
+	switch format {
+	case MappingFormatJSON:
+		err = writeRowsJSON(writer, count, row)
+	case MappingFormatCSV:
+		err = writeRowsCSV(writer, columns, count, row)
+	default:
+		err = fmt.Errorf("unsupported mapping format %q", format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+func writeRowsJSON[T mappingRow](w *bufio.Writer, count int, row func(i int) T) error {
+	if _, err := w.WriteString("["); err != nil {
+		return err
+	}
+
+	for i := range count {
+		data, err := json.Marshal(row(i))
+		if err != nil {
+			return err
+		}
+
+		separator := ",\n  "
+		if i == 0 {
+			separator = "\n  "
+		}
+
+		if _, err := w.WriteString(separator); err != nil {
+			return err
+		}
+
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.WriteString("\n]\n")
+
+	return err
+}
+
+func writeRowsCSV[T mappingRow](w *bufio.Writer, columns []string, count int, row func(i int) T) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+
+	for i := range count {
+		if err := writer.Write(row(i).csvRecord()); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
@@ -0,0 +1,148 @@
+package validators
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+)
+
+func makeRowValidators() []*Validator {
+	balance := uint64(64_000_000_000)
+	vals := append(makeValidators("mnemonic-0", 2), makeValidators("operator-b", 1)...)
+
+	for _, val := range vals {
+		val.WithdrawalCredentials = append([]byte{0x01}, make([]byte, 31)...)
+	}
+
+	vals[1].Balance = &balance
+	vals[2].Status = ValidatorStatusExited
+
+	return vals
+}
+
+// makeRowState returns the registry and balances a genesis state would hold
+// for vals: the balance as given, the effective balance capped at 32 ETH.
+func makeRowState(vals []*Validator) ([]*phase0.Validator, []phase0.Gwei) {
+	stateVals := make([]*phase0.Validator, len(vals))
+	balances := make([]phase0.Gwei, len(vals))
+
+	for i, val := range vals {
+		balances[i] = 32_000_000_000
+		if val.Balance != nil {
+			balances[i] = phase0.Gwei(*val.Balance)
+		}
+
+		stateVals[i] = &phase0.Validator{
+			PublicKey:        val.PublicKey,
+			EffectiveBalance: min(balances[i], 32_000_000_000),
+		}
+	}
+
+	return stateVals, balances
+}
+
+func TestWriteValidatorRows_JSON(t *testing.T) {
+	vals := makeRowValidators()
+	stateVals, balances := makeRowState(vals)
+
+	var buf bytes.Buffer
+	if err := WriteValidatorRows(&buf, MappingFormatJSON, vals, stateVals, balances); err != nil {
+		t.Fatalf("failed to write rows: %v", err)
+	}
+
+	rows := []*ValidatorRow{}
+	if err := json.Unmarshal(buf.Bytes(), &rows); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, buf.String())
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(rows))
+	}
+
+	want := ValidatorRow{
+		StateIndex:            1,
+		Source:                "mnemonic-0",
+		KeyIndex:              1,
+		Pubkey:                vals[1].PublicKey.String(),
+		WithdrawalCredentials: "0x01" + strings.Repeat("00", 31),
+		Balance:               64_000_000_000,
+		EffectiveBalance:      32_000_000_000,
+		Status:                "active",
+	}
+	if *rows[1] != want {
+		t.Fatalf("row 1 = %+v, want %+v", *rows[1], want)
+	}
+
+	if rows[0].Balance != 32_000_000_000 || rows[0].EffectiveBalance != 32_000_000_000 || rows[2].Status != "exited" || rows[2].Source != "operator-b" || rows[2].KeyIndex != 0 {
+		t.Fatalf("unexpected rows: %+v, %+v", *rows[0], *rows[2])
+	}
+}
+
+func TestWriteValidatorRows_EmptyJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteValidatorRows(&buf, MappingFormatJSON, nil, nil, nil); err != nil {
+		t.Fatalf("failed to write rows: %v", err)
+	}
+
+	rows := []*ValidatorRow{}
+	if err := json.Unmarshal(buf.Bytes(), &rows); err != nil || len(rows) != 0 {
+		t.Fatalf("expected empty json array, got %q (%v)", buf.String(), err)
+	}
+}
+
+func TestWriteMappingFileFormat_CSV(t *testing.T) {
+	vals := makeRowValidators()
+	stateVals, balances := makeRowState(vals)
+	path := filepath.Join(t.TempDir(), "mapping.csv")
+
+	if err := WriteMappingFileFormat(path, MappingFormatCSV, vals, stateVals, balances); err != nil {
+		t.Fatalf("failed to write mapping: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open mapping: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid csv output: %v", err)
+	}
+
+	if len(records) != 4 || strings.Join(records[0], ",") != "state_index,source,key_index,pubkey,withdrawal_credentials,balance,effective_balance,status" {
+		t.Fatalf("unexpected csv header or row count: %v", records)
+	}
+
+	if got := strings.Join(records[3], ","); got != "2,operator-b,0,"+vals[2].PublicKey.String()+",0x01"+strings.Repeat("00", 31)+",32000000000,32000000000,exited" {
+		t.Fatalf("unexpected csv row %s", got)
+	}
+}
+
+func TestWriteMappingFileFormat_Invalid(t *testing.T) {
+	vals := makeRowValidators()
+	stateVals, balances := makeRowState(vals)
+
+	err := WriteMappingFileFormat(filepath.Join(t.TempDir(), "mapping.xml"), "xml", vals, stateVals, balances)
+	if err == nil || !strings.Contains(err.Error(), "unsupported mapping format") {
+		t.Fatalf("expected unsupported format error, got %v", err)
+	}
+}
+
+func TestWriteValidatorRows_StateMismatch(t *testing.T) {
+	vals := makeRowValidators()
+	stateVals, balances := makeRowState(vals[:2])
+
+	var buf bytes.Buffer
+
+	err := WriteValidatorRows(&buf, MappingFormatCSV, vals, stateVals, balances)
+	if err == nil || !strings.Contains(err.Error(), "genesis state has 2 validators and 2 balances, expected 3") {
+		t.Fatalf("expected state mismatch error, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package validators
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -96,3 +97,70 @@ func TestWriteMappingFile(t *testing.T) {
 		t.Fatalf("unexpected mapping file content:\ngot:\n%s\nwant:\n%s", string(data), want)
 	}
 }
+
+func TestLoadMappingFile_RoundTrip(t *testing.T) {
+	vals := makeValidators("mnemonic-0", 1000)
+	vals = append(vals, makeValidators("additional-validators", 50)...)
+	ShuffleValidators(vals, 321)
+
+	path := filepath.Join(t.TempDir(), "mapping.yaml")
+	if err := WriteMappingFile(path, vals); err != nil {
+		t.Fatalf("WriteMappingFile failed: %v", err)
+	}
+
+	entries, err := LoadMappingFile(path)
+	if err != nil {
+		t.Fatalf("LoadMappingFile failed: %v", err)
+	}
+
+	want := BuildMapping(vals)
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %d", len(want), len(entries))
+	}
+
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Fatalf("entry %d = %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+
+	for i, val := range vals {
+		source, keyIndex, found := LookupStateIndex(entries, uint64(i))
+		if !found || source != val.Source || keyIndex != val.SourceKeyIndex {
+			t.Fatalf("state index %d resolved to %s/%d, want %s/%d", i, source, keyIndex, val.Source, val.SourceKeyIndex)
+		}
+
+		stateIndex, found := LookupKeyIndex(entries, val.Source, val.SourceKeyIndex)
+		if !found || stateIndex != uint64(i) {
+			t.Fatalf("key %s/%d resolved to state index %d, want %d", val.Source, val.SourceKeyIndex, stateIndex, i)
+		}
+	}
+
+	if _, _, found := LookupStateIndex(entries, uint64(len(vals))); found {
+		t.Fatalf("expected state index beyond the mapping not to be found")
+	}
+
+	if _, found := LookupKeyIndex(entries, "mnemonic-1", 0); found {
+		t.Fatalf("expected unknown source not to be found")
+	}
+}
+
+func TestParseMapping_Invalid(t *testing.T) {
+	tests := []struct {
+		data    string
+		wantErr string
+	}{
+		{"- 0-9: { src: \"a\", from: 0 }\n", "src, from and to are required"},
+		{"- 0-9: { src: \"a\", from: 0, to: 8 }\n", "does not match key range"},
+		{"- 0_9: { src: \"a\", from: 0, to: 9 }\n", "invalid state index range"},
+		{"- 0-9: { src: \"a\", from: 0, to: 9 }\n- 5-9: { src: \"b\", from: 0, to: 4 }\n", "overlapping"},
+		{"src: a\n", "is not a list"},
+	}
+
+	for _, test := range tests {
+		_, err := ParseMapping([]byte(test.data))
+		if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Fatalf("expected error containing %q for %q, got %v", test.wantErr, test.data, err)
+		}
+	}
+}
@@ -6,20 +6,26 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync/atomic"
 
-	"github.com/ethpandaops/go-eth2-client/spec/phase0"
 	"github.com/sirupsen/logrus"
 	"github.com/tyler-smith/go-bip39"
 	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v3"
-
-	e2util "github.com/wealdtech/go-eth2-util"
 )
 
 func GenerateValidatorsByMnemonic(mnemonicsConfigPath string) ([]*Validator, error) {
-	mnemonics, err := loadMnemonics(mnemonicsConfigPath)
+	return GenerateValidatorsByMnemonicWithCache(mnemonicsConfigPath, nil)
+}
+
+// GenerateValidatorsByMnemonicWithCache works like GenerateValidatorsByMnemonic,
+// but looks up derived keys in keyCache first and stores newly derived keys in
+// it. A nil keyCache disables caching.
+func GenerateValidatorsByMnemonicWithCache(mnemonicsConfigPath string, keyCache *KeyCache) ([]*Validator, error) {
+	mnemonics, err := LoadMnemonics(mnemonicsConfigPath)
 	if err != nil {
 		return nil, err
 	}
@@ -42,16 +48,18 @@ func GenerateValidatorsByMnemonic(mnemonicsConfigPath string) ([]*Validator, err
 
 		logrus.Infof("processing mnemonic %d, for %d validators", m, mnemonicSrc.Count)
 
-		seed, err := seedFromMnemonic(mnemonicSrc.Mnemonic)
+		seed, err := seedFromMnemonic(mnemonicSrc.Mnemonic, mnemonicSrc.Passphrase)
 		if err != nil {
 			return nil, fmt.Errorf("mnemonic %d is bad", m)
 		}
 
-		// Use the explicit name for the validator mapping if provided, otherwise
-		// fall back to the mnemonic's index in the list.
-		source := mnemonicSrc.Name
-		if source == "" {
-			source = fmt.Sprintf("mnemonic-%d", m)
+		source := mnemonicSrc.SourceName(m)
+
+		// 0x00 credentials are derived from the withdrawal keys
+		usesWithdrawalKeys := mnemonicSrc.WdPrefix == "" || mnemonicSrc.WdPrefix == "0x00" || mnemonicSrc.WdAddress == ""
+
+		if err := verifyKeyCacheRanges(keyCache, seed, &mnemonicSrc, usesWithdrawalKeys); err != nil {
+			return nil, fmt.Errorf("failed to verify key cache of mnemonic %d: %w", m, err)
 		}
 
 		for i := uint64(0); i < mnemonicSrc.Count; i++ {
@@ -59,20 +67,20 @@ func GenerateValidatorsByMnemonic(mnemonicsConfigPath string) ([]*Validator, err
 			idx := mnemonicSrc.Start + i
 
 			g.Go(func() error {
-				signingSK, err := e2util.PrivateKeyFromSeedAndPath(seed, validatorKeyName(idx))
+				signingPubkey, err := keyCache.DerivePubkey(seed, mnemonicSrc.SigningPath, idx)
 				if err != nil {
 					return err
 				}
 
 				data := &Validator{
-					PublicKey:             phase0.BLSPubKey(signingSK.PublicKey().Marshal()),
+					PublicKey:             signingPubkey,
 					WithdrawalCredentials: make([]byte, 32),
 					Status:                mnemonicSrc.Status,
 					Source:                source,
 					SourceKeyIndex:        idx,
 				}
 
-				if mnemonicSrc.WdPrefix != "" && mnemonicSrc.WdPrefix != "0x00" && mnemonicSrc.WdAddress != "" {
+				if !usesWithdrawalKeys {
 					// set withdrawal address (0x01 or 0x02 credentials)
 					address, err := hex.DecodeString(strings.ReplaceAll(mnemonicSrc.WdAddress, "0x", ""))
 					if err != nil {
@@ -83,19 +91,15 @@ func GenerateValidatorsByMnemonic(mnemonicsConfigPath string) ([]*Validator, err
 					data.WithdrawalCredentials[0] = 0x01
 				} else {
 					// set withdrawal BLS pubkey (0x00 credentials)
-					wdkeyPath := mnemonicSrc.WdKeyPath
-					if wdkeyPath == "" {
-						wdkeyPath = withdrawalKeyName(idx)
-					}
+					wdTemplate, wdIndex := mnemonicSrc.withdrawalKey(idx)
 
-					withdrawSK, err := e2util.PrivateKeyFromSeedAndPath(seed, wdkeyPath)
+					withdrawPub, err := keyCache.DerivePubkey(seed, wdTemplate, wdIndex)
 					if err != nil {
 						return err
 					}
 
-					withdrawPub := withdrawSK.PublicKey().Marshal()
 					h := sha256.New()
-					h.Write(withdrawPub)
+					h.Write(withdrawPub[:])
 					copy(data.WithdrawalCredentials, h.Sum(nil))
 					data.WithdrawalCredentials[0] = 0x00
 				}
@@ -132,24 +136,106 @@ func GenerateValidatorsByMnemonic(mnemonicsConfigPath string) ([]*Validator, err
 		}
 	}
 
+	if err := keyCache.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save key cache: %w", err)
+	}
+
 	return validators, nil
 }
 
-func validatorKeyName(i uint64) string {
-	return fmt.Sprintf("m/12381/3600/%d/0/0", i)
+const (
+	// keyPathIndex is the placeholder for the key index in derivation path
+	// templates.
+	keyPathIndex = "{index}"
+
+	// DefaultSigningPath and DefaultWithdrawalPath are the EIP-2334 signing and
+	// withdrawal key paths.
+	DefaultSigningPath    = "m/12381/3600/" + keyPathIndex + "/0/0"
+	DefaultWithdrawalPath = "m/12381/3600/" + keyPathIndex + "/0"
+)
+
+// keyPath fills the key index into a derivation path template.
+// verifyKeyCacheRanges verifies the cached signing keys and, if used, the
+// cached withdrawal keys of a mnemonic range.
+func verifyKeyCacheRanges(keyCache *KeyCache, seed []byte, mnemonicSrc *MnemonicSrc, usesWithdrawalKeys bool) error {
+	if mnemonicSrc.Count == 0 {
+		return nil
+	}
+
+	first, last := mnemonicSrc.Start, mnemonicSrc.Start+mnemonicSrc.Count-1
+
+	if err := keyCache.VerifyRange(seed, mnemonicSrc.SigningPath, first, last); err != nil {
+		return err
+	}
+
+	if !usesWithdrawalKeys {
+		return nil
+	}
+
+	firstTemplate, firstIndex := mnemonicSrc.withdrawalKey(first)
+	lastTemplate, lastIndex := mnemonicSrc.withdrawalKey(last)
+
+	if err := keyCache.VerifyRange(seed, firstTemplate, firstIndex, firstIndex); err != nil {
+		return err
+	}
+
+	return keyCache.VerifyRange(seed, lastTemplate, lastIndex, lastIndex)
+}
+
+// withdrawalKey returns the path template and index of the withdrawal key of
+// the validator at idx. A fixed withdrawal key path is its own template.
+func (m *MnemonicSrc) withdrawalKey(idx uint64) (string, uint64) {
+	if m.WdKeyPath != "" {
+		return m.WdKeyPath, 0
+	}
+
+	return m.WithdrawalPath, idx
+}
+
+func keyPath(template string, i uint64) string {
+	return strings.ReplaceAll(template, keyPathIndex, strconv.FormatUint(i, 10))
 }
 
-func withdrawalKeyName(i uint64) string {
-	return fmt.Sprintf("m/12381/3600/%d/0", i)
+// validateKeyPath checks a derivation path (template) for the format accepted
+// by EIP-2333 key derivation: "m" followed by numeric components. Templates
+// must contain the {index} placeholder as a whole component exactly once.
+func validateKeyPath(path string, isTemplate bool) error {
+	components := strings.Split(path, "/")
+	if components[0] != "m" {
+		return fmt.Errorf("path %q must start with \"m\"", path)
+	}
+
+	if len(components) < 2 {
+		return fmt.Errorf("path %q has no components", path)
+	}
+
+	placeholders := 0
+
+	for _, component := range components[1:] {
+		if isTemplate && component == keyPathIndex {
+			placeholders++
+			continue
+		}
+
+		if _, err := strconv.ParseUint(component, 10, 32); err != nil {
+			return fmt.Errorf("path %q has invalid component %q", path, component)
+		}
+	}
+
+	if isTemplate && placeholders != 1 {
+		return fmt.Errorf("path template %q must contain %s exactly once", path, keyPathIndex)
+	}
+
+	return nil
 }
 
-func seedFromMnemonic(mnemonic string) (seed []byte, err error) {
+func seedFromMnemonic(mnemonic, passphrase string) (seed []byte, err error) {
 	mnemonic = strings.TrimSpace(mnemonic)
 	if !bip39.IsMnemonicValid(mnemonic) {
 		return nil, errors.New("mnemonic is not valid")
 	}
 
-	return bip39.NewSeed(mnemonic, ""), nil
+	return bip39.NewSeed(mnemonic, passphrase), nil
 }
 
 type MnemonicSrc struct {
@@ -162,9 +248,31 @@ type MnemonicSrc struct {
 	WdPrefix  string          `yaml:"wd_prefix"`
 	WdKeyPath string          `yaml:"wd_key_path"`
 	Status    ValidatorStatus `yaml:"status"`
+
+	// Passphrase is the optional BIP39 passphrase, either given inline or read
+	// from PassphraseFile (relative paths are resolved against the mnemonics file).
+	Passphrase     string `yaml:"passphrase"`
+	PassphraseFile string `yaml:"passphrase_file"`
+
+	// SigningPath and WithdrawalPath are derivation path templates with an
+	// {index} placeholder. They default to the EIP-2334 paths.
+	SigningPath    string `yaml:"signing_path"`
+	WithdrawalPath string `yaml:"withdrawal_path"`
+}
+
+// SourceName returns the source name used in the validator mapping for the
+// mnemonic at position index of the mnemonics file: the explicit name if
+// provided, otherwise "mnemonic-<index>".
+func (m *MnemonicSrc) SourceName(index int) string {
+	if m.Name != "" {
+		return m.Name
+	}
+
+	return fmt.Sprintf("mnemonic-%d", index)
 }
 
-func loadMnemonics(srcPath string) ([]MnemonicSrc, error) {
+// LoadMnemonics loads and validates the mnemonic sources from a mnemonics file.
+func LoadMnemonics(srcPath string) ([]MnemonicSrc, error) {
 	f, err := os.Open(srcPath)
 	if err != nil {
 		return nil, err
@@ -179,5 +287,58 @@ func loadMnemonics(srcPath string) ([]MnemonicSrc, error) {
 		return nil, err
 	}
 
+	for i := range data {
+		if err := data[i].prepare(filepath.Dir(srcPath)); err != nil {
+			return nil, fmt.Errorf("mnemonic %d: %w", i, err)
+		}
+	}
+
 	return data, nil
 }
+
+// prepare validates the optional fields of a mnemonic source, fills in the
+// default derivation paths and loads the passphrase file.
+func (m *MnemonicSrc) prepare(baseDir string) error {
+	if m.SigningPath == "" {
+		m.SigningPath = DefaultSigningPath
+	}
+
+	if m.WithdrawalPath == "" {
+		m.WithdrawalPath = DefaultWithdrawalPath
+	}
+
+	if err := validateKeyPath(m.SigningPath, true); err != nil {
+		return fmt.Errorf("invalid signing_path: %w", err)
+	}
+
+	if err := validateKeyPath(m.WithdrawalPath, true); err != nil {
+		return fmt.Errorf("invalid withdrawal_path: %w", err)
+	}
+
+	if m.WdKeyPath != "" {
+		if err := validateKeyPath(m.WdKeyPath, false); err != nil {
+			return fmt.Errorf("invalid wd_key_path: %w", err)
+		}
+	}
+
+	if m.PassphraseFile != "" {
+		if m.Passphrase != "" {
+			return fmt.Errorf("passphrase and passphrase_file are mutually exclusive")
+		}
+
+		passphrasePath := m.PassphraseFile
+		if !filepath.IsAbs(passphrasePath) {
+			passphrasePath = filepath.Join(baseDir, passphrasePath)
+		}
+
+		passphrase, err := os.ReadFile(passphrasePath)
+		if err != nil {
+			return fmt.Errorf("failed to read passphrase file: %w", err)
+		}
+
+		// only strip the line break, spaces are valid passphrase characters
+		m.Passphrase = strings.TrimRight(string(passphrase), "\r\n")
+	}
+
+	return nil
+}
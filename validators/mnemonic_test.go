@@ -2,7 +2,9 @@ package validators
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -267,3 +269,133 @@ func TestGenerateValidatorsByMnemonic_MassKeys(t *testing.T) {
 		t.Fatalf("expected 200 validators, got %d", len(validators))
 	}
 }
+
+func TestGenerateValidatorsByMnemonic_Passphrase(t *testing.T) {
+	initTestBLS(t)
+
+	passphraseFile := filepath.Join(t.TempDir(), "passphrase.txt")
+	if err := os.WriteFile(passphraseFile, []byte("correct horse battery staple\n"), 0o600); err != nil {
+		t.Fatalf("failed to write passphrase file: %v", err)
+	}
+
+	mnemonicsFile := createTestMnemonicsFile(t, `
+- mnemonic: "`+testMnemonic+`"
+  count: 1
+- mnemonic: "`+testMnemonic+`"
+  count: 1
+  passphrase: "correct horse battery staple"
+- mnemonic: "`+testMnemonic+`"
+  count: 1
+  passphrase_file: "`+passphraseFile+`"
+`)
+
+	validators, err := GenerateValidatorsByMnemonic(mnemonicsFile)
+	if err != nil {
+		t.Fatalf("failed to load validators from mnemonics: %v", err)
+	}
+
+	if validators[0].PublicKey == validators[1].PublicKey {
+		t.Fatalf("expected the passphrase to change the derived key")
+	}
+
+	if validators[1].PublicKey != validators[2].PublicKey {
+		t.Fatalf("expected passphrase and passphrase_file to derive the same key, got %s and %s", validators[1].PublicKey.String(), validators[2].PublicKey.String())
+	}
+
+	seed, err := seedFromMnemonic(testMnemonic, "correct horse battery staple")
+	if err != nil {
+		t.Fatalf("failed to derive seed: %v", err)
+	}
+
+	want, err := derivePubkey(seed, keyPath(DefaultSigningPath, 0))
+	if err != nil {
+		t.Fatalf("failed to derive pubkey: %v", err)
+	}
+
+	if validators[1].PublicKey != want {
+		t.Fatalf("expected pubkey %s, got %s", want.String(), validators[1].PublicKey.String())
+	}
+}
+
+func TestGenerateValidatorsByMnemonic_PathTemplates(t *testing.T) {
+	initTestBLS(t)
+
+	mnemonicsFile := createTestMnemonicsFile(t, `
+- mnemonic: "`+testMnemonic+`"
+  start: 3
+  count: 2
+  wd_prefix: "0x00"
+  signing_path: "m/12381/3600/0/{index}/0"
+  withdrawal_path: "m/12381/3600/1/{index}"
+`)
+
+	validators, err := GenerateValidatorsByMnemonic(mnemonicsFile)
+	if err != nil {
+		t.Fatalf("failed to load validators from mnemonics: %v", err)
+	}
+
+	seed, err := seedFromMnemonic(testMnemonic, "")
+	if err != nil {
+		t.Fatalf("failed to derive seed: %v", err)
+	}
+
+	for i, val := range validators {
+		idx := uint64(3 + i)
+
+		signingPubkey, err := derivePubkey(seed, fmt.Sprintf("m/12381/3600/0/%d/0", idx))
+		if err != nil {
+			t.Fatalf("failed to derive pubkey: %v", err)
+		}
+
+		if val.PublicKey != signingPubkey {
+			t.Fatalf("validator %d: expected pubkey %s, got %s", i, signingPubkey.String(), val.PublicKey.String())
+		}
+
+		withdrawalPubkey, err := derivePubkey(seed, fmt.Sprintf("m/12381/3600/1/%d", idx))
+		if err != nil {
+			t.Fatalf("failed to derive pubkey: %v", err)
+		}
+
+		wantCreds := sha256.Sum256(withdrawalPubkey[:])
+		wantCreds[0] = 0x00
+
+		if !bytes.Equal(val.WithdrawalCredentials, wantCreds[:]) {
+			t.Fatalf("validator %d: expected withdrawal credentials 0x%x, got 0x%x", i, wantCreds, val.WithdrawalCredentials)
+		}
+	}
+}
+
+func TestGenerateValidatorsByMnemonic_InvalidPathTemplates(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields string
+		want   string
+	}{
+		{name: "missing placeholder", fields: `signing_path: "m/12381/3600/0/0/0"`, want: "must contain {index} exactly once"},
+		{name: "duplicate placeholder", fields: `withdrawal_path: "m/12381/{index}/{index}"`, want: "must contain {index} exactly once"},
+		{name: "printf placeholder", fields: `signing_path: "m/12381/3600/%d/0/0"`, want: "invalid component \"%d\""},
+		{name: "no master", fields: `signing_path: "12381/3600/{index}/0/0"`, want: "must start with \"m\""},
+		{name: "invalid fixed withdrawal path", fields: `wd_key_path: "m/12381/x"`, want: "invalid wd_key_path"},
+		{name: "both passphrases", fields: "passphrase: \"a\"\n  passphrase_file: \"b\"", want: "mutually exclusive"},
+		{name: "missing passphrase file", fields: `passphrase_file: "does-not-exist"`, want: "failed to read passphrase file"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mnemonicsFile := createTestMnemonicsFile(t, `
+- mnemonic: "`+testMnemonic+`"
+  count: 1
+  `+test.fields+`
+`)
+
+			_, err := GenerateValidatorsByMnemonic(mnemonicsFile)
+			if err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+
+			if !strings.Contains(err.Error(), test.want) || !strings.Contains(err.Error(), "mnemonic 0") {
+				t.Fatalf("expected error to contain %q, got %s", test.want, err)
+			}
+		})
+	}
+}
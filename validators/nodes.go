@@ -0,0 +1,246 @@
+package validators
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NodeSpec is a single entry of a node plan. A node either gets a fixed number
+// of validators (Count) or a share of the validators left after all fixed
+// counts have been assigned, proportional to its Weight. Nodes without count
+// and weight get a weight of 1.
+type NodeSpec struct {
+	Name   string `yaml:"name"`
+	Count  uint64 `yaml:"count"`
+	Weight uint64 `yaml:"weight"`
+}
+
+// NodeAssignment describes the validators assigned to a node. A node always
+// gets a contiguous state index range of the final (possibly shuffled)
+// validator set. Ranges lists where the keys of that state range originate.
+// The state index range is unset for nodes without validators.
+type NodeAssignment struct {
+	Name           string            `yaml:"name"`
+	StateIndexFrom *uint64           `yaml:"state_index_from,omitempty"`
+	StateIndexTo   *uint64           `yaml:"state_index_to,omitempty"`
+	Validators     uint64            `yaml:"validators"`
+	Ranges         []NodeSourceRange `yaml:"ranges"`
+	Pubkeys        []string          `yaml:"pubkeys"`
+}
+
+// NodeSourceRange is a contiguous key index range of a single source assigned
+// to a node.
+type NodeSourceRange struct {
+	Source       string `yaml:"src"`
+	KeyIndexFrom uint64 `yaml:"from"`
+	KeyIndexTo   uint64 `yaml:"to"`
+}
+
+// LoadNodePlan loads a node plan from a YAML file in the form:
+//
+//   - name: "lighthouse-geth-1"
+//     count: 64
+//   - name: "teku-besu-1"
+//     weight: 2
+func LoadNodePlan(path string) ([]*NodeSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read node plan: %w", err)
+	}
+
+	plan := []*NodeSpec{}
+	if err := yaml.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to decode node plan: %w", err)
+	}
+
+	return plan, nil
+}
+
+// AssignNodes splits the final, ordered validator list into contiguous state
+// index ranges, one per node in plan order. Nodes with a fixed count get
+// exactly that many validators, the remaining validators are split across the
+// weighted nodes using the largest remainder method (ties go to the earlier
+// node), so the result only depends on the plan and the validator list.
+//
+// The assignment is based on state indices, so it stays valid when the
+// validator set has been shuffled: the source ranges of each node are derived
+// from the shuffled order the same way BuildMapping does.
+func AssignNodes(vals []*Validator, plan []*NodeSpec) ([]*NodeAssignment, error) {
+	if len(plan) == 0 {
+		return nil, fmt.Errorf("node plan is empty")
+	}
+
+	total := uint64(len(vals))
+	fixed := uint64(0)
+	totalWeight := uint64(0)
+	names := make(map[string]bool, len(plan))
+
+	for i, node := range plan {
+		if node.Name == "" {
+			return nil, fmt.Errorf("node %d has no name", i)
+		}
+
+		if names[node.Name] {
+			return nil, fmt.Errorf("duplicate node name %q", node.Name)
+		}
+
+		names[node.Name] = true
+
+		switch {
+		case node.Count > 0 && node.Weight > 0:
+			return nil, fmt.Errorf("node %q: count and weight are mutually exclusive", node.Name)
+		case node.Count > 0:
+			fixed += node.Count
+		case node.Weight > 0:
+			totalWeight += node.Weight
+		default:
+			totalWeight++
+		}
+	}
+
+	if fixed > total {
+		return nil, fmt.Errorf("node plan assigns %d validators, but the validator set only has %d", fixed, total)
+	}
+
+	if totalWeight == 0 && fixed != total {
+		return nil, fmt.Errorf("node plan assigns %d validators, but the validator set has %d (add a weighted node to take the rest)", fixed, total)
+	}
+
+	counts := weightedNodeCounts(plan, total-fixed, totalWeight)
+	assignments := make([]*NodeAssignment, 0, len(plan))
+	offset := uint64(0)
+
+	for i, node := range plan {
+		count := counts[i]
+
+		assignment := &NodeAssignment{
+			Name:       node.Name,
+			Validators: count,
+			Ranges:     []NodeSourceRange{},
+			Pubkeys:    make([]string, 0, count),
+		}
+
+		if count > 0 {
+			from, to := offset, offset+count-1
+			assignment.StateIndexFrom = &from
+			assignment.StateIndexTo = &to
+		}
+
+		for _, entry := range BuildMapping(vals[offset : offset+count]) {
+			assignment.Ranges = append(assignment.Ranges, NodeSourceRange{
+				Source:       entry.Source,
+				KeyIndexFrom: entry.KeyIndexFrom,
+				KeyIndexTo:   entry.KeyIndexTo,
+			})
+		}
+
+		for _, val := range vals[offset : offset+count] {
+			assignment.Pubkeys = append(assignment.Pubkeys, val.PublicKey.String())
+		}
+
+		assignments = append(assignments, assignment)
+		offset += count
+	}
+
+	return assignments, nil
+}
+
+// weightedNodeCounts returns the number of validators for each node in plan,
+// distributing remaining across the weighted nodes.
+func weightedNodeCounts(plan []*NodeSpec, remaining, totalWeight uint64) []uint64 {
+	counts := make([]uint64, len(plan))
+	weighted := make([]int, 0, len(plan))
+	remainders := make([]uint64, len(plan))
+	assigned := uint64(0)
+
+	for i, node := range plan {
+		if node.Count > 0 {
+			counts[i] = node.Count
+			continue
+		}
+
+		weight := node.Weight
+		if weight == 0 {
+			weight = 1
+		}
+
+		counts[i] = remaining * weight / totalWeight
+		remainders[i] = remaining * weight % totalWeight
+		assigned += counts[i]
+		weighted = append(weighted, i)
+	}
+
+	sort.SliceStable(weighted, func(a, b int) bool {
+		return remainders[weighted[a]] > remainders[weighted[b]]
+	})
+
+	for i := uint64(0); i < remaining-assigned; i++ {
+		counts[weighted[i]]++
+	}
+
+	return counts
+}
+
+// KeySelector returns a KeySelector that selects the keys assigned to the node.
+func (a *NodeAssignment) KeySelector() KeySelector {
+	return func(source string, keyIndex uint64) bool {
+		for _, r := range a.Ranges {
+			if r.Source == source && keyIndex >= r.KeyIndexFrom && keyIndex <= r.KeyIndexTo {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// WriteAssignmentFile writes the node assignments to path in YAML format.
+func WriteAssignmentFile(path string, assignments []*NodeAssignment) error {
+	data, err := yaml.Marshal(assignments)
+	if err != nil {
+		return fmt.Errorf("failed to encode node assignment: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil { //nolint:gosec // no strict permissions needed
+		return fmt.Errorf("failed to write node assignment file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadAssignmentFile loads node assignments written by WriteAssignmentFile.
+func LoadAssignmentFile(path string) ([]*NodeAssignment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read node assignment file: %w", err)
+	}
+
+	assignments := []*NodeAssignment{}
+	if err := yaml.Unmarshal(data, &assignments); err != nil {
+		return nil, fmt.Errorf("failed to decode node assignment file: %w", err)
+	}
+
+	for _, assignment := range assignments {
+		for _, pubkey := range assignment.Pubkeys {
+			if _, err := ParsePubkey(pubkey); err != nil {
+				return nil, fmt.Errorf("node %q: invalid pubkey %q: %w", assignment.Name, pubkey, err)
+			}
+		}
+	}
+
+	return assignments, nil
+}
+
+// FindNodeAssignment returns the assignment of the named node.
+func FindNodeAssignment(assignments []*NodeAssignment, name string) (*NodeAssignment, error) {
+	for _, assignment := range assignments {
+		if assignment.Name == name {
+			return assignment, nil
+		}
+	}
+
+	return nil, fmt.Errorf("node %q not found in node assignment", name)
+}
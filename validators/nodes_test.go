@@ -0,0 +1,186 @@
+package validators
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAssignNodes_CountsAndWeights(t *testing.T) {
+	vals := append(makeValidators("mnemonic-0", 60), makeValidators("mnemonic-1", 40)...)
+
+	assignments, err := AssignNodes(vals, []*NodeSpec{
+		{Name: "node-a", Count: 10},
+		{Name: "node-b", Weight: 2},
+		{Name: "node-c", Weight: 1},
+		{Name: "node-d"},
+	})
+	if err != nil {
+		t.Fatalf("failed to assign nodes: %v", err)
+	}
+
+	// 90 remaining validators split 2:1:1 -> 45, 22.5, 22.5; the tie on the
+	// remainder goes to the earlier node
+	wantCounts := []uint64{10, 45, 23, 22}
+
+	var next uint64
+
+	for i, assignment := range assignments {
+		if assignment.Validators != wantCounts[i] || uint64(len(assignment.Pubkeys)) != wantCounts[i] {
+			t.Fatalf("node %s: expected %d validators, got %d", assignment.Name, wantCounts[i], assignment.Validators)
+		}
+
+		if *assignment.StateIndexFrom != next || *assignment.StateIndexTo != next+wantCounts[i]-1 {
+			t.Fatalf("node %s: unexpected state range %d-%d", assignment.Name, *assignment.StateIndexFrom, *assignment.StateIndexTo)
+		}
+
+		next += wantCounts[i]
+	}
+
+	// node-b covers state indices 10-54, spanning both sources
+	want := []NodeSourceRange{
+		{Source: "mnemonic-0", KeyIndexFrom: 10, KeyIndexTo: 54},
+	}
+	if len(assignments[1].Ranges) != 1 || assignments[1].Ranges[0] != want[0] {
+		t.Fatalf("unexpected ranges for node-b: %+v", assignments[1].Ranges)
+	}
+
+	want = []NodeSourceRange{
+		{Source: "mnemonic-0", KeyIndexFrom: 55, KeyIndexTo: 59},
+		{Source: "mnemonic-1", KeyIndexFrom: 0, KeyIndexTo: 17},
+	}
+	if len(assignments[2].Ranges) != 2 || assignments[2].Ranges[0] != want[0] || assignments[2].Ranges[1] != want[1] {
+		t.Fatalf("unexpected ranges for node-c: %+v", assignments[2].Ranges)
+	}
+}
+
+func TestAssignNodes_Shuffled(t *testing.T) {
+	vals := makeValidators("mnemonic-0", 500)
+	ShuffleValidators(vals, 1234)
+
+	assignments, err := AssignNodes(vals, []*NodeSpec{{Name: "node-a"}, {Name: "node-b"}, {Name: "node-c"}})
+	if err != nil {
+		t.Fatalf("failed to assign nodes: %v", err)
+	}
+
+	for _, assignment := range assignments {
+		selector := assignment.KeySelector()
+		from, to := *assignment.StateIndexFrom, *assignment.StateIndexTo
+
+		for i := from; i <= to; i++ {
+			if assignment.Pubkeys[i-from] != vals[i].PublicKey.String() {
+				t.Fatalf("node %s: pubkey at state index %d does not match", assignment.Name, i)
+			}
+
+			if !selector(vals[i].Source, vals[i].SourceKeyIndex) {
+				t.Fatalf("node %s: key %d at state index %d not selected", assignment.Name, vals[i].SourceKeyIndex, i)
+			}
+		}
+
+		// keys of the other nodes must not be selected
+		for i, val := range vals {
+			inRange := uint64(i) >= from && uint64(i) <= to
+			if !inRange && selector(val.Source, val.SourceKeyIndex) {
+				t.Fatalf("node %s: selects key %d of state index %d", assignment.Name, val.SourceKeyIndex, i)
+			}
+		}
+	}
+}
+
+func TestAssignNodes_EmptyNode(t *testing.T) {
+	vals := makeValidators("mnemonic-0", 10)
+
+	assignments, err := AssignNodes(vals, []*NodeSpec{{Name: "node-a", Count: 10}, {Name: "node-b"}})
+	if err != nil {
+		t.Fatalf("failed to assign nodes: %v", err)
+	}
+
+	node := assignments[1]
+	if node.Validators != 0 || node.StateIndexFrom != nil || node.StateIndexTo != nil {
+		t.Fatalf("expected node-b without validators and state range, got %+v", node)
+	}
+
+	path := filepath.Join(t.TempDir(), "assignment.yaml")
+	if err := WriteAssignmentFile(path, assignments); err != nil {
+		t.Fatalf("failed to write assignment file: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read assignment file: %v", err)
+	}
+
+	if strings.Count(string(data), "state_index_from") != 1 {
+		t.Fatalf("expected a state range for node-a only, got:\n%s", data)
+	}
+}
+
+func TestAssignNodes_InvalidPlans(t *testing.T) {
+	vals := makeValidators("mnemonic-0", 10)
+
+	tests := []struct {
+		plan    []*NodeSpec
+		wantErr string
+	}{
+		{nil, "node plan is empty"},
+		{[]*NodeSpec{{Count: 10}}, "has no name"},
+		{[]*NodeSpec{{Name: "a", Count: 5}, {Name: "a"}}, "duplicate node name"},
+		{[]*NodeSpec{{Name: "a", Count: 5, Weight: 1}}, "mutually exclusive"},
+		{[]*NodeSpec{{Name: "a", Count: 11}}, "only has 10"},
+		{[]*NodeSpec{{Name: "a", Count: 5}}, "add a weighted node"},
+	}
+
+	for _, test := range tests {
+		_, err := AssignNodes(vals, test.plan)
+		if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Fatalf("expected error containing %q, got %v", test.wantErr, err)
+		}
+	}
+}
+
+func TestAssignmentFile_RoundTrip(t *testing.T) {
+	vals := makeValidators("mnemonic-0", 30)
+
+	planFile := filepath.Join(t.TempDir(), "nodes.yaml")
+	if err := os.WriteFile(planFile, []byte("- name: node-a\n  count: 10\n- name: node-b\n  weight: 1\n"), 0o600); err != nil {
+		t.Fatalf("failed to write node plan: %v", err)
+	}
+
+	plan, err := LoadNodePlan(planFile)
+	if err != nil {
+		t.Fatalf("failed to load node plan: %v", err)
+	}
+
+	assignments, err := AssignNodes(vals, plan)
+	if err != nil {
+		t.Fatalf("failed to assign nodes: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "assignment.yaml")
+	if err := WriteAssignmentFile(path, assignments); err != nil {
+		t.Fatalf("failed to write assignment file: %v", err)
+	}
+
+	loaded, err := LoadAssignmentFile(path)
+	if err != nil {
+		t.Fatalf("failed to load assignment file: %v", err)
+	}
+
+	node, err := FindNodeAssignment(loaded, "node-b")
+	if err != nil {
+		t.Fatalf("failed to find node: %v", err)
+	}
+
+	if *node.StateIndexFrom != 10 || *node.StateIndexTo != 29 || len(node.Pubkeys) != 20 || node.Pubkeys[0] != vals[10].PublicKey.String() {
+		t.Fatalf("unexpected node assignment: %+v", node)
+	}
+
+	if len(node.Ranges) != 1 || node.Ranges[0] != (NodeSourceRange{Source: "mnemonic-0", KeyIndexFrom: 10, KeyIndexTo: 29}) {
+		t.Fatalf("unexpected node ranges: %+v", node.Ranges)
+	}
+
+	if _, err := FindNodeAssignment(loaded, "node-x"); err == nil {
+		t.Fatalf("expected error for unknown node")
+	}
+}
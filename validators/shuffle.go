@@ -2,7 +2,9 @@ package validators
 
 import (
 	"encoding/binary"
+	"fmt"
 	"math/rand/v2"
+	"strings"
 )
 
 const (
@@ -60,19 +62,90 @@ func blockSizeForCount(count int) int {
 	}
 }
 
+// Shuffle modes selectable with ShuffleValidatorsByMode.
+const (
+	// ShuffleModeBlock reorders contiguous blocks of validators (see
+	// ShuffleValidators).
+	ShuffleModeBlock = "block"
+
+	// ShuffleModeFull shuffles every validator individually.
+	ShuffleModeFull = "full"
+
+	// ShuffleModeRoundRobin interleaves the validators of all sources one by
+	// one, so every source is spread evenly across the validator indices.
+	ShuffleModeRoundRobin = "round-robin"
+)
+
+// shuffleFunc reorders vals in place using rng. blockSize is the explicit
+// block size requested by the user (0 if not set).
+type shuffleFunc func(vals []*Validator, rng *rand.Rand, blockSize int)
+
+// shuffleModes maps the supported shuffle modes to their implementation.
+var shuffleModes = map[string]shuffleFunc{
+	ShuffleModeBlock:      shuffleBlocks,
+	ShuffleModeFull:       shuffleFull,
+	ShuffleModeRoundRobin: shuffleRoundRobin,
+}
+
+// ShuffleModes returns the names of all supported shuffle modes.
+func ShuffleModes() []string {
+	return []string{ShuffleModeBlock, ShuffleModeFull, ShuffleModeRoundRobin}
+}
+
 // ShuffleValidators reorders the validator list block-wise in place. The list
 // is split into contiguous blocks (see blockSizeForCount) and the order of the
 // blocks is shuffled using a PRNG seeded with seed, so the same inputs and seed
 // always produce the same ordering. Validators within a block keep their
 // relative order, which keeps the resulting mapping compact.
 func ShuffleValidators(vals []*Validator, seed uint64) {
+	shuffleBlocks(vals, newShuffleRNG(seed), 0)
+}
+
+// ShuffleValidatorsByMode reorders the validator list in place using the given
+// shuffle mode. The result only depends on the validator list, mode, seed and
+// blockSize. blockSize sets an explicit block size for ShuffleModeBlock (0
+// scales the block size with the validator count) and must be 0 for other modes.
+func ShuffleValidatorsByMode(vals []*Validator, mode string, seed uint64, blockSize int) error {
+	shuffle, found := shuffleModes[mode]
+	if !found {
+		return fmt.Errorf("unknown shuffle mode %q (supported: %s)", mode, strings.Join(ShuffleModes(), ", "))
+	}
+
+	if blockSize < 0 {
+		return fmt.Errorf("invalid shuffle block size %d", blockSize)
+	}
+
+	if blockSize != 0 && mode != ShuffleModeBlock {
+		return fmt.Errorf("shuffle block size is only supported by the %s shuffle mode", ShuffleModeBlock)
+	}
+
+	shuffle(vals, newShuffleRNG(seed), blockSize)
+
+	return nil
+}
+
+func newShuffleRNG(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed)) //nolint:gosec // deterministic shuffle, not security-sensitive
+}
+
+// shuffleBlocks splits vals into contiguous blocks of blockSize validators
+// (scaled by blockSizeForCount if 0) and shuffles the order of the blocks.
+func shuffleBlocks(vals []*Validator, rng *rand.Rand, blockSize int) {
 	n := len(vals)
-	if n <= minBlockSize {
-		// Nothing meaningful to shuffle: a single block (or less).
+
+	if blockSize == 0 {
+		if n <= minBlockSize {
+			// Nothing meaningful to shuffle: a single block (or less).
+			return
+		}
+
+		blockSize = blockSizeForCount(n)
+	}
+
+	if n <= blockSize {
 		return
 	}
 
-	blockSize := blockSizeForCount(n)
 	blockCount := (n + blockSize - 1) / blockSize
 
 	order := make([]int, blockCount)
@@ -80,7 +153,6 @@ func ShuffleValidators(vals []*Validator, seed uint64) {
 		order[i] = i
 	}
 
-	rng := rand.New(rand.NewPCG(seed, seed)) //nolint:gosec // deterministic shuffle, not security-sensitive
 	rng.Shuffle(blockCount, func(i, j int) {
 		order[i], order[j] = order[j], order[i]
 	})
@@ -95,3 +167,44 @@ func ShuffleValidators(vals []*Validator, seed uint64) {
 
 	copy(vals, shuffled)
 }
+
+// shuffleFull shuffles every validator individually.
+func shuffleFull(vals []*Validator, rng *rand.Rand, _ int) {
+	rng.Shuffle(len(vals), func(i, j int) {
+		vals[i], vals[j] = vals[j], vals[i]
+	})
+}
+
+// shuffleRoundRobin interleaves the sources: it takes the next validator of
+// each source in turn until all sources are exhausted. The order of the
+// sources within a round is shuffled once, validators of a source keep their
+// relative order.
+func shuffleRoundRobin(vals []*Validator, rng *rand.Rand, _ int) {
+	sources := make([]string, 0)
+	queues := make(map[string][]*Validator)
+
+	for _, val := range vals {
+		if _, found := queues[val.Source]; !found {
+			sources = append(sources, val.Source)
+		}
+
+		queues[val.Source] = append(queues[val.Source], val)
+	}
+
+	rng.Shuffle(len(sources), func(i, j int) {
+		sources[i], sources[j] = sources[j], sources[i]
+	})
+
+	shuffled := make([]*Validator, 0, len(vals))
+
+	for len(shuffled) < len(vals) {
+		for _, source := range sources {
+			if queue := queues[source]; len(queue) > 0 {
+				shuffled = append(shuffled, queue[0])
+				queues[source] = queue[1:]
+			}
+		}
+	}
+
+	copy(vals, shuffled)
+}
@@ -180,3 +180,149 @@ func TestShuffleValidators_SmallSetNoOp(t *testing.T) {
 		idx++
 	}
 }
+
+// checkMapping asserts that BuildMapping describes vals exactly.
+func checkMapping(t *testing.T, vals []*Validator) {
+	t.Helper()
+
+	var covered uint64
+
+	for i, e := range BuildMapping(vals) {
+		if e.StateIndexFrom != covered || e.StateIndexTo-e.StateIndexFrom != e.KeyIndexTo-e.KeyIndexFrom {
+			t.Fatalf("entry %d has inconsistent ranges: %+v", i, e)
+		}
+
+		for s := e.StateIndexFrom; s <= e.StateIndexTo; s++ {
+			if vals[s].Source != e.Source || vals[s].SourceKeyIndex != e.KeyIndexFrom+(s-e.StateIndexFrom) {
+				t.Fatalf("entry %d does not describe validator at state index %d", i, s)
+			}
+		}
+
+		covered = e.StateIndexTo + 1
+	}
+
+	if covered != uint64(len(vals)) {
+		t.Fatalf("mapping covers %d validators, expected %d", covered, len(vals))
+	}
+}
+
+func TestShuffleValidatorsByMode_BlockMatchesShuffleValidators(t *testing.T) {
+	a := makeValidators("mnemonic-0", 1000)
+	b := makeValidators("mnemonic-0", 1000)
+
+	ShuffleValidators(a, 42)
+
+	if err := ShuffleValidatorsByMode(b, ShuffleModeBlock, 42, 0); err != nil {
+		t.Fatalf("shuffle failed: %v", err)
+	}
+
+	for i := range a {
+		if a[i].SourceKeyIndex != b[i].SourceKeyIndex {
+			t.Fatalf("orders differ at index %d", i)
+		}
+	}
+}
+
+func TestShuffleValidatorsByMode_ExplicitBlockSize(t *testing.T) {
+	vals := makeValidators("mnemonic-0", 100)
+
+	if err := ShuffleValidatorsByMode(vals, ShuffleModeBlock, 7, 8); err != nil {
+		t.Fatalf("shuffle failed: %v", err)
+	}
+
+	entries := BuildMapping(vals)
+	if len(entries) < 2 {
+		t.Fatalf("expected multiple mapping entries, got %d", len(entries))
+	}
+
+	for i, e := range entries {
+		// every block starts at a multiple of the block size
+		if e.KeyIndexFrom%8 != 0 {
+			t.Fatalf("entry %d starts at key index %d, not at a block boundary", i, e.KeyIndexFrom)
+		}
+	}
+
+	checkMapping(t, vals)
+}
+
+func TestShuffleValidatorsByMode_Full(t *testing.T) {
+	a := makeValidators("mnemonic-0", 200)
+	b := makeValidators("mnemonic-0", 200)
+
+	for _, vals := range [][]*Validator{a, b} {
+		if err := ShuffleValidatorsByMode(vals, ShuffleModeFull, 99, 0); err != nil {
+			t.Fatalf("shuffle failed: %v", err)
+		}
+	}
+
+	seen := make(map[uint64]bool)
+
+	for i := range a {
+		if a[i].SourceKeyIndex != b[i].SourceKeyIndex {
+			t.Fatalf("full shuffle is not deterministic at index %d", i)
+		}
+
+		seen[a[i].SourceKeyIndex] = true
+	}
+
+	if len(seen) != 200 {
+		t.Fatalf("expected 200 distinct validators, got %d", len(seen))
+	}
+
+	if len(BuildMapping(a)) < 100 {
+		t.Fatalf("expected a fully shuffled set to produce many mapping entries")
+	}
+
+	checkMapping(t, a)
+}
+
+func TestShuffleValidatorsByMode_RoundRobin(t *testing.T) {
+	vals := append(makeValidators("mnemonic-0", 6), makeValidators("mnemonic-1", 3)...)
+	vals = append(vals, makeValidators("additional-validators", 3)...)
+
+	if err := ShuffleValidatorsByMode(vals, ShuffleModeRoundRobin, 5, 0); err != nil {
+		t.Fatalf("shuffle failed: %v", err)
+	}
+
+	// the first three rounds contain every source once, the remaining
+	// validators of mnemonic-0 follow
+	for round := range 3 {
+		sources := make(map[string]bool)
+
+		for _, val := range vals[round*3 : round*3+3] {
+			sources[val.Source] = true
+
+			if val.SourceKeyIndex != uint64(round) {
+				t.Fatalf("round %d contains key index %d of %s", round, val.SourceKeyIndex, val.Source)
+			}
+		}
+
+		if len(sources) != 3 {
+			t.Fatalf("round %d does not contain all sources", round)
+		}
+	}
+
+	for i, val := range vals[9:] {
+		if val.Source != "mnemonic-0" || val.SourceKeyIndex != uint64(3+i) {
+			t.Fatalf("unexpected validator %s/%d at index %d", val.Source, val.SourceKeyIndex, 9+i)
+		}
+	}
+
+	checkMapping(t, vals)
+}
+
+func TestShuffleValidatorsByMode_InvalidOptions(t *testing.T) {
+	vals := makeValidators("mnemonic-0", 10)
+
+	if err := ShuffleValidatorsByMode(vals, "random", 1, 0); err == nil {
+		t.Fatalf("expected error for unknown shuffle mode")
+	}
+
+	if err := ShuffleValidatorsByMode(vals, ShuffleModeFull, 1, 10); err == nil {
+		t.Fatalf("expected error for block size with full shuffle mode")
+	}
+
+	if err := ShuffleValidatorsByMode(vals, ShuffleModeBlock, 1, -1); err == nil {
+		t.Fatalf("expected error for negative block size")
+	}
+}
@@ -0,0 +1,90 @@
+package validators
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+	"golang.org/x/sync/errgroup"
+
+	e2util "github.com/wealdtech/go-eth2-util"
+)
+
+// SigningKey is a validator signing key derived from a mnemonic source. It
+// uses the same source names, key indices and derivation paths as
+// GenerateValidatorsByMnemonic, so keys always match the genesis validators.
+type SigningKey struct {
+	Source    string
+	KeyIndex  uint64
+	Path      string
+	PublicKey phase0.BLSPubKey
+	SecretKey []byte
+}
+
+// KeySelector decides whether the key with keyIndex of the named source is
+// selected.
+type KeySelector func(source string, keyIndex uint64) bool
+
+// DeriveSigningKeys derives the signing keys of all validators defined in the
+// mnemonics file that are accepted by selector (all keys if selector is nil).
+// Keys are returned in the same order GenerateValidatorsByMnemonic produces
+// the validators.
+func DeriveSigningKeys(mnemonicsConfigPath string, selector KeySelector) ([]*SigningKey, error) {
+	mnemonics, err := LoadMnemonics(mnemonicsConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*SigningKey, 0)
+
+	for m, mnemonicSrc := range mnemonics {
+		source := mnemonicSrc.SourceName(m)
+		first := len(keys)
+
+		for i := uint64(0); i < mnemonicSrc.Count; i++ {
+			idx := mnemonicSrc.Start + i
+			if selector != nil && !selector(source, idx) {
+				continue
+			}
+
+			keys = append(keys, &SigningKey{
+				Source:   source,
+				KeyIndex: idx,
+				Path:     keyPath(mnemonicSrc.SigningPath, idx),
+			})
+		}
+
+		if len(keys) == first {
+			continue
+		}
+
+		seed, err := seedFromMnemonic(mnemonicSrc.Mnemonic, mnemonicSrc.Passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("mnemonic %d is bad", m)
+		}
+
+		var g errgroup.Group
+
+		g.SetLimit(runtime.NumCPU())
+
+		for _, key := range keys[first:] {
+			g.Go(func() error {
+				sk, err := e2util.PrivateKeyFromSeedAndPath(seed, key.Path)
+				if err != nil {
+					return err
+				}
+
+				key.SecretKey = sk.Marshal()
+				key.PublicKey = phase0.BLSPubKey(sk.PublicKey().Marshal())
+
+				return nil
+			})
+		}
+
+		if err := g.Wait(); err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
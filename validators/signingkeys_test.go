@@ -0,0 +1,61 @@
+package validators
+
+import (
+	"testing"
+)
+
+func TestDeriveSigningKeys_MatchesGeneratedValidators(t *testing.T) {
+	initTestBLS(t)
+
+	mnemonicsFile := createTestMnemonicsFile(t, `
+- mnemonic: "`+testMnemonic+`"
+  start: 0
+  count: 4
+- mnemonic: "`+testMnemonic+`"
+  name: "operator-b"
+  start: 100
+  count: 4
+  signing_path: "m/12381/3600/0/{index}/0"
+`)
+
+	vals, err := GenerateValidatorsByMnemonic(mnemonicsFile)
+	if err != nil {
+		t.Fatalf("failed to generate validators: %v", err)
+	}
+
+	keys, err := DeriveSigningKeys(mnemonicsFile, nil)
+	if err != nil {
+		t.Fatalf("failed to derive signing keys: %v", err)
+	}
+
+	if len(keys) != len(vals) {
+		t.Fatalf("expected %d keys, got %d", len(vals), len(keys))
+	}
+
+	for i, key := range keys {
+		if key.PublicKey != vals[i].PublicKey || key.Source != vals[i].Source || key.KeyIndex != vals[i].SourceKeyIndex {
+			t.Fatalf("key %d (%s/%d %s) does not match validator (%s/%d %s)", i,
+				key.Source, key.KeyIndex, key.PublicKey.String(),
+				vals[i].Source, vals[i].SourceKeyIndex, vals[i].PublicKey.String())
+		}
+
+		if len(key.SecretKey) != 32 {
+			t.Fatalf("key %d: expected 32 byte secret key, got %d", i, len(key.SecretKey))
+		}
+	}
+
+	selected, err := DeriveSigningKeys(mnemonicsFile, func(source string, keyIndex uint64) bool {
+		return source == "operator-b" && keyIndex >= 102
+	})
+	if err != nil {
+		t.Fatalf("failed to derive signing keys: %v", err)
+	}
+
+	if len(selected) != 2 || selected[0].KeyIndex != 102 || selected[0].PublicKey != vals[6].PublicKey {
+		t.Fatalf("unexpected selection: %d keys", len(selected))
+	}
+
+	if selected[0].Path != "m/12381/3600/0/102/0" {
+		t.Fatalf("unexpected path %s", selected[0].Path)
+	}
+}
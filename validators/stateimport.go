@@ -0,0 +1,105 @@
+package validators
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+)
+
+// StateImportFilter selects the validators to import from the validator
+// registry of a beacon state.
+type StateImportFilter struct {
+	// ActiveOnly skips validators that are not active at the state epoch.
+	ActiveOnly bool
+
+	// IndexFrom and IndexTo are the inclusive range of registry indices to
+	// import, IndexTo nil imports up to the end of the registry.
+	IndexFrom uint64
+	IndexTo   *uint64
+
+	// CredentialTypes are the withdrawal credential prefixes to import (e.g.
+	// 0x01), all types are imported if empty.
+	CredentialTypes []byte
+}
+
+// ImportStateValidators converts the validator registry and balances of a
+// beacon state at epoch into genesis validators. The validators keep their
+// pubkey, withdrawal credentials and balance; slashed validators and
+// validators exited at epoch keep their status, all others become active. The
+// source of the validators is source and the key index is their index in the
+// registry, so the mapping records the imported ranges.
+func ImportStateValidators(stateVals []*phase0.Validator, balances []phase0.Gwei, epoch phase0.Epoch, source string, filter *StateImportFilter) ([]*Validator, error) {
+	if len(balances) != len(stateVals) {
+		return nil, fmt.Errorf("state has %d balances for %d validators", len(balances), len(stateVals))
+	}
+
+	if filter == nil {
+		filter = &StateImportFilter{}
+	}
+
+	vals := make([]*Validator, 0, len(stateVals))
+
+	for index, stateVal := range stateVals {
+		keyIndex := uint64(index) //nolint:gosec // index is a slice index, always >= 0
+		if keyIndex < filter.IndexFrom || (filter.IndexTo != nil && keyIndex > *filter.IndexTo) {
+			continue
+		}
+
+		if filter.ActiveOnly && (stateVal.ActivationEpoch > epoch || stateVal.ExitEpoch <= epoch) {
+			continue
+		}
+
+		if len(filter.CredentialTypes) > 0 && !hasCredentialType(stateVal.WithdrawalCredentials, filter.CredentialTypes) {
+			continue
+		}
+
+		status := ValidatorStatusActive
+
+		switch {
+		case stateVal.Slashed:
+			status = ValidatorStatusSlashed
+		case stateVal.ExitEpoch <= epoch:
+			status = ValidatorStatusExited
+		}
+
+		balance := uint64(balances[index])
+
+		vals = append(vals, &Validator{
+			PublicKey:             stateVal.PublicKey,
+			WithdrawalCredentials: append([]byte{}, stateVal.WithdrawalCredentials...),
+			Balance:               &balance,
+			Status:                status,
+			Source:                source,
+			SourceKeyIndex:        keyIndex,
+		})
+	}
+
+	return vals, nil
+}
+
+// ParseCredentialType parses a withdrawal credential prefix given as hex byte
+// with or without 0x prefix (e.g. 0x01 or 02).
+func ParseCredentialType(value string) (byte, error) {
+	credType, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimSpace(value), "0x"), 16, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid withdrawal credential type %q", value)
+	}
+
+	return byte(credType), nil
+}
+
+func hasCredentialType(withdrawalCredentials []byte, credTypes []byte) bool {
+	if len(withdrawalCredentials) == 0 {
+		return false
+	}
+
+	for _, credType := range credTypes {
+		if withdrawalCredentials[0] == credType {
+			return true
+		}
+	}
+
+	return false
+}
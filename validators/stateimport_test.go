@@ -0,0 +1,126 @@
+package validators
+
+import (
+	"testing"
+
+	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+)
+
+func createTestStateValidators() ([]*phase0.Validator, []phase0.Gwei) {
+	farFuture := phase0.Epoch(18446744073709551615)
+
+	stateVals := []*phase0.Validator{
+		// active
+		{PublicKey: phase0.BLSPubKey{0x01}, WithdrawalCredentials: append([]byte{0x01}, make([]byte, 31)...), ActivationEpoch: 0, ExitEpoch: farFuture},
+		// slashed and exited
+		{PublicKey: phase0.BLSPubKey{0x02}, WithdrawalCredentials: append([]byte{0x00}, make([]byte, 31)...), Slashed: true, ActivationEpoch: 0, ExitEpoch: 5},
+		// exited
+		{PublicKey: phase0.BLSPubKey{0x03}, WithdrawalCredentials: append([]byte{0x01}, make([]byte, 31)...), ActivationEpoch: 0, ExitEpoch: 8},
+		// pending activation
+		{PublicKey: phase0.BLSPubKey{0x04}, WithdrawalCredentials: append([]byte{0x02}, make([]byte, 31)...), ActivationEpoch: farFuture, ExitEpoch: farFuture},
+		// active, exiting after the state epoch
+		{PublicKey: phase0.BLSPubKey{0x05}, WithdrawalCredentials: append([]byte{0x02}, make([]byte, 31)...), ActivationEpoch: 2, ExitEpoch: 20},
+	}
+
+	balances := []phase0.Gwei{32_000_000_000, 31_000_000_000, 0, 32_000_000_000, 64_000_000_000}
+
+	return stateVals, balances
+}
+
+func TestImportStateValidators(t *testing.T) {
+	stateVals, balances := createTestStateValidators()
+
+	vals, err := ImportStateValidators(stateVals, balances, 10, "state.ssz", nil)
+	if err != nil {
+		t.Fatalf("failed to import validators: %v", err)
+	}
+
+	if len(vals) != len(stateVals) {
+		t.Fatalf("expected %d validators, got %d", len(stateVals), len(vals))
+	}
+
+	expectedStatus := []ValidatorStatus{
+		ValidatorStatusActive, ValidatorStatusSlashed, ValidatorStatusExited, ValidatorStatusActive, ValidatorStatusActive,
+	}
+
+	for i, val := range vals {
+		if val.PublicKey != stateVals[i].PublicKey || val.WithdrawalCredentials[0] != stateVals[i].WithdrawalCredentials[0] {
+			t.Fatalf("validator %d: expected the pubkey and withdrawal credentials of the state", i)
+		}
+
+		if val.Balance == nil || *val.Balance != uint64(balances[i]) {
+			t.Fatalf("validator %d: expected balance %d, got %v", i, balances[i], val.Balance)
+		}
+
+		if val.Status != expectedStatus[i] {
+			t.Fatalf("validator %d: expected status %s, got %s", i, expectedStatus[i], val.Status)
+		}
+
+		if val.Source != "state.ssz" || val.SourceKeyIndex != uint64(i) {
+			t.Fatalf("validator %d: expected source state.ssz/%d, got %s/%d", i, i, val.Source, val.SourceKeyIndex)
+		}
+	}
+
+	// the imported range is its own mapping entry
+	vals = append(vals, &Validator{PublicKey: phase0.BLSPubKey{0x10}, Source: "mnemonic-0", SourceKeyIndex: 0})
+
+	mapping := BuildMapping(vals)
+	if len(mapping) != 2 || mapping[0].Source != "state.ssz" || mapping[0].KeyIndexTo != 4 || mapping[1].StateIndexFrom != 5 {
+		t.Fatalf("expected a state and a mnemonic mapping entry, got %+v", mapping)
+	}
+}
+
+func TestImportStateValidators_Filter(t *testing.T) {
+	stateVals, balances := createTestStateValidators()
+	indexTo := uint64(3)
+
+	tests := []struct {
+		name    string
+		filter  *StateImportFilter
+		indices []uint64
+	}{
+		{name: "active only", filter: &StateImportFilter{ActiveOnly: true}, indices: []uint64{0, 4}},
+		{name: "index range", filter: &StateImportFilter{IndexFrom: 1, IndexTo: &indexTo}, indices: []uint64{1, 2, 3}},
+		{name: "index from", filter: &StateImportFilter{IndexFrom: 3}, indices: []uint64{3, 4}},
+		{name: "credential types", filter: &StateImportFilter{CredentialTypes: []byte{0x00, 0x02}}, indices: []uint64{1, 3, 4}},
+		{name: "combined", filter: &StateImportFilter{ActiveOnly: true, IndexTo: &indexTo, CredentialTypes: []byte{0x01}}, indices: []uint64{0}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vals, err := ImportStateValidators(stateVals, balances, 10, "state.ssz", test.filter)
+			if err != nil {
+				t.Fatalf("failed to import validators: %v", err)
+			}
+
+			if len(vals) != len(test.indices) {
+				t.Fatalf("expected %d validators, got %d", len(test.indices), len(vals))
+			}
+
+			for i, val := range vals {
+				if val.SourceKeyIndex != test.indices[i] {
+					t.Fatalf("expected validator %d at position %d, got %d", test.indices[i], i, val.SourceKeyIndex)
+				}
+			}
+		})
+	}
+
+	if _, err := ImportStateValidators(stateVals, balances[:2], 10, "state.ssz", nil); err == nil {
+		t.Fatalf("expected error for missing balances")
+	}
+}
+
+func TestParseCredentialType(t *testing.T) {
+	for value, expected := range map[string]byte{"0x01": 0x01, "02": 0x02, " 0x00 ": 0x00} {
+		credType, err := ParseCredentialType(value)
+		if err != nil || credType != expected {
+			t.Fatalf("expected credential type 0x%02x for %q, got 0x%02x (%v)", expected, value, credType, err)
+		}
+	}
+
+	for _, value := range []string{"", "0x100", "zz"} {
+		if _, err := ParseCredentialType(value); err == nil {
+			t.Fatalf("expected error for credential type %q", value)
+		}
+	}
+}
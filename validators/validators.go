@@ -1,7 +1,14 @@
 package validators
 
 import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/ethpandaops/go-eth2-client/spec/phase0"
+	"gopkg.in/yaml.v3"
 )
 
 type ValidatorStatus uint8
@@ -12,6 +19,71 @@ const (
 	ValidatorStatusExited
 )
 
+// validatorStatusNames maps the textual status names accepted in config files
+// to their ValidatorStatus value.
+var validatorStatusNames = map[string]ValidatorStatus{
+	"active":  ValidatorStatusActive,
+	"slashed": ValidatorStatusSlashed,
+	"exited":  ValidatorStatusExited,
+}
+
+// ParseValidatorStatus parses a validator status from either its numeric form
+// (0=active, 1=slashed, 2=exited) or its name. An empty string is treated as
+// active.
+func ParseValidatorStatus(value string) (ValidatorStatus, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return ValidatorStatusActive, nil
+	}
+
+	if status, ok := validatorStatusNames[value]; ok {
+		return status, nil
+	}
+
+	num, err := strconv.ParseUint(value, 10, 8)
+	if err != nil || num > uint64(ValidatorStatusExited) {
+		return 0, fmt.Errorf("invalid validator status %q", value)
+	}
+
+	return ValidatorStatus(num), nil
+}
+
+func (s ValidatorStatus) String() string {
+	for name, status := range validatorStatusNames {
+		if status == s {
+			return name
+		}
+	}
+
+	return strconv.FormatUint(uint64(s), 10)
+}
+
+func (s *ValidatorStatus) UnmarshalYAML(node *yaml.Node) error {
+	status, err := ParseValidatorStatus(node.Value)
+	if err != nil {
+		return err
+	}
+
+	*s = status
+
+	return nil
+}
+
+func (s *ValidatorStatus) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	status, err := ParseValidatorStatus(string(bytes.Trim(data, `"`)))
+	if err != nil {
+		return err
+	}
+
+	*s = status
+
+	return nil
+}
+
 type Validator struct {
 	PublicKey             phase0.BLSPubKey
 	WithdrawalCredentials []byte
@@ -21,4 +93,22 @@ type Validator struct {
 	// Source identifies where the key originated
 	Source         string
 	SourceKeyIndex uint64
+
+	// DepositSignature is the signature of the validator deposit, if known
+	// (from a deposit data file or signed with the mnemonic key)
+	DepositSignature *phase0.BLSSignature
+}
+
+// ParsePubkey parses a hex encoded (optionally 0x prefixed) BLS public key.
+func ParsePubkey(value string) (phase0.BLSPubKey, error) {
+	pubkey, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(value), "0x"))
+	if err != nil {
+		return phase0.BLSPubKey{}, err
+	}
+
+	if len(pubkey) != 48 {
+		return phase0.BLSPubKey{}, fmt.Errorf("invalid length %d", len(pubkey))
+	}
+
+	return phase0.BLSPubKey(pubkey), nil
 }